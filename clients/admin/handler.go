@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"testing_system/clients/common"
+	"testing_system/common/constants/role"
 	"testing_system/lib/logger"
 )
 
@@ -24,7 +25,11 @@ func SetupHandler(base *common.ClientBase) error {
 }
 
 func (h *Handler) setupRoutes() {
-	router := h.base.Router.Group("/admin", h.base.RequireAuthMiddleware(true))
+	router := h.base.Router.Group(
+		"/admin",
+		h.base.RequireAuthMiddleware(true),
+		h.base.RequireRoleMiddleware(role.Jury),
+	)
 	router.GET("", h.serveFrontend)
 
 	router.GET("/problems", h.serveFrontend)
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"sync"
+	"testing_system/common/constants/role"
+	"testing_system/common/db/models"
+	"testing_system/lib/logger"
+)
+
+type loginRequest struct {
+	Login    string `json:"login" form:"login" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+	Next     string `json:"-" form:"next"`
+}
+
+type loginResponse struct {
+	User      *models.User `json:"user"`
+	CSRFToken string       `json:"csrf_token,omitempty"`
+}
+
+// dummyUser has password hash that is checked for unknown logins
+var dummyUser = sync.OnceValue(func() *models.User {
+	user := new(models.User)
+	if err := user.SetPassword("dummy"); err != nil {
+		logger.Panic("Can not hash dummy password, error: %v", err)
+	}
+	return user
+})
+
+func (b *ClientBase) setupAuthRoutes() {
+	b.Router.GET("/login", b.serveLogin)
+	b.Router.POST("/login", b.handleLogin)
+	b.Router.POST("/logout", b.RequireAuthMiddleware(false), b.CSRFMiddleware, b.handleLogout)
+}
+
+func (b *ClientBase) serveLogin(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.gohtml", gin.H{
+		"Next": c.Query("next"),
+	})
+}
+
+// handleLogin accepts both login form and json request. Form is redirected to next page, json gets csrf token
+func (b *ClientBase) handleLogin(c *gin.Context) {
+	isJSON := c.ContentType() == gin.MIMEJSON
+	var request loginRequest
+	if err := c.ShouldBind(&request); err != nil {
+		b.loginFailed(c, isJSON, http.StatusBadRequest, "Login and password are required", request.Next)
+		return
+	}
+
+	user := new(models.User)
+	err := b.DB.WithContext(c).Where("login = ?", request.Login).First(user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Can not load user %s, error: %v", request.Login, err)
+		abortWithError(c, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if err != nil {
+		// Password is checked anyway, so response time does not reveal whether login exists
+		dummyUser().CheckPassword(request.Password)
+	}
+	if err != nil || !user.CheckPassword(request.Password) {
+		b.loginFailed(c, isJSON, http.StatusUnauthorized, "Invalid login or password", request.Next)
+		return
+	}
+
+	session, err := b.newSession(c, user)
+	if err != nil {
+		logger.Error("Can not create session for user %d, error: %v", user.ID, err)
+		abortWithError(c, http.StatusInternalServerError, "internal error")
+		return
+	}
+	logger.Trace("user %d logged in", user.ID)
+
+	if isJSON {
+		c.JSON(http.StatusOK, gin.H{
+			"ok":       true,
+			"response": loginResponse{User: user, CSRFToken: session.CSRFToken},
+		})
+		return
+	}
+	c.Redirect(http.StatusFound, b.loginRedirect(request.Next))
+}
+
+func (b *ClientBase) loginFailed(c *gin.Context, isJSON bool, code int, message string, next string) {
+	if isJSON {
+		abortWithError(c, code, message)
+		return
+	}
+	c.HTML(code, "login.gohtml", gin.H{
+		"Next":  next,
+		"Error": message,
+	})
+}
+
+// loginRedirect allows only local redirects after login
+func (b *ClientBase) loginRedirect(next string) string {
+	if strings.HasPrefix(next, "/") && !strings.HasPrefix(next, "//") && !strings.HasPrefix(next, "/\\") {
+		return next
+	}
+	if b.Config.Admin {
+		return "/admin"
+	}
+	return "/"
+}
+
+func (b *ClientBase) handleLogout(c *gin.Context) {
+	if err := b.removeSession(c); err != nil {
+		logger.Error("Can not remove session, error: %v", err)
+		abortWithError(c, http.StatusInternalServerError, "internal error")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// createInitialAdmin creates admin from config if there are no admins yet, so the first login is possible
+func (b *ClientBase) createInitialAdmin() error {
+	adminConfig := b.Config.Auth.InitialAdmin
+	if adminConfig == nil {
+		return nil
+	}
+	if adminConfig.Login == "" || adminConfig.Password == "" {
+		return logger.Error("Initial admin login and password should be specified")
+	}
+
+	var adminsCount int64
+	err := b.DB.Model(&models.User{}).Where("role = ?", role.Admin).Count(&adminsCount).Error
+	if err != nil {
+		return logger.Error("Can not count admins, error: %v", err)
+	}
+	if adminsCount > 0 {
+		return nil
+	}
+
+	admin := &models.User{
+		Login: adminConfig.Login,
+		Name:  adminConfig.Login,
+		Role:  role.Admin,
+	}
+	if err = admin.SetPassword(adminConfig.Password); err != nil {
+		return logger.Error("Can not set initial admin password, error: %v", err)
+	}
+	if err = b.DB.Create(admin).Error; err != nil {
+		return logger.Error("Can not create initial admin, error: %v", err)
+	}
+	logger.Info("Created initial admin %s", admin.Login)
+	return nil
+}
@@ -35,6 +35,9 @@ func NewClientBase(configPath string) *ClientBase {
 	}
 
 	logger.InitLogger(config.Logger)
+	if config.Auth.SessionLifetime == 0 {
+		config.Auth.SessionLifetime = clientconfig.DefaultSessionLifetime
+	}
 
 	base := &ClientBase{
 		Config:            config,
@@ -46,11 +49,15 @@ func NewClientBase(configPath string) *ClientBase {
 	if err != nil {
 		logger.Panic("Can not set up testing system db, error: %v", err)
 	}
+	if err = base.createInitialAdmin(); err != nil {
+		logger.Panic("Can not set up initial admin, error: %v", err)
+	}
 
 	base.Router = gin.Default()
 
 	base.Router.Static("/static", filepath.Join(base.Config.ResourcesPath, "static"))
 	base.Router.LoadHTMLGlob(filepath.Join(base.Config.ResourcesPath, "templates/*"))
+	base.setupAuthRoutes()
 	return base
 }
 
@@ -4,8 +4,11 @@ import (
 	"testing_system/clients/tsapi/tsapiconfig"
 	tsconfig "testing_system/common/config"
 	"testing_system/lib/logger"
+	"time"
 )
 
+const DefaultSessionLifetime = 7 * 24 * time.Hour
+
 type Config struct {
 	Address string `yaml:"Address"`
 
@@ -17,6 +20,22 @@ type Config struct {
 
 	ResourcesPath string `yaml:"ResourcesPath"`
 
+	Auth AuthConfig `yaml:"Auth"`
+
 	TestingSystemAPI *tsapiconfig.Config `yaml:"TestingSystemAPI"`
 	Admin            bool                `yaml:"Admin"`
 }
+
+type AuthConfig struct {
+	SessionLifetime time.Duration `yaml:"SessionLifetime"`
+	// SecureCookies should be enabled if client is served over https
+	SecureCookies bool `yaml:"SecureCookies"`
+
+	// InitialAdmin is created on startup if there are no admins in db
+	InitialAdmin *InitialAdminConfig `yaml:"InitialAdmin"`
+}
+
+type InitialAdminConfig struct {
+	Login    string `yaml:"Login"`
+	Password string `yaml:"Password"`
+}
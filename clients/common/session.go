@@ -1,13 +1,244 @@
 package common
 
-import "github.com/gin-gonic/gin"
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing_system/common/constants/role"
+	"testing_system/common/db/models"
+	"testing_system/lib/logger"
+	"time"
+)
 
+const (
+	sessionCookieName = "ts_session"
+	// CSRF cookie and header names are the ones used by axios by default
+	csrfCookieName = "XSRF-TOKEN"
+	csrfHeaderName = "X-XSRF-TOKEN"
+	csrfFormField  = "csrf_token"
+
+	userContextKey    = "auth_user"
+	sessionContextKey = "auth_session"
+)
+
+var errNotAuthenticated = errors.New("not authenticated")
+
+// CurrentUser returns user authenticated by RequireAuthMiddleware
+func CurrentUser(c *gin.Context) *models.User {
+	user, ok := c.Get(userContextKey)
+	if !ok {
+		return nil
+	}
+	return user.(*models.User)
+}
+
+func currentSession(c *gin.Context) *models.Session {
+	session, ok := c.Get(sessionContextKey)
+	if !ok {
+		return nil
+	}
+	return session.(*models.Session)
+}
+
+// CSRFToken returns csrf token of current session, requests authenticated with api token have no csrf token
+func CSRFToken(c *gin.Context) string {
+	session := currentSession(c)
+	if session == nil {
+		return ""
+	}
+	return session.CSRFToken
+}
+
+// RequireAuthMiddleware authenticates user either by session cookie or by api token in Authorization header.
+// Unauthenticated users are redirected to login page if redirect is set
 func (b *ClientBase) RequireAuthMiddleware(redirect bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: add check auth
+		err := b.authenticate(c)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, errNotAuthenticated) {
+			logger.Error("Can not authenticate request, error: %v", err)
+			abortWithError(c, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if redirect {
+			c.Redirect(http.StatusFound, "/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+			c.Abort()
+			return
+		}
+		abortWithError(c, http.StatusUnauthorized, "Not authenticated")
 	}
 }
 
+// RequireRoleMiddleware should be used after RequireAuthMiddleware
+func (b *ClientBase) RequireRoleMiddleware(required role.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil || !user.Role.Allows(required) {
+			abortWithError(c, http.StatusForbidden, "Access denied")
+		}
+	}
+}
+
+// CSRFMiddleware checks that state changing requests made with session contain session csrf token.
+// Requests authenticated by api token are not sent by browser, so they are not checked
 func (b *ClientBase) CSRFMiddleware(c *gin.Context) {
-	// TODO: Add check csrf
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+	session := currentSession(c)
+	if session == nil {
+		return
+	}
+	token := c.GetHeader(csrfHeaderName)
+	if token == "" {
+		token = c.PostForm(csrfFormField)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+		abortWithError(c, http.StatusForbidden, "Invalid csrf token")
+	}
+}
+
+func (b *ClientBase) authenticate(c *gin.Context) error {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return errNotAuthenticated
+		}
+		return b.authenticateAPIToken(c, token)
+	}
+
+	sessionToken, err := c.Cookie(sessionCookieName)
+	if err != nil || sessionToken == "" {
+		return errNotAuthenticated
+	}
+	session := new(models.Session)
+	err = b.DB.
+		WithContext(c).
+		Preload("User").
+		Where("token_hash = ? AND expires_at > ?", models.HashSecretToken(sessionToken), time.Now()).
+		First(session).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotAuthenticated
+		}
+		return err
+	}
+	if session.User == nil {
+		return errNotAuthenticated
+	}
+
+	if csrfCookie, _ := c.Cookie(csrfCookieName); csrfCookie != session.CSRFToken {
+		b.setCSRFCookie(c, session)
+	}
+	c.Set(userContextKey, session.User)
+	c.Set(sessionContextKey, session)
+	return nil
+}
+
+func (b *ClientBase) authenticateAPIToken(c *gin.Context, token string) error {
+	apiToken := new(models.APIToken)
+	err := b.DB.
+		WithContext(c).
+		Preload("User").
+		Where("token_hash = ?", models.HashSecretToken(token)).
+		First(apiToken).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotAuthenticated
+		}
+		return err
+	}
+	if apiToken.User == nil {
+		return errNotAuthenticated
+	}
+
+	err = b.DB.WithContext(c).Model(apiToken).UpdateColumn("last_used_at", time.Now()).Error
+	if err != nil {
+		logger.Warn("Can not update last usage of api token %d, error: %v", apiToken.ID, err)
+	}
+	c.Set(userContextKey, apiToken.User)
+	return nil
+}
+
+// newSession creates session for user and sets session cookies
+func (b *ClientBase) newSession(c *gin.Context, user *models.User) (*models.Session, error) {
+	sessionToken, err := models.NewSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	csrfToken, err := models.NewSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{
+		TokenHash: models.HashSecretToken(sessionToken),
+		CSRFToken: csrfToken,
+		ExpiresAt: time.Now().Add(b.Config.Auth.SessionLifetime),
+		UserID:    user.ID,
+		User:      user,
+	}
+
+	err = b.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err = tx.Where("expires_at <= ?", time.Now()).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		sessionCookieName,
+		sessionToken,
+		int(b.Config.Auth.SessionLifetime.Seconds()),
+		"/",
+		"",
+		b.Config.Auth.SecureCookies,
+		true,
+	)
+	b.setCSRFCookie(c, session)
+	return session, nil
+}
+
+// setCSRFCookie sets cookie that is readable by frontend, so it can send csrf token in header
+func (b *ClientBase) setCSRFCookie(c *gin.Context, session *models.Session) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		csrfCookieName,
+		session.CSRFToken,
+		int(time.Until(session.ExpiresAt).Seconds()),
+		"/",
+		"",
+		b.Config.Auth.SecureCookies,
+		false,
+	)
+}
+
+func (b *ClientBase) removeSession(c *gin.Context) error {
+	if session := currentSession(c); session != nil {
+		if err := b.DB.WithContext(c).Delete(session).Error; err != nil {
+			return err
+		}
+	}
+	c.SetCookie(sessionCookieName, "", -1, "/", "", b.Config.Auth.SecureCookies, true)
+	c.SetCookie(csrfCookieName, "", -1, "/", "", b.Config.Auth.SecureCookies, false)
+	return nil
+}
+
+func abortWithError(c *gin.Context, code int, message string) {
+	c.AbortWithStatusJSON(code, gin.H{
+		"ok":    false,
+		"error": message,
+	})
 }
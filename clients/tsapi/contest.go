@@ -0,0 +1,158 @@
+package tsapi
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"testing_system/common/db/models"
+	"time"
+)
+
+type contestInList struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type contestListFilter struct {
+	Count int `form:"count" binding:"required"`
+	Page  int `form:"page,default=1"`
+}
+
+// contestRequest is used to create and modify contests, problems are referenced by their ids
+type contestRequest struct {
+	Name       string    `json:"name" binding:"required"`
+	StartTime  time.Time `json:"start_time" binding:"required"`
+	EndTime    time.Time `json:"end_time" binding:"required"`
+	ProblemIDs []uint    `json:"problem_ids"`
+}
+
+func (h *Handler) getContests(c *gin.Context) {
+	filter := new(contestListFilter)
+	if err := c.ShouldBindQuery(filter); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if filter.Count <= 0 {
+		respError(c, http.StatusBadRequest, "Count should be positive number")
+		return
+	}
+	if filter.Page <= 0 {
+		respError(c, http.StatusBadRequest, "Page should be positive number")
+		return
+	}
+	var contests []contestInList
+	err := h.base.DB.
+		WithContext(c).
+		Model(&models.Contest{}).
+		Order("id desc").
+		Limit(filter.Count).
+		Offset((filter.Page - 1) * filter.Count).
+		Find(&contests).
+		Error
+	if err != nil {
+		respServerError(c, "Can not load contests, error: %v", err)
+		return
+	}
+	respSuccess(c, contests)
+}
+
+func (h *Handler) getContest(c *gin.Context) {
+	contest, ok := h.findContest(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	respSuccess(c, contest)
+}
+
+func (h *Handler) addContest(c *gin.Context) {
+	contest := new(models.Contest)
+	if !h.bindContest(c, contest) {
+		return
+	}
+
+	if err := h.base.DB.WithContext(c).Create(contest).Error; err != nil {
+		respServerError(c, "Can not create contest, error: %v", err)
+		return
+	}
+	respSuccess(c, contest)
+}
+
+func (h *Handler) modifyContest(c *gin.Context) {
+	contest, ok := h.findContest(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if !h.bindContest(c, contest) {
+		return
+	}
+
+	err := h.base.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(contest).Error; err != nil {
+			return err
+		}
+		return tx.Model(contest).Association("Problems").Replace(contest.Problems)
+	})
+	if err != nil {
+		respServerError(c, "Can not update contest %d, error: %v", contest.ID, err)
+		return
+	}
+	respSuccessEmpty(c)
+}
+
+// bindContest parses contest request and fills contest with it, problems are loaded from db
+func (h *Handler) bindContest(c *gin.Context, contest *models.Contest) bool {
+	var request contestRequest
+	if err := c.BindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return false
+	}
+	if !request.StartTime.Before(request.EndTime) {
+		respError(c, http.StatusBadRequest, "Contest start time should be before end time")
+		return false
+	}
+
+	contest.Name = request.Name
+	contest.StartTime = request.StartTime
+	contest.EndTime = request.EndTime
+	contest.Problems = make([]*models.Problem, 0, len(request.ProblemIDs))
+	for _, problemID := range request.ProblemIDs {
+		if contest.HasProblem(problemID) {
+			respError(c, http.StatusBadRequest, "Problem %d is used more than once", problemID)
+			return false
+		}
+		problem, ok := h.findProblemByID(c, problemID)
+		if !ok {
+			return false
+		}
+		contest.Problems = append(contest.Problems, problem)
+	}
+	return true
+}
+
+func (h *Handler) findContest(c *gin.Context, id string) (*models.Contest, bool) {
+	contestID, err := strconv.Atoi(id)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse contest id %s, error: %v", id, err)
+		return nil, false
+	}
+
+	contest := new(models.Contest)
+	err = h.base.DB.
+		WithContext(c).
+		Preload("Problems", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(contest, contestID).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respError(c, http.StatusNotFound, "Contest with id %d not found", contestID)
+		} else {
+			respServerError(c, "Can not load contest %d, error: %v", contestID, err)
+		}
+		return nil, false
+	}
+	return contest, true
+}
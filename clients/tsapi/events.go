@@ -0,0 +1,71 @@
+package tsapi
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"io"
+	"testing_system/clients/common"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/role"
+	"testing_system/lib/logger"
+	"time"
+)
+
+func (h *Handler) getSubmissionEvents(c *gin.Context) {
+	submission, ok := h.findSubmission(c)
+	if !ok {
+		return
+	}
+	h.streamSubmissionEvents(c, &submission.ID, nil)
+}
+
+// getAllSubmissionsEvents streams events of all submissions, participants receive only events of their submissions
+func (h *Handler) getAllSubmissionsEvents(c *gin.Context) {
+	var userID *uint
+	if user := common.CurrentUser(c); !user.Role.Allows(role.Jury) {
+		userID = &user.ID
+	}
+	h.streamSubmissionEvents(c, nil, userID)
+}
+
+func (h *Handler) streamSubmissionEvents(c *gin.Context, submissionID *uint, userID *uint) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	events := make(chan *masterconn.SubmissionEvent)
+	go func() {
+		defer close(events)
+		err := h.base.MasterConnection.SubscribeSubmissionEvents(ctx, submissionID, userID, func(event *masterconn.SubmissionEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Can not receive submission events from master, error: %v", err)
+		}
+	}()
+
+	keepAlive := time.NewTicker(masterconn.SubmissionEventsKeepAlive)
+	defer keepAlive.Stop()
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing_system/clients/tsapi/masterstatus"
 	"testing_system/clients/tsapi/tsapiconfig"
 	"testing_system/common/constants/resource"
+	"testing_system/common/constants/role"
 	"testing_system/lib/logger"
 )
 
@@ -40,27 +41,68 @@ func SetupHandler(clientBase *common.ClientBase) error {
 func (h *Handler) setupRoutes() {
 	apiRouter := h.base.Router.Group("/api", h.base.RequireAuthMiddleware(false))
 	apiCSRFRouter := apiRouter.Group("", h.base.CSRFMiddleware)
+	juryRouter := apiRouter.Group("", h.base.RequireRoleMiddleware(role.Jury))
+	juryCSRFRouter := juryRouter.Group("", h.base.CSRFMiddleware)
+	adminCSRFRouter := apiRouter.Group("", h.base.RequireRoleMiddleware(role.Admin), h.base.CSRFMiddleware)
+
+	apiRouter.GET("/get/me", h.getMe)
+	apiRouter.GET("/get/tokens", h.getAPITokens)
+	apiCSRFRouter.PUT("/new/token", h.addAPIToken)
+	apiCSRFRouter.POST("/delete/token/:id", h.deleteAPIToken)
 
 	apiRouter.GET("/get/problems", h.getProblems)
 	apiRouter.GET("/get/problem/:id", h.getProblem)
-	apiRouter.GET("/get/problem/:id/test/:test/input", h.problemTestResourceGetter(resource.TestInput))
-	apiRouter.GET("/get/problem/:id/test/:test/answer", h.problemTestResourceGetter(resource.TestAnswer))
+	apiRouter.GET("/get/problem/:id/statement/:name", h.getProblemStatement)
+	juryRouter.GET("/get/problem/:id/test/:test/input", h.problemTestResourceGetter(resource.TestInput))
+	juryRouter.GET("/get/problem/:id/test/:test/answer", h.problemTestResourceGetter(resource.TestAnswer))
+	juryRouter.GET("/get/problem/:id/package", h.getProblemPackage)
+
+	juryCSRFRouter.PUT("/new/problem", h.addProblem)
+	juryCSRFRouter.PUT("/new/problem/package", h.addProblemPackage)
+	juryCSRFRouter.POST("/modify/problem/:id", h.modifyProblem)
+	juryCSRFRouter.POST("/upload/problem/:id/test/:test/input", h.problemResourceUploader(resource.TestInput, true))
+	juryCSRFRouter.POST("/upload/problem/:id/test/:test/answer", h.problemResourceUploader(resource.TestAnswer, true))
+	juryCSRFRouter.POST("/upload/problem/:id/checker", h.problemResourceUploader(resource.Checker, false))
+	juryCSRFRouter.POST("/upload/problem/:id/interactor", h.problemResourceUploader(resource.Interactor, false))
+	juryCSRFRouter.POST("/upload/problem/:id/solution", h.uploadProblemSolution)
+	juryCSRFRouter.POST("/upload/problem/:id/generator", h.problemResourceUploader(resource.Generator, false))
+	juryCSRFRouter.POST("/upload/problem/:id/validator", h.problemResourceUploader(resource.Validator, false))
+	juryCSRFRouter.POST("/upload/problem/:id/grader", h.uploadProblemGrader)
+	juryCSRFRouter.POST("/commit/problem/:id", h.commitProblemRevision)
+	juryCSRFRouter.POST("/modify/problem/:id/test_script", h.modifyProblemTestScript)
+	juryCSRFRouter.POST("/generate/problem/:id/tests", h.generateProblemTests)
+	juryCSRFRouter.PUT("/new/problem/:id/invocation", h.addInvocation)
+	juryRouter.GET("/get/invocation/:id", h.getInvocation)
+	juryRouter.GET("/get/invocation/:id/time_limit", h.getTimeLimitSuggestion)
+	juryCSRFRouter.POST("/modify/problem/:id/time_limit", h.applyTimeLimitSuggestion)
+
+	juryRouter.GET("/get/users", h.getUsers)
+	adminCSRFRouter.PUT("/new/user", h.addUser)
+	adminCSRFRouter.POST("/modify/user/:id", h.modifyUser)
 
-	apiCSRFRouter.PUT("/new/problem", h.addProblem)
-	apiCSRFRouter.POST("/modify/problem/:id", h.modifyProblem)
+	apiRouter.GET("/get/contests", h.getContests)
+	apiRouter.GET("/get/contest/:id", h.getContest)
+	juryCSRFRouter.PUT("/new/contest", h.addContest)
+	juryCSRFRouter.POST("/modify/contest/:id", h.modifyContest)
 
+	// Participants can access only their own submissions
 	apiRouter.GET("/get/submissions", h.getSubmissions)
 	apiRouter.GET("/get/submission/:id", h.getSubmission)
+	apiRouter.GET("/get/submission/:id/history", h.getSubmissionHistory)
+	apiRouter.GET("/get/submission/:id/events", h.getSubmissionEvents)
+	apiRouter.GET("/get/submissions/events", h.getAllSubmissionsEvents)
 	apiRouter.GET("/get/submission/:id/source", h.submissionResourceGetter(resource.SourceCode, false))
 	apiRouter.GET("/get/submission/:id/compile_output", h.submissionResourceGetter(resource.CompileOutput, true))
 
-	apiRouter.GET("/get/submission/:id/test/:test/output", h.submissionTestResourceGetter(resource.TestOutput))
-	apiRouter.GET("/get/submission/:id/test/:test/stderr", h.submissionTestResourceGetter(resource.TestStderr))
-	apiRouter.GET("/get/submission/:id/test/:test/check", h.submissionTestResourceGetter(resource.CheckerOutput))
+	juryRouter.GET("/get/submission/:id/test/:test/output", h.submissionTestResourceGetter(resource.TestOutput))
+	juryRouter.GET("/get/submission/:id/test/:test/stderr", h.submissionTestResourceGetter(resource.TestStderr))
+	juryRouter.GET("/get/submission/:id/test/:test/check", h.submissionTestResourceGetter(resource.CheckerOutput))
 
 	apiCSRFRouter.PUT("/new/submission", h.addSubmission)
+	juryCSRFRouter.POST("/rejudge", h.rejudge)
+	juryCSRFRouter.POST("/cancel/submission/:id", h.cancelSubmission)
 
-	apiRouter.GET("/get/master_status", h.getMasterStatus)
+	juryRouter.GET("/get/master_status", h.getMasterStatus)
 
-	apiCSRFRouter.POST("/reset/invoker_cache", h.resetInvokerCache)
+	adminCSRFRouter.POST("/reset/invoker_cache", h.resetInvokerCache)
 }
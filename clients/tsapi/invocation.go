@@ -0,0 +1,136 @@
+package tsapi
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/db/models"
+	"testing_system/lib/connector"
+)
+
+type newInvocationRequest struct {
+	// Solutions are names of tested solutions, by default all solutions except do-not-run ones are tested
+	Solutions []string `json:"solutions"`
+}
+
+func (h *Handler) addInvocation(c *gin.Context) {
+	problem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var request newInvocationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	invocationID, err := h.base.MasterConnection.NewInvocation(c, &masterconn.InvocationRequest{
+		ProblemID: problem.ID,
+		Solutions: request.Solutions,
+	})
+	if err != nil {
+		var connectorErr *connector.Error
+		if errors.As(err, &connectorErr) && connectorErr.Code < http.StatusInternalServerError {
+			respError(c, connectorErr.Code, "Can not start invocation of problem %d, error: %v", problem.ID, err)
+			return
+		}
+		respServerError(c, "Can not start invocation of problem %d, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, invocationID)
+}
+
+func (h *Handler) getInvocation(c *gin.Context) {
+	invocationID, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse invocation id %s, error: %v", c.Param("id"), err)
+		return
+	}
+
+	result, err := h.base.MasterConnection.GetInvocation(c, uint(invocationID))
+	if err != nil {
+		var connectorErr *connector.Error
+		if errors.As(err, &connectorErr) && connectorErr.Code == http.StatusNotFound {
+			respError(c, http.StatusNotFound, "Invocation %d does not exist", invocationID)
+			return
+		}
+		respServerError(c, "Can not load invocation %d, error: %v", invocationID, err)
+		return
+	}
+	respSuccess(c, result)
+}
+
+func (h *Handler) getTimeLimitSuggestion(c *gin.Context) {
+	invocationID, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse invocation id %s, error: %v", c.Param("id"), err)
+		return
+	}
+	suggestion, ok := h.suggestTimeLimit(c, uint(invocationID))
+	if !ok {
+		return
+	}
+	respSuccess(c, suggestion)
+}
+
+type applyTimeLimitRequest struct {
+	InvocationID uint `json:"invocation_id" binding:"required"`
+	// Force applies time limit even if some time limit solutions do not exceed it
+	Force bool `json:"force"`
+}
+
+// applyTimeLimitSuggestion saves time limit suggested by invocation in new problem revision
+func (h *Handler) applyTimeLimitSuggestion(c *gin.Context) {
+	oldProblem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var request applyTimeLimitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	suggestion, ok := h.suggestTimeLimit(c, request.InvocationID)
+	if !ok {
+		return
+	}
+	if suggestion.ProblemID != oldProblem.ID {
+		respError(c, http.StatusBadRequest, "Invocation %d is not of problem %d", request.InvocationID, oldProblem.ID)
+		return
+	}
+	if len(suggestion.PassingSolutions) > 0 && !request.Force {
+		respError(
+			c,
+			http.StatusBadRequest,
+			"Solutions %v do not exceed suggested time limit %v",
+			suggestion.PassingSolutions,
+			suggestion.TimeLimit,
+		)
+		return
+	}
+
+	problem := *oldProblem
+	problem.TimeLimit = suggestion.TimeLimit
+	if err := models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem); err != nil {
+		respServerError(c, "Can not update problem %d time limit, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, suggestion)
+}
+
+func (h *Handler) suggestTimeLimit(c *gin.Context, invocationID uint) (*masterconn.TimeLimitSuggestion, bool) {
+	suggestion, err := h.base.MasterConnection.SuggestTimeLimit(c, invocationID)
+	if err != nil {
+		var connectorErr *connector.Error
+		if errors.As(err, &connectorErr) && connectorErr.Code < http.StatusInternalServerError {
+			respError(c, connectorErr.Code, "Can not suggest time limit by invocation %d, error: %v", invocationID, err)
+			return nil, false
+		}
+		respServerError(c, "Can not suggest time limit by invocation %d, error: %v", invocationID, err)
+		return nil, false
+	}
+	return suggestion, true
+}
@@ -107,6 +107,8 @@ type SubmissionInList struct {
 	Language    string          `json:"language"`
 	Score       float64         `json:"score"`
 	Verdict     verdict.Verdict `json:"verdict"`
+	UserID      *uint           `json:"user_id,omitempty"`
+	ContestID   *uint           `json:"contest_id,omitempty"`
 	CurrentTest int             `json:"current_test,omitempty" gorm:"-"`
 }
 
@@ -117,6 +119,8 @@ type SubmissionsFilter struct {
 	ProblemID *uint            `form:"problem_id,omitempty"`
 	Verdict   *verdict.Verdict `form:"verdict,omitempty"`
 	Language  *string          `form:"language,omitempty"`
+	UserID    *uint            `form:"user_id,omitempty"`
+	ContestID *uint            `form:"contest_id,omitempty"`
 }
 
 func (s *MasterStatus) GetSubmissions(ctx context.Context, filter *SubmissionsFilter) ([]SubmissionInList, error) {
@@ -133,6 +137,12 @@ func (s *MasterStatus) GetSubmissions(ctx context.Context, filter *SubmissionsFi
 	if filter.Language != nil {
 		request = request.Where("language=?", *filter.Language)
 	}
+	if filter.UserID != nil {
+		request = request.Where("user_id=?", *filter.UserID)
+	}
+	if filter.ContestID != nil {
+		request = request.Where("contest_id=?", *filter.ContestID)
+	}
 	var submissions []SubmissionInList
 
 	err := request.
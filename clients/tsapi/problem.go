@@ -5,10 +5,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xorcare/pointer"
 	"gorm.io/gorm"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"testing_system/common/connectors/storageconn"
 	"testing_system/common/constants/resource"
+	"testing_system/common/constants/standardchecker"
 	"testing_system/common/db/models"
 )
 
@@ -67,7 +69,17 @@ func (h *Handler) addProblem(c *gin.Context) {
 		return
 	}
 
-	h.base.DB.WithContext(c).Create(&problem)
+	problem.Revision = 1
+	err := h.base.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&problem).Error; err != nil {
+			return err
+		}
+		return tx.Create(models.NewProblemRevision(&problem)).Error
+	})
+	if err != nil {
+		respServerError(c, "Can not create problem, error: %v", err)
+		return
+	}
 	respSuccess(c, problem)
 }
 
@@ -85,7 +97,7 @@ func (h *Handler) modifyProblem(c *gin.Context) {
 	if !checkProblemIsOK(c, problem) {
 		return
 	}
-	err := h.base.DB.WithContext(c).Save(&problem).Error
+	err := models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem)
 	if err != nil {
 		respError(
 			c, http.StatusInternalServerError, "Can not update problem %d, error: %v", problem.ID, err,
@@ -95,6 +107,90 @@ func (h *Handler) modifyProblem(c *gin.Context) {
 	respSuccessEmpty(c)
 }
 
+// commitProblemRevision makes files uploaded to draft revision visible to new submissions as new problem revision
+func (h *Handler) commitProblemRevision(c *gin.Context) {
+	oldProblem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	problem := *oldProblem
+	if err := models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem); err != nil {
+		respServerError(c, "Can not commit problem %d revision, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, problem.Revision)
+}
+
+// problemResourceUploader saves problem resource to draft revision, which is the next revision of problem.
+// Draft files are not seen by submissions until the revision is committed by commitProblemRevision
+// or by any other problem change. Uploaded validator is enabled for problem, so its upload commits the draft
+func (h *Handler) problemResourceUploader(resourceType resource.Type, isTestResource bool) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		oldProblem, ok := h.findProblem(c, c.Param("id"))
+		if !ok {
+			return
+		}
+		var testID uint64
+		if isTestResource {
+			if testID, ok = h.getProblemTestID(c, oldProblem); !ok {
+				return
+			}
+		}
+		file, err := c.FormFile("file")
+		if err != nil {
+			respError(c, http.StatusBadRequest, "Can not parse file, error: %v", err)
+			return
+		}
+		if !h.uploadProblemDraftFile(c, oldProblem, resourceType, testID, file) {
+			return
+		}
+		if resourceType != resource.Validator {
+			respSuccess(c, oldProblem.Revision+1)
+			return
+		}
+
+		problem := *oldProblem
+		problem.HasValidator = true
+		if err = models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem); err != nil {
+			respServerError(c, "Can not save problem %d revision, error: %v", problem.ID, err)
+			return
+		}
+		respSuccess(c, problem.Revision)
+	}
+}
+
+// uploadProblemDraftFile uploads file to draft revision of problem, it is not run inside db transaction,
+// so large files do not keep transaction open
+func (h *Handler) uploadProblemDraftFile(
+	c *gin.Context,
+	problem *models.Problem,
+	resourceType resource.Type,
+	testID uint64,
+	file *multipart.FileHeader,
+) bool {
+	reader, err := file.Open()
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not read file, error: %v", err)
+		return false
+	}
+	defer reader.Close()
+
+	err = h.base.StorageConnection.Upload(&storageconn.Request{
+		Resource:        resourceType,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision + 1,
+		TestID:          testID,
+		File:            reader,
+		StorageFilename: file.Filename,
+		Ctx:             c,
+	}).Error
+	if err != nil {
+		respServerError(c, "Can not upload problem %d %v, error: %v", problem.ID, resourceType, err)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) problemTestResourceGetter(resourceType resource.Type) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		problem, ok := h.findProblem(c, c.Param("id"))
@@ -107,12 +203,13 @@ func (h *Handler) problemTestResourceGetter(resourceType resource.Type) func(c *
 		}
 
 		resp := h.base.StorageConnection.Download(&storageconn.Request{
-			Resource:      resourceType,
-			ProblemID:     uint64(problem.ID),
-			TestID:        testID,
-			DownloadBytes: true,
-			DownloadHead:  pointer.Int64(h.config.LoadFilesHead),
-			Ctx:           c,
+			Resource:        resourceType,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: problem.Revision,
+			TestID:          testID,
+			DownloadBytes:   true,
+			DownloadHead:    pointer.Int64(h.config.LoadFilesHead),
+			Ctx:             c,
 		})
 		if resp.Error != nil {
 			if errors.Is(resp.Error, storageconn.ErrStorageFileNotFound) {
@@ -179,10 +276,22 @@ func (h *Handler) findProblem(c *gin.Context, id string) (*models.Problem, bool)
 }
 
 func checkProblemIsOK(c *gin.Context, problem models.Problem) bool {
+	if !checkProblemCheckerIsOK(c, problem) || !checkProblemSolutionsAreOK(c, problem) ||
+		!checkProblemGradersAreOK(c, problem) || !checkProblemTestScriptIsOK(c, problem) {
+		return false
+	}
+	if problem.ProblemType == models.ProblemTypeOutputOnly && problem.Interactive {
+		respError(c, http.StatusBadRequest, "Output only problem can not be interactive")
+		return false
+	}
+	if problem.ProblemType == models.ProblemTypeTwoPhase && problem.Interactive {
+		respError(c, http.StatusBadRequest, "Two phase problem can not be interactive")
+		return false
+	}
 	switch problem.ProblemType {
 	case models.ProblemTypeICPC:
 		return true
-	case models.ProblemTypeIOI:
+	case models.ProblemTypeIOI, models.ProblemTypeOutputOnly, models.ProblemTypeTwoPhase:
 		lastTest := uint64(0)
 		usedGroupNames := make(map[string]struct{})
 		for _, group := range problem.TestGroups {
@@ -257,3 +366,37 @@ func checkProblemIsOK(c *gin.Context, problem models.Problem) bool {
 		return false
 	}
 }
+
+func checkProblemCheckerIsOK(c *gin.Context, problem models.Problem) bool {
+	switch problem.CheckerType {
+	case 0,
+		models.CheckerTypeTestlib,
+		models.CheckerTypeExitCode,
+		models.CheckerTypeEjudge,
+		models.CheckerTypeKattis,
+		models.CheckerTypeCMS:
+		return true
+	case models.CheckerTypeStandard:
+		if !standardchecker.IsValid(problem.StandardChecker) {
+			respError(c, http.StatusBadRequest,
+				"Unknown standard checker %s, supported checkers are %v", problem.StandardChecker, standardchecker.All,
+			)
+			return false
+		}
+		if problem.CheckerEpsilon == nil {
+			return true
+		}
+		if problem.StandardChecker != standardchecker.Floats {
+			respError(c, http.StatusBadRequest, "Checker epsilon can be set only for %s checker", standardchecker.Floats)
+			return false
+		}
+		if !(*problem.CheckerEpsilon > 0) {
+			respError(c, http.StatusBadRequest, "Checker epsilon should be positive")
+			return false
+		}
+		return true
+	default:
+		respError(c, http.StatusBadRequest, "Invalid checker type")
+		return false
+	}
+}
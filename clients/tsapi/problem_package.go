@@ -0,0 +1,226 @@
+package tsapi
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"strings"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+)
+
+func (h *Handler) getProblemPackage(c *gin.Context) {
+	problem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	pkg, err := problempackage.Export(c, h.base.StorageConnection, problem)
+	if err != nil {
+		respServerError(c, "Can not export problem %d, error: %v", problem.ID, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("problem-%d.zip", problem.ID),
+	}))
+	c.Status(http.StatusOK)
+	if err = pkg.Write(c.Writer); err != nil {
+		// Headers are already sent, so we can only log the error
+		respServerError(c, "Can not write problem %d package, error: %v", problem.ID, err)
+	}
+}
+
+func (h *Handler) addProblemPackage(c *gin.Context) {
+	file, err := c.FormFile("package")
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse package, error: %v", err)
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not read package, error: %v", err)
+		return
+	}
+	defer reader.Close()
+
+	pkg, err := problempackage.Read(reader, file.Size)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Invalid problem package, error: %v", err)
+		return
+	}
+	if !checkProblemIsOK(c, *pkg.Problem) {
+		return
+	}
+
+	problem, err := problempackage.Import(c, h.base.DB, h.base.StorageConnection, pkg)
+	if err != nil {
+		respServerError(c, "Can not import problem package, error: %v", err)
+		return
+	}
+	respSuccess(c, problem)
+}
+
+// uploadProblemSolution adds or replaces reference solution in new problem revision
+func (h *Handler) uploadProblemSolution(c *gin.Context) {
+	oldProblem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse file, error: %v", err)
+		return
+	}
+	language, ok := c.GetPostForm("language")
+	if !ok {
+		respError(c, http.StatusBadRequest, "No language specified")
+		return
+	}
+	tag := solutiontag.Tag(c.PostForm("tag"))
+	if !tag.IsValid() {
+		respError(c, http.StatusBadRequest, "Unknown solution tag %s, supported tags are %v", tag, solutiontag.All)
+		return
+	}
+
+	problem := *oldProblem
+	problem.Solutions = make(models.ProblemSolutions, 0, len(oldProblem.Solutions)+1)
+	for _, solution := range oldProblem.Solutions {
+		if solution.Name != file.Filename {
+			problem.Solutions = append(problem.Solutions, solution)
+		}
+	}
+	problem.Solutions = append(problem.Solutions, &models.ProblemSolution{
+		Name:     file.Filename,
+		Language: language,
+		Tag:      tag,
+	})
+	if !checkProblemSolutionsAreOK(c, problem) {
+		return
+	}
+
+	if !h.uploadProblemDraftFile(c, oldProblem, resource.Solution, 0, file) {
+		return
+	}
+	if err = models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem); err != nil {
+		respServerError(c, "Can not save problem %d revision, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, problem.Revision)
+}
+
+// uploadProblemGrader uploads grader that is compiled together with submissions in its language
+func (h *Handler) uploadProblemGrader(c *gin.Context) {
+	oldProblem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse file, error: %v", err)
+		return
+	}
+	language, ok := c.GetPostForm("language")
+	if !ok {
+		respError(c, http.StatusBadRequest, "No language specified")
+		return
+	}
+
+	problem := *oldProblem
+	problem.Graders = make(models.ProblemGraders, 0, len(oldProblem.Graders)+1)
+	for _, grader := range oldProblem.Graders {
+		if grader.Name != file.Filename {
+			problem.Graders = append(problem.Graders, grader)
+		}
+	}
+	problem.Graders = append(problem.Graders, &models.ProblemGrader{
+		Name:     file.Filename,
+		Language: language,
+	})
+	if !checkProblemGradersAreOK(c, problem) {
+		return
+	}
+
+	if !h.uploadProblemDraftFile(c, oldProblem, resource.Grader, 0, file) {
+		return
+	}
+	if err = models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem); err != nil {
+		respServerError(c, "Can not save problem %d revision, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, problem.Revision)
+}
+
+func (h *Handler) getProblemStatement(c *gin.Context) {
+	problem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	statement := problem.FindStatement(c.Param("name"))
+	if statement == nil {
+		respError(c, http.StatusNotFound, "Problem %d has no statement %s", problem.ID, c.Param("name"))
+		return
+	}
+
+	resp := h.base.StorageConnection.Download(&storageconn.Request{
+		Resource:        resource.Statement,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		StorageFilename: statement.Name,
+		DownloadBytes:   true,
+		Ctx:             c,
+	})
+	if resp.Error != nil {
+		respServerError(c, "Can not load problem %d statement %s, error: %v", problem.ID, statement.Name, resp.Error)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{
+		"filename": statement.Name,
+	}))
+	c.Data(http.StatusOK, statement.Type, resp.RawData)
+}
+
+func checkProblemSolutionsAreOK(c *gin.Context, problem models.Problem) bool {
+	usedNames := make(map[string]struct{})
+	for _, solution := range problem.Solutions {
+		if solution.Name == "" {
+			respError(c, http.StatusBadRequest, "Solution name is empty")
+			return false
+		}
+		if _, ok := usedNames[solution.Name]; ok {
+			respError(c, http.StatusBadRequest, "Solution %s is used more than once", solution.Name)
+			return false
+		}
+		if !solution.Tag.IsValid() {
+			respError(c, http.StatusBadRequest, "Solution %s has unknown tag %s", solution.Name, solution.Tag)
+			return false
+		}
+		usedNames[solution.Name] = struct{}{}
+	}
+	return true
+}
+
+func checkProblemGradersAreOK(c *gin.Context, problem models.Problem) bool {
+	usedNames := make(map[string]struct{})
+	for _, grader := range problem.Graders {
+		// Grader is put into compilation sandbox with its name
+		if grader.Name == "" || strings.ContainsAny(grader.Name, "/\\") {
+			respError(c, http.StatusBadRequest, "Grader has invalid name %s", grader.Name)
+			return false
+		}
+		if _, ok := usedNames[grader.Name]; ok {
+			respError(c, http.StatusBadRequest, "Grader %s is used more than once", grader.Name)
+			return false
+		}
+		if grader.Language == "" {
+			respError(c, http.StatusBadRequest, "Grader %s has no language", grader.Name)
+			return false
+		}
+		usedNames[grader.Name] = struct{}{}
+	}
+	return true
+}
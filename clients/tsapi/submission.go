@@ -6,13 +6,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xorcare/pointer"
 	"gorm.io/gorm"
-	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"testing_system/clients/common"
 	"testing_system/clients/tsapi/masterstatus"
+	"testing_system/common/connectors/masterconn"
 	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
 	"testing_system/common/constants/resource"
+	"testing_system/common/constants/role"
+	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
+	"testing_system/lib/connector"
 )
 
 func (h *Handler) getSubmissions(c *gin.Context) {
@@ -20,6 +26,10 @@ func (h *Handler) getSubmissions(c *gin.Context) {
 	err := c.ShouldBindQuery(&filter)
 	if err != nil {
 		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if user := common.CurrentUser(c); !user.Role.Allows(role.Jury) {
+		filter.UserID = &user.ID
 	}
 
 	submissions, err := h.masterStatus.GetSubmissions(c, &filter)
@@ -130,34 +140,71 @@ func (h *Handler) addSubmission(c *gin.Context) {
 		return
 	}
 
-	var reader io.Reader
-	var filename string
-	file, err := c.FormFile("solution")
-
-	if err == nil {
+	// Several solution files are packed by master and unpacked before compilation
+	form, err := c.MultipartForm()
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse solution file")
+		return
+	}
+	var files []*masterconn.SourceFile
+	for _, file := range form.File["solution"] {
 		fd, err := file.Open()
 		if err != nil {
 			respError(c, http.StatusBadRequest, "Can not parse solution")
 			return
 		}
 		defer fd.Close()
-		reader = fd
-		filename = file.Filename
-	} else {
-		if !errors.Is(err, http.ErrMissingFile) {
-			respError(c, http.StatusBadRequest, "Can not parse solution file")
-			return
-		}
+		files = append(files, &masterconn.SourceFile{Name: file.Filename, Reader: fd})
+	}
+	if len(files) == 0 {
 		solutionBytes, ok := c.GetPostForm("solution_text")
 		if !ok {
 			respError(c, http.StatusBadRequest, "No solution file or text provided")
 			return
 		}
-		reader = strings.NewReader(solutionBytes)
-		filename = fmt.Sprintf("solution.%s", language)
+		files = append(files, &masterconn.SourceFile{
+			Name:   fmt.Sprintf("solution.%s", language),
+			Reader: strings.NewReader(solutionBytes),
+		})
 	}
 
-	submissionID, err := h.base.MasterConnection.SendNewSubmission(c, problem.ID, language, filename, reader)
+	submissionPriority := priority.Priority(c.DefaultPostForm("priority", string(priority.Default)))
+	if !submissionPriority.IsValid() {
+		respError(c, http.StatusBadRequest, "Unknown priority %s", submissionPriority)
+		return
+	}
+
+	newSubmission := &masterconn.NewSubmission{
+		ProblemID: problem.ID,
+		Language:  language,
+		Priority:  submissionPriority,
+	}
+	if newSubmission.UserID, ok = parseOptionalFormID(c, "user_id"); !ok {
+		return
+	}
+	if newSubmission.ContestID, ok = parseOptionalFormID(c, "contest_id"); !ok {
+		return
+	}
+	if forceRun := c.PostForm("force_run"); forceRun != "" {
+		newSubmission.ForceRun, err = strconv.ParseBool(forceRun)
+		if err != nil {
+			respError(c, http.StatusBadRequest, "Can not parse force_run %s, error: %v", forceRun, err)
+			return
+		}
+	}
+
+	// Participants always submit on their own behalf and can not choose priority or force testing
+	if user := common.CurrentUser(c); !user.Role.Allows(role.Jury) {
+		newSubmission.UserID = &user.ID
+		newSubmission.ForceRun = false
+		if newSubmission.ContestID != nil {
+			newSubmission.Priority = priority.Contest
+		} else {
+			newSubmission.Priority = priority.Practice
+		}
+	}
+
+	submissionID, err := h.base.MasterConnection.SendNewSubmissionFiles(c, newSubmission, files)
 	if err != nil {
 		respServerError(c, "Can not send new submission, error: %v", err)
 		return
@@ -186,5 +233,83 @@ func (h *Handler) findSubmission(c *gin.Context) (*models.Submission, bool) {
 		}
 		return nil, false
 	}
+
+	user := common.CurrentUser(c)
+	if !user.Role.Allows(role.Jury) && (submission.UserID == nil || *submission.UserID != user.ID) {
+		respError(c, http.StatusNotFound, "Submission with id %d not found", submitID.ID)
+		return nil, false
+	}
 	return submission, true
 }
+
+func (h *Handler) rejudge(c *gin.Context) {
+	var request masterconn.RejudgeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if !request.HasFilter() {
+		respError(c, http.StatusBadRequest, "No rejudge filter specified")
+		return
+	}
+
+	response, err := h.base.MasterConnection.Rejudge(c, &request)
+	if err != nil {
+		respServerError(c, "Can not rejudge submissions, error: %v", err)
+		return
+	}
+	respSuccess(c, response)
+}
+
+func (h *Handler) cancelSubmission(c *gin.Context) {
+	submission, ok := h.findSubmission(c)
+	if !ok {
+		return
+	}
+	if submission.Verdict != verdict.RU {
+		respError(c, http.StatusBadRequest, "Submission %d is not testing", submission.ID)
+		return
+	}
+
+	err := h.base.MasterConnection.CancelSubmission(c, submission.ID)
+	if err != nil {
+		var connectorErr *connector.Error
+		if errors.As(err, &connectorErr) && connectorErr.Code == http.StatusBadRequest {
+			respError(c, http.StatusBadRequest, "Submission %d is not testing", submission.ID)
+			return
+		}
+		respServerError(c, "Can not cancel submission %d, error: %v", submission.ID, err)
+		return
+	}
+	respSuccessEmpty(c)
+}
+
+func (h *Handler) getSubmissionHistory(c *gin.Context) {
+	submission, ok := h.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	history := make([]*models.SubmissionHistory, 0)
+	err := h.base.DB.
+		WithContext(c).
+		Where("submission_id = ?", submission.ID).
+		Order("id desc").
+		Find(&history).
+		Error
+	if err != nil {
+		respServerError(c, "Can not load submission %d history, error: %v", submission.ID, err)
+		return
+	}
+	respSuccess(c, history)
+}
+
+func parseOptionalFormID(c *gin.Context, field string) (*uint, bool) {
+	value := c.PostForm(field)
+	id, err := connector.ParseOptionalID(value)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse %s %s, error: %v", field, value, err)
+		return nil, false
+	}
+	return id, true
+}
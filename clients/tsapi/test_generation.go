@@ -0,0 +1,99 @@
+package tsapi
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/db/models"
+	"testing_system/lib/connector"
+)
+
+type testScriptRequest struct {
+	// Script is polygon style test script, each line is "<generator> [args...] > <test>"
+	Script string `json:"script"`
+}
+
+// modifyProblemTestScript replaces test script in new problem revision, empty script removes it
+func (h *Handler) modifyProblemTestScript(c *gin.Context) {
+	oldProblem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var request testScriptRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	script, err := models.ParseTestScript(request.Script)
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse test script, error: %v", err)
+		return
+	}
+
+	problem := *oldProblem
+	problem.TestScript = script
+	if !checkProblemTestScriptIsOK(c, problem) {
+		return
+	}
+	err = models.SaveNewProblemRevision(h.base.DB.WithContext(c), oldProblem, &problem)
+	if err != nil {
+		respServerError(c, "Can not update problem %d test script, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, problem.Revision)
+}
+
+// generateProblemTests generates tests on invokers, master creates new problem revision for them,
+// so submissions that are tested against previous revisions keep their tests
+func (h *Handler) generateProblemTests(c *gin.Context) {
+	problem, ok := h.findProblem(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if problem.Interactive {
+		respError(c, http.StatusBadRequest, "Tests of interactive problem can not be generated")
+		return
+	}
+	if problem.MainSolution() == nil {
+		respError(c, http.StatusBadRequest, "Problem %d has no main solution", problem.ID)
+		return
+	}
+
+	submissionID, err := h.base.MasterConnection.GenerateTests(c, &masterconn.TestGenerationRequest{
+		ProblemID: problem.ID,
+	})
+	if err != nil {
+		var connectorErr *connector.Error
+		if errors.As(err, &connectorErr) && connectorErr.Code < http.StatusInternalServerError {
+			respError(c, connectorErr.Code, "Can not generate tests of problem %d, error: %v", problem.ID, err)
+			return
+		}
+		respServerError(c, "Can not generate tests of problem %d, error: %v", problem.ID, err)
+		return
+	}
+	respSuccess(c, submissionID)
+}
+
+func checkProblemTestScriptIsOK(c *gin.Context, problem models.Problem) bool {
+	usedTests := make(map[uint64]struct{})
+	for _, line := range problem.TestScript {
+		if line.Test == 0 || line.Test > problem.TestsNumber {
+			respError(c, http.StatusBadRequest,
+				"Test script generates test %d, tests are numbered from 1 to %d", line.Test, problem.TestsNumber,
+			)
+			return false
+		}
+		if _, ok := usedTests[line.Test]; ok {
+			respError(c, http.StatusBadRequest, "Test %d is generated more than once", line.Test)
+			return false
+		}
+		if line.Generator == "" || strings.ContainsAny(line.Generator, "/\\") {
+			respError(c, http.StatusBadRequest, "Test %d has invalid generator name %s", line.Test, line.Generator)
+			return false
+		}
+		usedTests[line.Test] = struct{}{}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package tsapi
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"testing_system/clients/common"
+	"testing_system/common/db/models"
+)
+
+type newAPITokenResponse struct {
+	*models.APIToken
+	// Token is shown only once, db stores only its hash
+	Token string `json:"token"`
+}
+
+func (h *Handler) getAPITokens(c *gin.Context) {
+	tokens := make([]*models.APIToken, 0)
+	err := h.base.DB.
+		WithContext(c).
+		Where("user_id = ?", common.CurrentUser(c).ID).
+		Order("id desc").
+		Find(&tokens).
+		Error
+	if err != nil {
+		respServerError(c, "Can not load api tokens, error: %v", err)
+		return
+	}
+	respSuccess(c, tokens)
+}
+
+func (h *Handler) addAPIToken(c *gin.Context) {
+	apiToken := new(models.APIToken)
+	if err := c.BindJSON(apiToken); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	token, err := models.NewSecretToken()
+	if err != nil {
+		respServerError(c, "%v", err)
+		return
+	}
+	*apiToken = models.APIToken{
+		Name:      apiToken.Name,
+		TokenHash: models.HashSecretToken(token),
+		UserID:    common.CurrentUser(c).ID,
+	}
+
+	if err = h.base.DB.WithContext(c).Create(apiToken).Error; err != nil {
+		respServerError(c, "Can not create api token, error: %v", err)
+		return
+	}
+	respSuccess(c, newAPITokenResponse{APIToken: apiToken, Token: token})
+}
+
+func (h *Handler) deleteAPIToken(c *gin.Context) {
+	tokenID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse token id %s, error: %v", c.Param("id"), err)
+		return
+	}
+	result := h.base.DB.
+		WithContext(c).
+		Where("id = ? AND user_id = ?", tokenID, common.CurrentUser(c).ID).
+		Delete(&models.APIToken{})
+	if result.Error != nil {
+		respServerError(c, "Can not delete api token %d, error: %v", tokenID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respError(c, http.StatusNotFound, "Token with id %d not found", tokenID)
+		return
+	}
+	respSuccessEmpty(c)
+}
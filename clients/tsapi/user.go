@@ -0,0 +1,151 @@
+package tsapi
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"testing_system/clients/common"
+	"testing_system/common/constants/role"
+	"testing_system/common/db/models"
+)
+
+type userListFilter struct {
+	Count int `form:"count" binding:"required"`
+	Page  int `form:"page,default=1"`
+}
+
+func (h *Handler) getUsers(c *gin.Context) {
+	filter := new(userListFilter)
+	if err := c.ShouldBindQuery(filter); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if filter.Count <= 0 {
+		respError(c, http.StatusBadRequest, "Count should be positive number")
+		return
+	}
+	if filter.Page <= 0 {
+		respError(c, http.StatusBadRequest, "Page should be positive number")
+		return
+	}
+	var users []models.User
+	err := h.base.DB.
+		WithContext(c).
+		Order("id desc").
+		Limit(filter.Count).
+		Offset((filter.Page - 1) * filter.Count).
+		Find(&users).
+		Error
+	if err != nil {
+		respServerError(c, "Can not load users, error: %v", err)
+		return
+	}
+	respSuccess(c, users)
+}
+
+type userRequest struct {
+	Login    string    `json:"login" binding:"required"`
+	Name     string    `json:"name"`
+	Role     role.Role `json:"role"`
+	Password string    `json:"password"`
+}
+
+type meResponse struct {
+	User      *models.User `json:"user"`
+	CSRFToken string       `json:"csrf_token,omitempty"`
+}
+
+func (h *Handler) getMe(c *gin.Context) {
+	respSuccess(c, meResponse{
+		User:      common.CurrentUser(c),
+		CSRFToken: common.CSRFToken(c),
+	})
+}
+
+func (h *Handler) addUser(c *gin.Context) {
+	var request userRequest
+	if err := c.BindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if request.Password == "" {
+		respError(c, http.StatusBadRequest, "Password is required")
+		return
+	}
+	user := new(models.User)
+	if !fillUser(c, user, &request) {
+		return
+	}
+
+	if err := h.base.DB.WithContext(c).Create(user).Error; err != nil {
+		respServerError(c, "Can not create user %s, error: %v", user.Login, err)
+		return
+	}
+	respSuccess(c, user)
+}
+
+// modifyUser updates user, password is changed only if it is specified
+func (h *Handler) modifyUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respError(c, http.StatusBadRequest, "Can not parse user id %s, error: %v", c.Param("id"), err)
+		return
+	}
+	user := new(models.User)
+	err = h.base.DB.WithContext(c).First(user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respError(c, http.StatusNotFound, "User with id %d not found", userID)
+		} else {
+			respServerError(c, "Can not load user %d, error: %v", userID, err)
+		}
+		return
+	}
+
+	var request userRequest
+	if err = c.BindJSON(&request); err != nil {
+		respError(c, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if !fillUser(c, user, &request) {
+		return
+	}
+
+	err = h.base.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if request.Password == "" {
+			return nil
+		}
+		// Changed password logs user out everywhere
+		return tx.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error
+	})
+	if err != nil {
+		respServerError(c, "Can not update user %d, error: %v", user.ID, err)
+		return
+	}
+	respSuccessEmpty(c)
+}
+
+func fillUser(c *gin.Context, user *models.User, request *userRequest) bool {
+	if request.Role == "" {
+		request.Role = role.Default
+	}
+	if !request.Role.IsValid() {
+		respError(c, http.StatusBadRequest, "Unknown role %s, supported roles are %v", request.Role, role.All)
+		return false
+	}
+	user.Login = request.Login
+	user.Name = request.Name
+	user.Role = request.Role
+	if request.Password != "" {
+		if err := user.SetPassword(request.Password); err != nil {
+			respServerError(c, "Can not set user password, error: %v", err)
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"testing_system/lib/connector"
 	"testing_system/lib/logger"
 
 	"github.com/xorcare/pointer"
@@ -14,6 +15,10 @@ type Config struct {
 
 	Logger *logger.Config `yaml:"Logger,omitempty"`
 
+	// Auth configures server TLS and default secrets of master, invoker and storage handlers.
+	// Each component can accept its own secrets with Auth of its config
+	Auth *connector.ServerAuthConfig `yaml:"Auth,omitempty"`
+
 	Invoker *InvokerConfig `yaml:"Invoker,omitempty"`
 	Master  *MasterConfig  `yaml:"Master,omitempty"`
 	Storage *StorageConfig `yaml:"Storage,omitempty"`
@@ -1,8 +1,11 @@
 package config
 
+import "testing_system/lib/connector"
+
 type Connection struct {
 	Address string `yaml:"Address"`
-	// TODO: Add authentification
+	// Auth configures request signing and client certificates, it should match Auth of the server
+	Auth *connector.AuthConfig `yaml:"Auth,omitempty"`
 }
 
 func fillInConnections(config *Config) {
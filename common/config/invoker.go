@@ -1,6 +1,9 @@
 package config
 
-import "time"
+import (
+	"testing_system/lib/connector"
+	"time"
+)
 
 type InvokerConfig struct {
 	// PublicAddress defines address for public access to invoker from master if the server is set up locally with some proxy
@@ -24,6 +27,12 @@ type InvokerConfig struct {
 	CompilerConfigsFolder string `yaml:"CompilerConfigsFolder"`
 
 	CheckerLimits *RunLimitsConfig `yaml:"CheckerLimits,omitempty"`
+	// GeneratorLimits are used for test generators and validators, by default they are the same as checker ones
+	GeneratorLimits *RunLimitsConfig `yaml:"GeneratorLimits,omitempty"`
+
+	// Auth lists secrets accepted by invoker handlers, secrets of testing system Auth are used if it is not set.
+	// Master signs requests to invokers with Master.InvokerAuth
+	Auth *connector.HandlerAuthConfig `yaml:"Auth,omitempty"`
 }
 
 func FillInInvokerConfig(config *InvokerConfig) {
@@ -66,4 +75,9 @@ func FillInInvokerConfig(config *InvokerConfig) {
 		config.CheckerLimits = &RunLimitsConfig{}
 	}
 	fillInDefaultCheckerRunLimitsConfig(config.CheckerLimits)
+
+	if config.GeneratorLimits == nil {
+		config.GeneratorLimits = &RunLimitsConfig{}
+	}
+	fillInDefaultCheckerRunLimitsConfig(config.GeneratorLimits)
 }
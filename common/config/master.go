@@ -1,13 +1,57 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"testing_system/common/constants/priority"
+	"testing_system/lib/connector"
+	"time"
+)
 
 type MasterConfig struct {
 	InvokersPingInterval time.Duration `yaml:"InvokersPingInterval"`
 	SendJobInterval      time.Duration `yaml:"FetchJobInterval"`
 	LostJobTimeout       time.Duration `yaml:"LostJobTimeout"`
+
+	// QueueWeights sets share of jobs for each submission priority.
+	// Not specified priorities use priority.DefaultWeights, unknown priorities are rejected
+	QueueWeights map[priority.Priority]int `yaml:"QueueWeights,omitempty"`
+
+	// FairShareBy sets how jobs of the same priority are shared.
+	// By default, it is FairShareBySubmission
+	FairShareBy string `yaml:"FairShareBy,omitempty"`
+
+	// Auth lists secrets accepted by master handlers, secrets of testing system Auth are used if it is not set
+	Auth *connector.HandlerAuthConfig `yaml:"Auth,omitempty"`
+
+	// InvokerAuth is used for connections from master to all invokers
+	InvokerAuth *connector.AuthConfig `yaml:"InvokerAuth,omitempty"`
+
+	// Webhooks are notified when final result of submission is saved
+	Webhooks []*WebhookConfig `yaml:"Webhooks,omitempty"`
+
+	// TimeLimitFactor is multiplier of max accepted solution time in suggested problem time limit, 2 by default
+	TimeLimitFactor float64 `yaml:"TimeLimitFactor,omitempty"`
 }
 
+type WebhookConfig struct {
+	URL string `yaml:"URL"`
+	// Secret is used to sign webhook payload with HMAC-SHA256, payload is not signed if it is empty
+	Secret string `yaml:"Secret,omitempty"`
+	// Timeout of single delivery attempt, 10s by default
+	Timeout time.Duration `yaml:"Timeout,omitempty"`
+	// MaxElapsedTime limits time of delivery retries, 1h by default
+	MaxElapsedTime time.Duration `yaml:"MaxElapsedTime,omitempty"`
+}
+
+const (
+	// FairShareBySubmission shares jobs equally between submissions
+	FairShareBySubmission = "submission"
+	// FairShareByUser shares jobs equally between users, submissions without user are treated as separate users
+	FairShareByUser = "user"
+	// FairShareByContest shares jobs equally between contests, submissions without contest are treated as separate contests
+	FairShareByContest = "contest"
+)
+
 func fillInMasterConfig(config *MasterConfig) {
 	if config.InvokersPingInterval == 0 {
 		config.InvokersPingInterval = time.Second
@@ -18,4 +62,45 @@ func fillInMasterConfig(config *MasterConfig) {
 	if config.LostJobTimeout == 0 {
 		config.LostJobTimeout = 5 * time.Second
 	}
+	switch config.FairShareBy {
+	case FairShareBySubmission, FairShareByUser, FairShareByContest:
+	case "":
+		config.FairShareBy = FairShareBySubmission
+	default:
+		panic(fmt.Sprintf("Unknown master FairShareBy value %s", config.FairShareBy))
+	}
+	for _, webhook := range config.Webhooks {
+		fillInWebhookConfig(webhook)
+	}
+	if config.TimeLimitFactor == 0 {
+		config.TimeLimitFactor = 2
+	}
+	if config.TimeLimitFactor < 1 {
+		panic("Master TimeLimitFactor should not be less than 1")
+	}
+	if config.QueueWeights == nil {
+		config.QueueWeights = make(map[priority.Priority]int)
+	}
+	for p := range config.QueueWeights {
+		if !p.IsValid() {
+			panic(fmt.Sprintf("Unknown priority %s in master QueueWeights", p))
+		}
+	}
+	for p, weight := range priority.DefaultWeights {
+		if config.QueueWeights[p] <= 0 {
+			config.QueueWeights[p] = weight
+		}
+	}
+}
+
+func fillInWebhookConfig(config *WebhookConfig) {
+	if config.URL == "" {
+		panic("Master webhook URL is not specified")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxElapsedTime == 0 {
+		config.MaxElapsedTime = time.Hour
+	}
 }
@@ -1,9 +1,14 @@
 package config
 
+import "testing_system/lib/connector"
+
 type StorageConfig struct {
 	StoragePath string `yaml:"StoragePath"`
 
 	BlockSize uint `yaml:"BlockSize"`
+
+	// Auth lists secrets accepted by storage handlers, secrets of testing system Auth are used if it is not set
+	Auth *connector.HandlerAuthConfig `yaml:"Auth,omitempty"`
 }
 
 func fillInStorageConfig(config *StorageConfig) {
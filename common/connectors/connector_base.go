@@ -3,6 +3,8 @@ package connectors
 import (
 	"github.com/go-resty/resty/v2"
 	"testing_system/common/config"
+	"testing_system/lib/connector"
+	"testing_system/lib/logger"
 )
 
 type ConnectorBase struct {
@@ -16,7 +18,9 @@ func NewConnectorBase(connection *config.Connection) *ConnectorBase {
 		client:     resty.New(),
 	}
 	c.client.SetBaseURL(connection.Address)
-	// TODO: Add auth
+	if err := connector.SetupClientAuth(c.client, connection.Auth); err != nil {
+		logger.Panic("Can not set up auth of connection to %s, error: %v", connection.Address, err)
+	}
 	// TODO: Add retry configuration
 	return c
 }
@@ -10,11 +10,12 @@ func _() {
 	var x [1]struct{}
 	_ = x[CompileJob-1]
 	_ = x[TestJob-2]
+	_ = x[GenerateTestJob-3]
 }
 
-const _JobType_name = "CompileJobTestJob"
+const _JobType_name = "CompileJobTestJobGenerateTestJob"
 
-var _JobType_index = [...]uint8{0, 10, 17}
+var _JobType_index = [...]uint8{0, 10, 17, 32}
 
 func (i JobType) String() string {
 	i -= 1
@@ -12,6 +12,8 @@ type JobType int
 const (
 	CompileJob JobType = iota + 1
 	TestJob
+	// GenerateTestJob generates test input, validates it and writes answer with compiled main solution
+	GenerateTestJob
 )
 
 type Job struct {
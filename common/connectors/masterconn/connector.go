@@ -1,10 +1,14 @@
 package masterconn
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing_system/common/config"
 	"testing_system/common/connectors"
 	"testing_system/common/connectors/invokerconn"
@@ -13,6 +17,9 @@ import (
 	"github.com/go-resty/resty/v2"
 )
 
+// maxSubmissionEventSize limits size of single event in submission events stream
+const maxSubmissionEventSize = 16 * 1024 * 1024
+
 type Connector struct {
 	connection *connectors.ConnectorBase
 }
@@ -36,18 +43,39 @@ func (c *Connector) SendInvokerStatus(response *invokerconn.Status) error {
 }
 func (c *Connector) SendNewSubmission(
 	ctx context.Context,
-	problemID uint,
-	language string,
+	submission *NewSubmission,
 	fileName string,
 	fileReader io.Reader,
+) (SubmissionID uint, err error) {
+	return c.SendNewSubmissionFiles(ctx, submission, []*SourceFile{{Name: fileName, Reader: fileReader}})
+}
+
+// SendNewSubmissionFiles sends submission that consists of several source files
+func (c *Connector) SendNewSubmissionFiles(
+	ctx context.Context,
+	submission *NewSubmission,
+	files []*SourceFile,
 ) (SubmissionID uint, err error) {
 	r := c.connection.R()
 	r.SetContext(ctx)
-	r.SetFormData(map[string]string{
-		"ProblemID": strconv.FormatUint(uint64(problemID), 10),
-		"Language":  language,
-	})
-	r.SetFileReader("Solution", fileName, fileReader)
+	formData := map[string]string{
+		"ProblemID": strconv.FormatUint(uint64(submission.ProblemID), 10),
+		"Language":  submission.Language,
+		"Priority":  string(submission.Priority),
+	}
+	if submission.UserID != nil {
+		formData["UserID"] = strconv.FormatUint(uint64(*submission.UserID), 10)
+	}
+	if submission.ContestID != nil {
+		formData["ContestID"] = strconv.FormatUint(uint64(*submission.ContestID), 10)
+	}
+	if submission.ForceRun {
+		formData["ForceRun"] = "true"
+	}
+	r.SetFormData(formData)
+	for _, file := range files {
+		r.SetFileReader("Solution", file.Name, file.Reader)
+	}
 	var submissionResponse SubmissionResponse
 	r.SetResult(&submissionResponse)
 	resp, err := r.Post("/master/submit")
@@ -88,3 +116,157 @@ func (c *Connector) ResetInvokerCache(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (c *Connector) Rejudge(ctx context.Context, request *RejudgeRequest) (*RejudgeResponse, error) {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetBody(request)
+	var rejudgeResponse RejudgeResponse
+	r.SetResult(&rejudgeResponse)
+	resp, err := r.Post("/master/rejudge")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, connector.ParseRespError(resp.Body(), resp)
+	}
+	return &rejudgeResponse, nil
+}
+
+func (c *Connector) CancelSubmission(ctx context.Context, submissionID uint) error {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetBody(&CancelRequest{SubmissionID: submissionID})
+	resp, err := r.Post("/master/cancel")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return connector.ParseRespError(resp.Body(), resp)
+	}
+	return nil
+}
+
+// SubscribeSubmissionEvents reads master stream of submission events and calls handler for each event.
+// If submissionID is nil, events of all submissions are received. If userID is set, only events of
+// submissions of this user are received.
+// It returns when stream is finished, context is cancelled or handler returns error
+func (c *Connector) SubscribeSubmissionEvents(
+	ctx context.Context,
+	submissionID *uint,
+	userID *uint,
+	handler func(event *SubmissionEvent) error,
+) error {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetDoNotParseResponse(true)
+	r.SetHeader("Accept", "text/event-stream")
+	if submissionID != nil {
+		r.SetQueryParam("SubmissionID", strconv.FormatUint(uint64(*submissionID), 10))
+	}
+	if userID != nil {
+		r.SetQueryParam("UserID", strconv.FormatUint(uint64(*userID), 10))
+	}
+	resp, err := r.Get("/master/events")
+	if err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer body.Close()
+	if resp.StatusCode() != http.StatusOK {
+		data, _ := io.ReadAll(body)
+		return connector.ParseRespError(data, resp)
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSubmissionEventSize)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if data.Len() == 0 {
+			continue
+		}
+		event := new(SubmissionEvent)
+		if err = json.Unmarshal([]byte(data.String()), event); err != nil {
+			return fmt.Errorf("can not parse submission event, error: %v", err)
+		}
+		data.Reset()
+		if err = handler(event); err != nil {
+			return err
+		}
+	}
+	if err = scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Connector) NewInvocation(ctx context.Context, request *InvocationRequest) (uint, error) {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetBody(request)
+	var invocationResponse InvocationResponse
+	r.SetResult(&invocationResponse)
+	resp, err := r.Post("/master/invocation")
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return 0, connector.ParseRespError(resp.Body(), resp)
+	}
+	return invocationResponse.InvocationID, nil
+}
+
+func (c *Connector) GetInvocation(ctx context.Context, invocationID uint) (*InvocationResult, error) {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetQueryParam("InvocationID", strconv.FormatUint(uint64(invocationID), 10))
+	var result InvocationResult
+	r.SetResult(&result)
+	resp, err := r.Get("/master/invocation")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, connector.ParseRespError(resp.Body(), resp)
+	}
+	return &result, nil
+}
+
+func (c *Connector) SuggestTimeLimit(ctx context.Context, invocationID uint) (*TimeLimitSuggestion, error) {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetQueryParam("InvocationID", strconv.FormatUint(uint64(invocationID), 10))
+	var suggestion TimeLimitSuggestion
+	r.SetResult(&suggestion)
+	resp, err := r.Get("/master/invocation/time_limit")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, connector.ParseRespError(resp.Body(), resp)
+	}
+	return &suggestion, nil
+}
+
+func (c *Connector) GenerateTests(ctx context.Context, request *TestGenerationRequest) (uint, error) {
+	r := c.connection.R()
+	r.SetContext(ctx)
+	r.SetBody(request)
+	var generationResponse TestGenerationResponse
+	r.SetResult(&generationResponse)
+	resp, err := r.Post("/master/generate_tests")
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return 0, connector.ParseRespError(resp.Body(), resp)
+	}
+	return generationResponse.SubmissionID, nil
+}
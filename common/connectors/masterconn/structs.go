@@ -1,7 +1,12 @@
 package masterconn
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"testing_system/common/connectors/invokerconn"
+	"testing_system/common/constants/priority"
 	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/lib/customfields"
@@ -32,10 +37,125 @@ type JobResultStatistics struct {
 	// TODO: Add more statistics
 }
 
+// NewSubmission contains parameters of a new submission, only ProblemID and Language are required
+type NewSubmission struct {
+	ProblemID uint
+	Language  string
+	Priority  priority.Priority
+	UserID    *uint
+	ContestID *uint
+	// ForceRun disables copying results of finished submission with same source, problem and language
+	ForceRun bool
+}
+
+// SourceFile is one of submission source files. Several files are packed by master to zip archive
+type SourceFile struct {
+	Name   string
+	Reader io.Reader
+}
+
+type CancelRequest struct {
+	SubmissionID uint `json:"submission_id" binding:"required"`
+}
+
 type SubmissionResponse struct {
 	SubmissionID uint `json:"submission_id"`
 }
 
+// RejudgeRequest selects submissions to rejudge. At least one filter should be specified, all specified filters should match
+type RejudgeRequest struct {
+	SubmissionID *uint `json:"submission_id,omitempty"`
+	ProblemID    *uint `json:"problem_id,omitempty"`
+
+	Language *string `json:"language,omitempty"`
+	// Verdicts filters submissions by their current verdict
+	Verdicts []verdict.Verdict `json:"verdicts,omitempty"`
+	// FromSubmissionID and ToSubmissionID filter submissions by ID range, both bounds are inclusive
+	FromSubmissionID *uint `json:"from_submission_id,omitempty"`
+	ToSubmissionID   *uint `json:"to_submission_id,omitempty"`
+
+	// ResetInvokerCache should be set if problem tests or checker were changed
+	ResetInvokerCache bool `json:"reset_invoker_cache,omitempty"`
+	// Priority of rejudged submissions in queue, by default it is priority.Rejudge. Stored priority is not changed
+	Priority *priority.Priority `json:"priority,omitempty"`
+}
+
+func (r *RejudgeRequest) HasFilter() bool {
+	return r.SubmissionID != nil ||
+		r.ProblemID != nil ||
+		r.Language != nil ||
+		len(r.Verdicts) > 0 ||
+		r.FromSubmissionID != nil ||
+		r.ToSubmissionID != nil
+}
+
+type RejudgeResponse struct {
+	SubmissionIDs []uint `json:"submission_ids"`
+	// SkippedSubmissionIDs are submissions that are being tested now
+	SkippedSubmissionIDs []uint `json:"skipped_submission_ids"`
+	// FailedSubmissionIDs are submissions that could not be rejudged, their results are kept
+	FailedSubmissionIDs []uint `json:"failed_submission_ids"`
+}
+
+// InvocationRequest starts testing of problem reference solutions on all tests of current problem revision
+type InvocationRequest struct {
+	ProblemID uint `json:"problem_id" binding:"required"`
+	// Solutions are names of tested solutions, by default all solutions except do-not-run ones are tested
+	Solutions []string `json:"solutions,omitempty"`
+}
+
+type InvocationResponse struct {
+	InvocationID uint `json:"invocation_id"`
+}
+
+// InvocationResult is a matrix of solutions by tests, test results of each solution are in its submission
+type InvocationResult struct {
+	ID              uint   `json:"id"`
+	ProblemID       uint   `json:"problem_id"`
+	ProblemRevision uint64 `json:"problem_revision"`
+	// Finished is set when all solutions are tested
+	Finished bool `json:"finished"`
+	// Mismatched are names of tested solutions whose results do not match their tags
+	Mismatched []string                    `json:"mismatched"`
+	Solutions  []*InvocationSolutionResult `json:"solutions"`
+}
+
+type InvocationSolutionResult struct {
+	models.InvocationSolution
+
+	Verdict           verdict.Verdict    `json:"verdict"`
+	CompilationResult *models.TestResult `json:"compilation_result,omitempty"`
+	TestResults       models.TestResults `json:"test_results"`
+	// MatchesTag is nil while solution is tested
+	MatchesTag *bool               `json:"matches_tag,omitempty"`
+	MaxTime    customfields.Time   `json:"max_time"`
+	MaxMemory  customfields.Memory `json:"max_memory"`
+}
+
+// TimeLimitSuggestion is problem time limit calculated from invocation results: max time of accepted
+// solutions multiplied by master TimeLimitFactor. Time limit solutions should still exceed it
+type TimeLimitSuggestion struct {
+	InvocationID    uint              `json:"invocation_id"`
+	ProblemID       uint              `json:"problem_id"`
+	ProblemRevision uint64            `json:"problem_revision"`
+	TimeLimit       customfields.Time `json:"time_limit"`
+	MaxAcceptedTime customfields.Time `json:"max_accepted_time"`
+	Factor          float64           `json:"factor"`
+	// PassingSolutions are time limit tagged solutions that do not exceed suggested time limit on any test
+	PassingSolutions []string `json:"passing_solutions"`
+}
+
+// TestGenerationRequest starts generation of tests of current problem revision by its test script.
+// Answers of all tests are written by main solution
+type TestGenerationRequest struct {
+	ProblemID uint `json:"problem_id" binding:"required"`
+}
+
+// TestGenerationResponse contains main solution submission, generation is finished when its testing is finished
+type TestGenerationResponse struct {
+	SubmissionID uint `json:"submission_id"`
+}
+
 type Status struct {
 	Epoch              string               `json:"epoch"`
 	TestingSubmissions []uint               `json:"testing_submissions"`
@@ -44,6 +164,44 @@ type Status struct {
 	Invokers []*InvokerStatus `json:"invokers"`
 }
 
+type SubmissionEventType string
+
+const (
+	// SubmissionEventTesting is sent when submission is added to queue and as initial state for new subscribers
+	SubmissionEventTesting SubmissionEventType = "testing"
+	// SubmissionEventUpdated is sent when test results or verdict of submission change
+	SubmissionEventUpdated SubmissionEventType = "updated"
+	// SubmissionEventFinished is sent when final result of submission is saved to db
+	SubmissionEventFinished SubmissionEventType = "finished"
+)
+
+// SubmissionEvent is streamed by master to subscribers of submission updates
+type SubmissionEvent struct {
+	Type       SubmissionEventType `json:"type"`
+	Submission *models.Submission  `json:"submission"`
+}
+
+// SubmissionEventsKeepAlive is interval of keep-alive comments in submission event streams
+const SubmissionEventsKeepAlive = 15 * time.Second
+
+const (
+	WebhookEventSubmissionFinished = "submission.finished"
+
+	WebhookTimestampHeader = "X-TS-Webhook-Timestamp"
+	// WebhookSignatureHeader contains hex HMAC-SHA256 of "<timestamp>.<body>" with webhook secret
+	WebhookSignatureHeader = "X-TS-Webhook-Signature"
+)
+
+// SubmissionWebhook is posted by master to configured webhooks when final result of submission is saved
+type SubmissionWebhook struct {
+	Event        string              `json:"event"`
+	SubmissionID uint                `json:"submission_id"`
+	Verdict      verdict.Verdict     `json:"verdict"`
+	Score        float64             `json:"score"`
+	GroupResults models.GroupResults `json:"group_results,omitempty"`
+	Submission   *models.Submission  `json:"submission"`
+}
+
 type InvokerStatus struct {
 	Address     string             `json:"address"`
 	TimeAdded   time.Time          `json:"time_added"`
@@ -60,3 +218,12 @@ type InvokerJobMetrics struct {
 	ExecutionDuration      time.Duration `json:"execution_duration"`
 	SendResultDuration     time.Duration `json:"send_result_duration"`
 }
+
+// SignWebhook returns signature of webhook body, receivers should compare it with WebhookSignatureHeader
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -21,6 +21,10 @@ type Request struct {
 
 	// If resource is part of problem, ProblemID is used
 	ProblemID uint64 `json:"problem_id"`
+	// If resource is part of problem, ProblemRevision selects problem revision.
+	// Resources that are not uploaded in revision are taken from previous revisions,
+	// revision 0 contains resources uploaded before problem revisions were introduced
+	ProblemRevision uint64 `json:"problem_revision,omitempty"`
 	// If resource is part of submit, SubmitID is used
 	SubmitID uint64 `json:"submit_id"`
 	// If resource is a test, TestID should be specified
@@ -0,0 +1,30 @@
+package priority
+
+// Priority is a queue class of submission. Master queue shares invokers between classes according to their weights
+type Priority string
+
+const (
+	Contest  Priority = "contest"  // Live contest submissions
+	Practice Priority = "practice" // Practice and upsolving submissions
+	Author   Priority = "author"   // Author and reference solutions runs
+	Rejudge  Priority = "rejudge"  // Rejudged submissions
+
+	// Default is used for submissions without priority
+	Default = Contest
+)
+
+// All lists priorities from the highest to the lowest one
+var All = []Priority{Contest, Practice, Author, Rejudge}
+
+// DefaultWeights are used if weights are not specified in master config
+var DefaultWeights = map[Priority]int{
+	Contest:  16,
+	Practice: 4,
+	Author:   2,
+	Rejudge:  1,
+}
+
+func (p Priority) IsValid() bool {
+	_, ok := DefaultWeights[p]
+	return ok
+}
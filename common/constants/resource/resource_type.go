@@ -16,6 +16,11 @@ const (
 	Checker
 	CheckerOutput
 	Interactor
+	Solution
+	Statement
+	Generator
+	Validator
+	Grader
 	// Will be increased
 	// Don't forget to add a new type to storage/filesystem/resource_info.go
 )
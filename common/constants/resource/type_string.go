@@ -18,11 +18,16 @@ func _() {
 	_ = x[Checker-7]
 	_ = x[CheckerOutput-8]
 	_ = x[Interactor-9]
+	_ = x[Solution-10]
+	_ = x[Statement-11]
+	_ = x[Generator-12]
+	_ = x[Validator-13]
+	_ = x[Grader-14]
 }
 
-const _Type_name = "SourceCodeCompiledBinaryCompileOutputTestInputTestAnswerTestOutputTestStderrCheckerCheckerOutputInteractor"
+const _Type_name = "SourceCodeCompiledBinaryCompileOutputTestInputTestAnswerTestOutputTestStderrCheckerCheckerOutputInteractorSolutionStatementGeneratorValidatorGrader"
 
-var _Type_index = [...]uint8{0, 10, 24, 37, 46, 56, 66, 76, 83, 96, 106}
+var _Type_index = [...]uint8{0, 10, 24, 37, 46, 56, 66, 76, 83, 96, 106, 114, 123, 132, 141, 147}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,32 @@
+package role
+
+// Role defines what user is allowed to do in clients
+type Role string
+
+const (
+	Admin       Role = "admin"       // Full access, including users and testing system management
+	Jury        Role = "jury"        // Problems, contests and all submissions management
+	Participant Role = "participant" // Own submissions only
+
+	// Default is used for users without role
+	Default = Participant
+)
+
+// All lists roles from the most privileged to the least privileged one
+var All = []Role{Admin, Jury, Participant}
+
+var levels = map[Role]int{
+	Admin:       3,
+	Jury:        2,
+	Participant: 1,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := levels[r]
+	return ok
+}
+
+// Allows reports whether user with role r may do actions that require role required
+func (r Role) Allows(required Role) bool {
+	return levels[r] >= levels[required]
+}
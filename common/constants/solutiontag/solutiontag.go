@@ -0,0 +1,107 @@
+package solutiontag
+
+import (
+	"slices"
+	"testing_system/common/constants/verdict"
+)
+
+// Tag is expected result of problem reference solution, values are the same as in polygon packages
+type Tag string
+
+const (
+	Main                   Tag = "main"     // Main correct solution
+	Accepted               Tag = "accepted" // Correct solution
+	WrongAnswer            Tag = "wrong-answer"
+	PresentationError      Tag = "presentation-error"
+	TimeLimit              Tag = "time-limit-exceeded"
+	TimeLimitOrAccepted    Tag = "time-limit-exceeded-or-accepted"
+	TimeLimitOrMemoryLimit Tag = "time-limit-exceeded-or-memory-limit-exceeded"
+	MemoryLimit            Tag = "memory-limit-exceeded"
+	Rejected               Tag = "rejected" // Any incorrect result
+	Failed                 Tag = "failed"   // Solution should fail checker or crash
+	DoNotRun               Tag = "do-not-run"
+)
+
+// All lists all known tags
+var All = []Tag{
+	Main,
+	Accepted,
+	WrongAnswer,
+	PresentationError,
+	TimeLimit,
+	TimeLimitOrAccepted,
+	TimeLimitOrMemoryLimit,
+	MemoryLimit,
+	Rejected,
+	Failed,
+	DoNotRun,
+}
+
+func (t Tag) IsValid() bool {
+	return slices.Contains(All, t)
+}
+
+type expectedVerdicts struct {
+	// allowed are verdicts that tests of solution may have
+	allowed []verdict.Verdict
+	// required are verdicts at least one of which should be on some test, empty means no such requirement
+	required []verdict.Verdict
+}
+
+var tagVerdicts = map[Tag]expectedVerdicts{
+	Main:     {allowed: []verdict.Verdict{verdict.OK}},
+	Accepted: {allowed: []verdict.Verdict{verdict.OK}},
+	WrongAnswer: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.WA, verdict.WR, verdict.PT},
+		required: []verdict.Verdict{verdict.WA, verdict.WR, verdict.PT},
+	},
+	PresentationError: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.WA, verdict.WR, verdict.PT},
+		required: []verdict.Verdict{verdict.WA, verdict.WR, verdict.PT},
+	},
+	TimeLimit: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.TL, verdict.WL},
+		required: []verdict.Verdict{verdict.TL, verdict.WL},
+	},
+	TimeLimitOrAccepted: {allowed: []verdict.Verdict{verdict.OK, verdict.TL, verdict.WL}},
+	TimeLimitOrMemoryLimit: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.TL, verdict.WL, verdict.ML},
+		required: []verdict.Verdict{verdict.TL, verdict.WL, verdict.ML},
+	},
+	MemoryLimit: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.ML},
+		required: []verdict.Verdict{verdict.ML},
+	},
+	Rejected: {
+		allowed: []verdict.Verdict{
+			verdict.OK, verdict.PT, verdict.WA, verdict.WR, verdict.RT, verdict.ML, verdict.TL, verdict.WL, verdict.SE,
+		},
+		required: []verdict.Verdict{verdict.PT, verdict.WA, verdict.WR, verdict.RT, verdict.ML, verdict.TL, verdict.WL, verdict.SE},
+	},
+	Failed: {
+		allowed:  []verdict.Verdict{verdict.OK, verdict.RT, verdict.SE},
+		required: []verdict.Verdict{verdict.RT, verdict.SE},
+	},
+}
+
+// Matches checks that test verdicts of solution correspond to the tag. Skipped tests are ignored,
+// compilation error and check failed never match
+func (t Tag) Matches(verdicts []verdict.Verdict) bool {
+	expected, ok := tagVerdicts[t]
+	if !ok {
+		return false
+	}
+	hasRequired := len(expected.required) == 0
+	for _, v := range verdicts {
+		if v == verdict.SK {
+			continue
+		}
+		if !slices.Contains(expected.allowed, v) {
+			return false
+		}
+		if slices.Contains(expected.required, v) {
+			hasRequired = true
+		}
+	}
+	return hasRequired
+}
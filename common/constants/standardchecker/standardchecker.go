@@ -0,0 +1,32 @@
+package standardchecker
+
+import "slices"
+
+// Names of builtin checkers, they are the same as names of testlib standard checkers
+const (
+	Tokens   = "wcmp"
+	Lines    = "lcmp"
+	Integers = "ncmp"
+	// Floats compares float numbers with problem checker epsilon
+	Floats  = "rcmp"
+	Floats4 = "rcmp4"
+	Floats6 = "rcmp6"
+	Floats9 = "rcmp9"
+	YesNo   = "yesno"
+)
+
+// All lists sorted names of all builtin checkers
+var All = []string{
+	Lines,
+	Integers,
+	Floats,
+	Floats4,
+	Floats6,
+	Floats9,
+	Tokens,
+	YesNo,
+}
+
+func IsValid(name string) bool {
+	return slices.Contains(All, name)
+}
@@ -21,6 +21,7 @@ const (
 
 	CF Verdict = "CF" // Check failed
 	SK Verdict = "SK" // Skipped
+	CL Verdict = "CL" // Cancelled
 
 	RU Verdict = "RU" // Running
 )
@@ -24,9 +24,30 @@ func NewDB(config config.DBConfig) (*gorm.DB, error) {
 	if err = db.AutoMigrate(&models.Problem{}); err != nil {
 		return nil, logger.Error("Can't migrate Problem: %v", err)
 	}
+	if err = db.AutoMigrate(&models.ProblemRevision{}); err != nil {
+		return nil, logger.Error("Can't migrate ProblemRevision: %v", err)
+	}
+	if err = db.AutoMigrate(&models.User{}); err != nil {
+		return nil, logger.Error("Can't migrate User: %v", err)
+	}
+	if err = db.AutoMigrate(&models.Session{}); err != nil {
+		return nil, logger.Error("Can't migrate Session: %v", err)
+	}
+	if err = db.AutoMigrate(&models.APIToken{}); err != nil {
+		return nil, logger.Error("Can't migrate APIToken: %v", err)
+	}
+	if err = db.AutoMigrate(&models.Contest{}); err != nil {
+		return nil, logger.Error("Can't migrate Contest: %v", err)
+	}
 	if err = db.AutoMigrate(&models.Submission{}); err != nil {
 		return nil, logger.Error("Can't migrate Submission: %v", err)
 	}
+	if err = db.AutoMigrate(&models.SubmissionHistory{}); err != nil {
+		return nil, logger.Error("Can't migrate SubmissionHistory: %v", err)
+	}
+	if err = db.AutoMigrate(&models.Invocation{}); err != nil {
+		return nil, logger.Error("Can't migrate Invocation: %v", err)
+	}
 	logger.Info("Configured DB successfully")
 	return db, err
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type Contest struct {
+	ID        uint           `gorm:"primarykey" json:"id" yaml:"id"`
+	CreatedAt time.Time      `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" yaml:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-" yaml:"-"`
+
+	Name string `json:"name" yaml:"name" binding:"required"`
+
+	StartTime time.Time `json:"start_time" yaml:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" yaml:"end_time" binding:"required"`
+
+	// Problems is contest problem set, problems are ordered by id
+	Problems []*Problem `gorm:"many2many:contest_problems;" json:"problems" yaml:"problems"`
+}
+
+// IsRunning reports whether submissions of participants are accepted as live contest submissions at time t
+func (c *Contest) IsRunning(t time.Time) bool {
+	return !t.Before(c.StartTime) && t.Before(c.EndTime)
+}
+
+func (c *Contest) HasProblem(problemID uint) bool {
+	return slices.ContainsFunc(c.Problems, func(problem *Problem) bool {
+		return problem.ID == problemID
+	})
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"testing_system/common/constants/solutiontag"
+	"time"
+)
+
+// InvocationSolution is reference solution run of invocation, it is tested as submission SubmissionID
+type InvocationSolution struct {
+	Name         string          `json:"name" yaml:"name"`
+	Language     string          `json:"language" yaml:"language"`
+	Tag          solutiontag.Tag `json:"tag" yaml:"tag"`
+	SubmissionID uint            `json:"submission_id" yaml:"submission_id"`
+}
+
+type InvocationSolutions []*InvocationSolution
+
+func (t InvocationSolutions) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *InvocationSolutions) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed while scanning InvocationSolutions")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t InvocationSolutions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	}
+	return ""
+}
+
+// Invocation is a run of problem reference solutions on all tests of problem revision
+type Invocation struct {
+	ID        uint      `gorm:"primarykey" json:"id" yaml:"id"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+
+	ProblemID       uint    `gorm:"index" json:"problem_id" yaml:"problem_id"`
+	Problem         Problem `gorm:"constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-" yaml:"-"`
+	ProblemRevision uint64  `json:"problem_revision" yaml:"problem_revision"`
+
+	Solutions InvocationSolutions `json:"solutions" yaml:"solutions"`
+}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"testing"
+	"testing_system/common/constants/role"
 	"testing_system/common/constants/verdict"
 	"testing_system/lib/customfields"
 	"time"
@@ -15,8 +16,11 @@ import (
 
 func fixtureDb(t *testing.T) *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	assert.NoError(t, db.AutoMigrate(&Submission{}))
 	assert.NoError(t, db.AutoMigrate(&Problem{}))
+	assert.NoError(t, db.AutoMigrate(&ProblemRevision{}))
+	assert.NoError(t, db.AutoMigrate(&User{}))
+	assert.NoError(t, db.AutoMigrate(&Contest{}))
+	assert.NoError(t, db.AutoMigrate(&Submission{}))
 	return db
 }
 
@@ -98,3 +102,111 @@ func TestTestResultsDB(t *testing.T) {
 		require.Equal(t, submission.TestResults, newSubmission.TestResults)
 	})
 }
+
+func TestContestSubmissionsDB(t *testing.T) {
+	db := fixtureDb(t)
+	problem := Problem{Name: "A", ProblemType: ProblemTypeICPC, TestsNumber: 1}
+	require.NoError(t, db.Create(&problem).Error)
+	user := User{Login: "user"}
+	require.NoError(t, db.Create(&user).Error)
+	contest := Contest{
+		Name:      "Contest",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now().Add(time.Hour),
+		Problems:  []*Problem{&problem},
+	}
+	require.NoError(t, db.Create(&contest).Error)
+
+	submission := Submission{
+		ProblemID: problem.ID,
+		UserID:    &user.ID,
+		ContestID: &contest.ID,
+		Language:  "cpp",
+		Verdict:   verdict.RU,
+	}
+	require.NoError(t, db.Create(&submission).Error)
+
+	var loadedContest Contest
+	require.NoError(t, db.Preload("Problems").First(&loadedContest, contest.ID).Error)
+	require.True(t, loadedContest.HasProblem(problem.ID))
+	require.False(t, loadedContest.HasProblem(problem.ID+1))
+	require.True(t, loadedContest.IsRunning(time.Now()))
+	require.False(t, loadedContest.IsRunning(time.Now().Add(2*time.Hour)))
+
+	var submissions []Submission
+	require.NoError(t, db.Where("user_id = ? AND contest_id = ?", user.ID, contest.ID).Find(&submissions).Error)
+	require.Len(t, submissions, 1)
+	require.Equal(t, submission.ID, submissions[0].ID)
+}
+
+func TestProblemRevisionsDB(t *testing.T) {
+	db := fixtureDb(t)
+	problem := Problem{Name: "A", ProblemType: ProblemTypeICPC, TestsNumber: 1, Revision: 1}
+	// Snapshot of the first revision is created when the revision is changed
+	require.NoError(t, db.Create(&problem).Error)
+
+	newProblem := problem
+	newProblem.TestsNumber = 2
+	require.NoError(t, SaveNewProblemRevision(db, &problem, &newProblem))
+	require.Equal(t, uint64(2), newProblem.Revision)
+	// The same revision can not be created twice
+	require.Error(t, SaveNewProblemRevision(db, &problem, &newProblem))
+
+	oldProblem, err := LoadProblemRevision(db, problem.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, problem.ID, oldProblem.ID)
+	require.Equal(t, uint64(1), oldProblem.Revision)
+	require.Equal(t, uint64(1), oldProblem.TestsNumber)
+
+	currentProblem, err := LoadProblemRevision(db, problem.ID, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), currentProblem.TestsNumber)
+
+	_, err = LoadProblemRevision(db, problem.ID, 3)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUserPassword(t *testing.T) {
+	db := fixtureDb(t)
+	user := User{Login: "admin", Role: role.Admin}
+	require.False(t, user.CheckPassword(""))
+	require.NoError(t, user.SetPassword("secret"))
+	require.NoError(t, db.Create(&user).Error)
+
+	var loadedUser User
+	require.NoError(t, db.First(&loadedUser, user.ID).Error)
+	require.True(t, loadedUser.CheckPassword("secret"))
+	require.False(t, loadedUser.CheckPassword("Secret"))
+	require.True(t, loadedUser.Role.Allows(role.Jury))
+	require.False(t, role.Participant.Allows(role.Jury))
+
+	token, err := NewSecretToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, HashSecretToken(token))
+	require.Equal(t, HashSecretToken(token), HashSecretToken(token))
+}
+
+func TestParseTestScript(t *testing.T) {
+	script, err := ParseTestScript(`
+# samples are uploaded
+gen 10 1 > 2
+gen_tree -n 100   -seed 5 > 3
+
+gen > 4
+`)
+	require.NoError(t, err)
+	require.Equal(t, TestScript{
+		{Test: 2, Generator: "gen", Args: []string{"10", "1"}},
+		{Test: 3, Generator: "gen_tree", Args: []string{"-n", "100", "-seed", "5"}},
+		{Test: 4, Generator: "gen", Args: []string{}},
+	}, script)
+	require.Equal(t, []string{"gen", "gen_tree"}, script.Generators())
+	require.Equal(t, "gen_tree -n 100 -seed 5 > 3", script[1].String())
+
+	_, err = ParseTestScript("gen 1 > $")
+	require.Error(t, err)
+	_, err = ParseTestScript("gen 1")
+	require.Error(t, err)
+	_, err = ParseTestScript(" > 1")
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/xorcare/pointer"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"testing_system/common/constants/solutiontag"
 	"testing_system/lib/customfields"
 	"time"
 )
@@ -16,6 +17,39 @@ type ProblemType int
 const (
 	ProblemTypeICPC ProblemType = iota + 1
 	ProblemTypeIOI
+	// ProblemTypeOutputOnly means that submission is zip archive of outputs, one file per test named by test number
+	// (e.g. "1", "01" or "1.out"), so several files of one test get WA. Outputs are checked without compilation
+	// and scored by groups as in IOI problems
+	ProblemTypeOutputOnly
+	// ProblemTypeTwoPhase means that solution binary is run twice on each test, with argument "1" and then with "2".
+	// The first run reads input from stdin, its stdout is the second run stdin, the second run output is checked.
+	// Time and memory limits are applied to each run, tests are scored by groups as in IOI problems
+	ProblemTypeTwoPhase
+)
+
+// HasTestGroups returns whether problem tests are scored by Problem.TestGroups
+func (t ProblemType) HasTestGroups() bool {
+	return t == ProblemTypeIOI || t == ProblemTypeOutputOnly || t == ProblemTypeTwoPhase
+}
+
+// CheckerType sets how invoker checks solution output
+type CheckerType int
+
+const (
+	// CheckerTypeTestlib means that checker binary writes testlib result xml file (-appes mode). Used by default
+	CheckerTypeTestlib CheckerType = iota + 1
+	// CheckerTypeExitCode means that checker binary verdict is its exit code: 0 is OK, 1 is WA, anything else is CF
+	CheckerTypeExitCode
+	// CheckerTypeEjudge means that checker binary uses ejudge exit codes: 0 is OK, 4 and 5 are WA, anything else is CF
+	CheckerTypeEjudge
+	// CheckerTypeStandard uses invoker built-in implementation of testlib checker Problem.StandardChecker
+	CheckerTypeStandard
+	// CheckerTypeKattis means that checker binary is kattis output validator run as `check input answer feedback_dir`
+	// with solution output as stdin: 42 is OK, 43 is WA, anything else is CF
+	CheckerTypeKattis
+	// CheckerTypeCMS means that checker binary is CMS checker run as `check input answer output`: it writes score
+	// from 0 to 1 to stdout and message to stderr. Partial score is multiplied by test cost in its group
+	CheckerTypeCMS
 )
 
 // TestGroupScoringType sets how should scheduler set points for a group
@@ -96,6 +130,100 @@ func (t TestGroups) GormDBDataType(db *gorm.DB, field *schema.Field) string {
 	return ""
 }
 
+// ProblemSolution is reference solution of problem, its source is stored as resource.Solution named Name
+type ProblemSolution struct {
+	Name     string          `json:"name" yaml:"name"`
+	Language string          `json:"language" yaml:"language"`
+	Tag      solutiontag.Tag `json:"tag" yaml:"tag"`
+}
+
+type ProblemSolutions []*ProblemSolution
+
+func (t ProblemSolutions) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *ProblemSolutions) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed while scanning ProblemSolutions")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t ProblemSolutions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	}
+	return ""
+}
+
+// ProblemStatement is problem statement file, it is stored as resource.Statement named Name
+type ProblemStatement struct {
+	Name     string `json:"name" yaml:"name"`
+	Language string `json:"language" yaml:"language"`
+	// Type is MIME type of statement, e.g. application/pdf
+	Type string `json:"type" yaml:"type"`
+}
+
+type ProblemStatements []*ProblemStatement
+
+func (t ProblemStatements) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *ProblemStatements) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed while scanning ProblemStatements")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t ProblemStatements) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	}
+	return ""
+}
+
+// ProblemGrader is jury file that is compiled together with submissions in Language,
+// it is stored as resource.Grader named Name and is put into compilation sandbox with the same name
+type ProblemGrader struct {
+	Name     string `json:"name" yaml:"name"`
+	Language string `json:"language" yaml:"language"`
+}
+
+type ProblemGraders []*ProblemGrader
+
+func (t ProblemGraders) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *ProblemGraders) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed while scanning ProblemGraders")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t ProblemGraders) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	}
+	return ""
+}
+
 type Problem struct {
 	ID        uint           `gorm:"primarykey" json:"id" yaml:"id"`
 	CreatedAt time.Time      `json:"created_at" yaml:"created_at"`
@@ -104,6 +232,11 @@ type Problem struct {
 
 	Name string `yaml:"name" json:"name" binding:"required"`
 
+	// Revision is increased on every problem modification. Problem resources are stored in storage per revision,
+	// submissions are tested against revision they were submitted or rejudged with.
+	// Revision 0 means that problem was created before revisions and its resources are stored without revision
+	Revision uint64 `yaml:"revision,omitempty" json:"revision"`
+
 	ProblemType ProblemType `yaml:"problem_type" json:"problem_type" binding:"required"`
 
 	// TestGroups ignored for ICPC problems
@@ -130,4 +263,177 @@ type Problem struct {
 	// MaxOutputSize specifies maximum output in EACH file.
 	// By default, it is 1g
 	MaxOutputSize *customfields.Memory `yaml:"max_output_size,omitempty" json:"max_output_size,omitempty"`
+
+	// Interactive specifies that solution communicates with problem interactor instead of reading input file.
+	// Interactor receives input.txt, output.txt and answer.txt, its output.txt is checked by checker afterward
+	Interactive bool `yaml:"interactive,omitempty" json:"interactive,omitempty"`
+
+	// CheckerType specifies how solution output is checked.
+	// By default, it is CheckerTypeTestlib
+	CheckerType CheckerType `yaml:"checker_type,omitempty" json:"checker_type,omitempty"`
+
+	// StandardChecker specifies testlib checker name (wcmp, lcmp, ncmp, rcmp, rcmp4, rcmp6, rcmp9, yesno)
+	// for CheckerTypeStandard
+	StandardChecker string `yaml:"standard_checker,omitempty" json:"standard_checker,omitempty"`
+
+	// CheckerEpsilon specifies max absolute or relative error of float numbers for rcmp standard checker.
+	// By default, it is 1.5e-6 as in testlib rcmp
+	CheckerEpsilon *float64 `yaml:"checker_epsilon,omitempty" json:"checker_epsilon,omitempty"`
+
+	// Solutions are reference solutions of problem, they are not tested automatically
+	Solutions ProblemSolutions `yaml:"solutions,omitempty" json:"solutions,omitempty"`
+
+	// Statements are problem statements in different languages and formats
+	Statements ProblemStatements `yaml:"statements,omitempty" json:"statements,omitempty"`
+
+	// TestScript lists tests whose inputs are generated by generators, other tests inputs are uploaded.
+	// Test generation runs the script and writes answers of all tests with main solution
+	TestScript TestScript `yaml:"test_script,omitempty" json:"test_script,omitempty"`
+
+	// HasValidator specifies that input validator is uploaded as resource.Validator.
+	// Validator reads test input from stdin and exits with nonzero code if input is invalid
+	HasValidator bool `yaml:"has_validator,omitempty" json:"has_validator,omitempty"`
+
+	// Graders are compiled together with submissions in their languages, e.g. grader.cpp with main function
+	// for IOI style problems where contestants implement a function. Compile scripts get them as "graders" value
+	Graders ProblemGraders `yaml:"graders,omitempty" json:"graders,omitempty"`
+}
+
+// UsesCheckerBinary reports whether problem checker is uploaded to storage as resource.Checker
+func (p *Problem) UsesCheckerBinary() bool {
+	switch p.CheckerType {
+	case CheckerTypeStandard:
+		return false
+	default:
+		return true
+	}
+}
+
+// FindTestGroup returns group that contains test or nil if there is no such group
+func (p *Problem) FindTestGroup(test uint64) *TestGroup {
+	for _, group := range p.TestGroups {
+		if group.FirstTest <= test && test <= group.LastTest {
+			return group
+		}
+	}
+	return nil
+}
+
+// FindSolution returns reference solution with name or nil if there is no such solution
+func (p *Problem) FindSolution(name string) *ProblemSolution {
+	for _, solution := range p.Solutions {
+		if solution.Name == name {
+			return solution
+		}
+	}
+	return nil
+}
+
+// FindTestScriptLine returns script line that generates test or nil if test input is uploaded
+func (p *Problem) FindTestScriptLine(test uint64) *TestScriptLine {
+	for _, line := range p.TestScript {
+		if line.Test == test {
+			return line
+		}
+	}
+	return nil
+}
+
+// MainSolution returns reference solution tagged as main or nil if there is no such solution
+func (p *Problem) MainSolution() *ProblemSolution {
+	for _, solution := range p.Solutions {
+		if solution.Tag == solutiontag.Main {
+			return solution
+		}
+	}
+	return nil
+}
+
+// FindStatement returns statement with name or nil if there is no such statement
+func (p *Problem) FindStatement(name string) *ProblemStatement {
+	for _, statement := range p.Statements {
+		if statement.Name == name {
+			return statement
+		}
+	}
+	return nil
+}
+
+// FindGrader returns grader with name or nil if there is no such grader
+func (p *Problem) FindGrader(name string) *ProblemGrader {
+	for _, grader := range p.Graders {
+		if grader.Name == name {
+			return grader
+		}
+	}
+	return nil
+}
+
+// LanguageGraders returns names of graders that are compiled with submissions in language
+func (p *Problem) LanguageGraders(language string) []string {
+	var names []string
+	for _, grader := range p.Graders {
+		if grader.Language == language {
+			names = append(names, grader.Name)
+		}
+	}
+	return names
+}
+
+// ProblemRevision keeps problem as it was at its revision.
+// Submissions that recorded old revision are tested with this snapshot instead of modified problem
+type ProblemRevision struct {
+	ID        uint      `gorm:"primarykey" json:"id" yaml:"id"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	ProblemID uint    `gorm:"uniqueIndex:problem_revision" json:"problem_id" yaml:"problem_id"`
+	Revision  uint64  `gorm:"uniqueIndex:problem_revision" json:"revision" yaml:"revision"`
+	Snapshot  Problem `gorm:"serializer:json" json:"snapshot" yaml:"snapshot"`
+}
+
+func NewProblemRevision(problem *Problem) *ProblemRevision {
+	return &ProblemRevision{
+		ProblemID: problem.ID,
+		Revision:  problem.Revision,
+		Snapshot:  *problem,
+	}
+}
+
+// SaveNewProblemRevision saves problem with increased revision in short transaction, snapshot of previous revision
+// is kept for submissions that were tested against it. Files should be uploaded to storage before, with revision
+// oldProblem.Revision + 1, so all files uploaded to this draft revision are committed together with problem
+func SaveNewProblemRevision(db *gorm.DB, oldProblem *Problem, problem *Problem) error {
+	problem.Revision = oldProblem.Revision + 1
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Problems created before revisions have no snapshot of their revision
+		err := tx.Where("problem_id = ? AND revision = ?", oldProblem.ID, oldProblem.Revision).
+			FirstOrCreate(NewProblemRevision(oldProblem)).
+			Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Save(problem).Error; err != nil {
+			return err
+		}
+		return tx.Create(NewProblemRevision(problem)).Error
+	})
+}
+
+// LoadProblemRevision loads problem as it was at revision.
+// gorm.ErrRecordNotFound is returned if there is no such problem or revision
+func LoadProblemRevision(db *gorm.DB, problemID uint, revision uint64) (*Problem, error) {
+	problem := new(Problem)
+	if err := db.First(problem, problemID).Error; err != nil {
+		return nil, err
+	}
+	if problem.Revision == revision {
+		return problem, nil
+	}
+
+	problemRevision := new(ProblemRevision)
+	err := db.Where("problem_id = ? AND revision = ?", problemID, revision).First(problemRevision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &problemRevision.Snapshot, nil
 }
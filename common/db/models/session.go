@@ -0,0 +1,52 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const secretTokenLength = 32
+
+// Session is a logged in client of user. Only hash of session token is stored
+type Session struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	TokenHash string `gorm:"uniqueIndex"`
+	CSRFToken string
+	ExpiresAt time.Time `gorm:"index"`
+
+	UserID uint  `gorm:"index"`
+	User   *User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// APIToken is used by scripts to access client api without session. Only hash of token is stored
+type APIToken struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	Name      string `json:"name" binding:"required"`
+	TokenHash string `gorm:"uniqueIndex" json:"-"`
+
+	UserID uint  `gorm:"index" json:"user_id"`
+	User   *User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// NewSecretToken generates random token for sessions and api tokens
+func NewSecretToken() (string, error) {
+	token := make([]byte, secretTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("can not generate secret token, error: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// HashSecretToken returns hash of token that is stored in db instead of token itself
+func HashSecretToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
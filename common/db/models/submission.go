@@ -6,6 +6,7 @@ import (
 	"errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"testing_system/common/constants/priority"
 	"testing_system/common/constants/verdict"
 	"testing_system/lib/customfields"
 	"time"
@@ -107,7 +108,28 @@ type Submission struct {
 
 	ProblemID uint    `gorm:"index:problem_submission,priority:1" json:"problem_id" yaml:"problem_id"`
 	Problem   Problem `gorm:"constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-" yaml:"-"`
-	Language  string  `json:"language" yaml:"language"`
+	// ProblemRevision is revision of problem that submission is tested against
+	ProblemRevision uint64 `json:"problem_revision" yaml:"problem_revision"`
+	Language        string `json:"language" yaml:"language"`
+	// Priority sets submission queue class. Empty priority means priority.Default
+	Priority priority.Priority `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// UserID and ContestID are optional, they are also used by master queue for fair-share scheduling
+	UserID    *uint    `gorm:"index" json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	User      *User    `gorm:"constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-" yaml:"-"`
+	ContestID *uint    `gorm:"index" json:"contest_id,omitempty" yaml:"contest_id,omitempty"`
+	Contest   *Contest `gorm:"constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-" yaml:"-"`
+
+	// SourceHash is hex SHA-256 of submission source, it is used to find finished submissions with same source
+	SourceHash string `gorm:"index" json:"source_hash,omitempty" yaml:"source_hash,omitempty"`
+	// SourceArchive is set when submission source is zip archive of several files, it is unpacked before compilation
+	SourceArchive bool `gorm:"not null;default:false" json:"source_archive,omitempty" yaml:"source_archive,omitempty"`
+	// CachedFromID is set when results were copied from a finished submission with same source instead of testing
+	CachedFromID *uint `json:"cached_from_id,omitempty" yaml:"cached_from_id,omitempty"`
+	// InvocationID is set for reference solution runs, such submissions are tested on all tests
+	InvocationID *uint `gorm:"index" json:"invocation_id,omitempty" yaml:"invocation_id,omitempty"`
+	// GeneratesTests is set for main solution runs that generate problem tests instead of being tested on them
+	GeneratesTests bool `gorm:"not null;default:false" json:"generates_tests,omitempty" yaml:"generates_tests,omitempty"`
 
 	Score             float64         `json:"score" yaml:"score"`
 	Verdict           verdict.Verdict `json:"verdict" yaml:"verdict"`
@@ -115,3 +137,53 @@ type Submission struct {
 	CompilationResult *TestResult     `json:"compilation_result" yaml:"compilation_result"`
 	GroupResults      GroupResults    `json:"group_results,omitempty" yaml:"group_results,omitempty"`
 }
+
+// ResetResults clears testing results before the submission is tested again
+func (s *Submission) ResetResults() {
+	s.Score = 0
+	s.Verdict = verdict.RU
+	s.TestResults = nil
+	s.CompilationResult = nil
+	s.GroupResults = nil
+	s.CachedFromID = nil
+}
+
+// CopyResults sets results of finished submission with same source to s
+func (s *Submission) CopyResults(cached *Submission) {
+	s.Score = cached.Score
+	s.Verdict = cached.Verdict
+	s.TestResults = cached.TestResults
+	s.CompilationResult = cached.CompilationResult
+	s.GroupResults = cached.GroupResults
+	if cached.CachedFromID != nil {
+		s.CachedFromID = cached.CachedFromID
+	} else {
+		s.CachedFromID = &cached.ID
+	}
+}
+
+// SubmissionHistory stores submission results that were replaced by rejudge
+type SubmissionHistory struct {
+	ID        uint      `gorm:"primarykey" json:"id" yaml:"id"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	SubmissionID uint       `gorm:"index" json:"submission_id" yaml:"submission_id"`
+	Submission   Submission `gorm:"constraint:OnUpdate:RESTRICT,OnDelete:RESTRICT;" json:"-" yaml:"-"`
+
+	Score             float64         `json:"score" yaml:"score"`
+	Verdict           verdict.Verdict `json:"verdict" yaml:"verdict"`
+	TestResults       TestResults     `json:"test_results" yaml:"test_results"`
+	CompilationResult *TestResult     `json:"compilation_result" yaml:"compilation_result"`
+	GroupResults      GroupResults    `json:"group_results,omitempty" yaml:"group_results,omitempty"`
+}
+
+func NewSubmissionHistory(submission *Submission) *SubmissionHistory {
+	return &SubmissionHistory{
+		SubmissionID:      submission.ID,
+		Score:             submission.Score,
+		Verdict:           submission.Verdict,
+		TestResults:       submission.TestResults,
+		CompilationResult: submission.CompilationResult,
+		GroupResults:      submission.GroupResults,
+	}
+}
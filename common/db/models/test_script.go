@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"strconv"
+	"strings"
+)
+
+// TestScriptLine generates input of Test by running generator binary Generator with Args.
+// Generator is stored as resource.Generator named Generator
+type TestScriptLine struct {
+	Test      uint64   `json:"test" yaml:"test"`
+	Generator string   `json:"generator" yaml:"generator"`
+	Args      []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// String returns line in polygon script format
+func (l *TestScriptLine) String() string {
+	parts := append([]string{l.Generator}, l.Args...)
+	return fmt.Sprintf("%s > %d", strings.Join(parts, " "), l.Test)
+}
+
+type TestScript []*TestScriptLine
+
+func (t TestScript) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *TestScript) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed while scanning TestScript")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t TestScript) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	}
+	return ""
+}
+
+// Generators returns names of all generators used in script, each name is returned once
+func (t TestScript) Generators() []string {
+	var names []string
+	used := make(map[string]struct{})
+	for _, line := range t {
+		if _, ok := used[line.Generator]; !ok {
+			used[line.Generator] = struct{}{}
+			names = append(names, line.Generator)
+		}
+	}
+	return names
+}
+
+// ParseTestScript parses polygon style script, each line is "<generator> [args...] > <test>".
+// Empty lines and lines starting with # are skipped. Test number is required, "> $" is not supported
+func ParseTestScript(script string) (TestScript, error) {
+	var result TestScript
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		command, test, ok := strings.Cut(line, ">")
+		if !ok {
+			return nil, fmt.Errorf("line %d: no test number after >", lineNumber)
+		}
+		testID, err := strconv.ParseUint(strings.TrimSpace(test), 10, 64)
+		if err != nil || testID == 0 {
+			return nil, fmt.Errorf("line %d: invalid test number %s", lineNumber, strings.TrimSpace(test))
+		}
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: no generator specified", lineNumber)
+		}
+		result = append(result, &TestScriptLine{
+			Test:      testID,
+			Generator: fields[0],
+			Args:      fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
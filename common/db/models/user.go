@@ -0,0 +1,79 @@
+package models
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"testing_system/common/constants/role"
+	"time"
+)
+
+const (
+	passwordHashIterations = 600000
+	passwordHashKeyLength  = 32
+	passwordSaltLength     = 16
+	passwordHashAlgorithm  = "pbkdf2-sha256"
+)
+
+type User struct {
+	ID        uint           `gorm:"primarykey" json:"id" yaml:"id"`
+	CreatedAt time.Time      `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" yaml:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-" yaml:"-"`
+
+	Login string    `gorm:"uniqueIndex" json:"login" yaml:"login" binding:"required"`
+	Name  string    `json:"name" yaml:"name"`
+	Role  role.Role `json:"role" yaml:"role"`
+
+	PasswordHash string `json:"-" yaml:"-"`
+}
+
+// SetPassword stores salted pbkdf2 hash of password in user
+func (u *User) SetPassword(password string) error {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("can not generate password salt, error: %v", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordHashIterations, passwordHashKeyLength)
+	if err != nil {
+		return fmt.Errorf("can not hash password, error: %v", err)
+	}
+	u.PasswordHash = strings.Join([]string{
+		passwordHashAlgorithm,
+		strconv.Itoa(passwordHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$")
+	return nil
+}
+
+// CheckPassword reports whether password matches stored hash. Users without password can not log in
+func (u *User) CheckPassword(password string) bool {
+	parts := strings.Split(u.PasswordHash, "$")
+	if len(parts) != 4 || parts[0] != passwordHashAlgorithm {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expectedKey, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expectedKey))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(key, expectedKey) == 1
+}
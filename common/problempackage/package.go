@@ -0,0 +1,329 @@
+package problempackage
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"testing_system/common/constants/resource"
+	"testing_system/common/db/models"
+	"time"
+)
+
+const (
+	problemFile      = "problem.yaml"
+	testsFolder      = "tests"
+	checkerFolder    = "checker"
+	interactorFolder = "interactor"
+	solutionsFolder  = "solutions"
+	statementsFolder = "statements"
+	generatorsFolder = "generators"
+	validatorFolder  = "validator"
+	gradersFolder    = "graders"
+
+	answerSuffix = ".a"
+
+	// maxFileSize limits size of single unpacked package file
+	maxFileSize = 1 << 30
+	// maxPackageSize limits total size of unpacked package files
+	maxPackageSize = 4 << 30
+	// maxProblemFileSize limits size of problem.yaml, which is the only file read into memory
+	maxProblemFileSize = 1 << 20
+)
+
+// File is a problem resource inside package
+type File struct {
+	Resource resource.Type
+	// TestID is set only for test inputs and answers
+	TestID uint64
+	// Name is kept for checker, interactor, solutions, statements, generators, validator and graders
+	Name string
+	// Data is file content. Files of read package keep it in archive, so Data is empty
+	Data []byte
+
+	zipFile *zip.File
+}
+
+// Package is a problem in native archive format. Archive is a zip with following files:
+//
+//	problem.yaml          models.Problem fields
+//	tests/01, tests/01.a  test inputs and answers
+//	checker/<name>        checker binary, if problem uses it
+//	interactor/<name>     interactor binary of interactive problem
+//	solutions/<name>      reference solutions listed in problem.yaml
+//	statements/<name>     statements listed in problem.yaml
+//	generators/<name>     generator binaries used in problem.yaml test script
+//	validator/<name>      input validator binary, if problem has it
+//	graders/<name>        graders listed in problem.yaml
+type Package struct {
+	Problem *models.Problem
+	Files   []*File
+}
+
+// Read parses and validates package archive. Only problem.yaml is read into memory,
+// other files are read from archive when package is written or imported, so r should stay open until then
+func Read(r io.ReaderAt, size int64) (*Package, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("can not open package archive, error: %v", err)
+	}
+
+	pkg := new(Package)
+	var totalSize uint64
+	paths := make(map[string]string)
+	for _, zipFile := range archive.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+		// zip reader fails if file is larger than its header says, so header sizes can be trusted
+		if zipFile.UncompressedSize64 > maxFileSize {
+			return nil, fmt.Errorf("file %s is too large", zipFile.Name)
+		}
+		totalSize += zipFile.UncompressedSize64
+		if totalSize > maxPackageSize {
+			return nil, fmt.Errorf("package is too large")
+		}
+
+		if zipFile.Name == problemFile {
+			if pkg.Problem != nil {
+				return nil, fmt.Errorf("duplicate file %s", problemFile)
+			}
+			data, err := readZipFile(zipFile, maxProblemFileSize)
+			if err != nil {
+				return nil, err
+			}
+			pkg.Problem = new(models.Problem)
+			if err = yaml.Unmarshal(data, pkg.Problem); err != nil {
+				return nil, fmt.Errorf("can not parse %s, error: %v", problemFile, err)
+			}
+			continue
+		}
+
+		file, err := parseFileName(zipFile.Name)
+		if err != nil {
+			return nil, err
+		}
+		// Test names like 1 and 01 are the same test
+		if name, ok := paths[file.path()]; ok {
+			return nil, fmt.Errorf("files %s and %s are the same package file", name, zipFile.Name)
+		}
+		paths[file.path()] = zipFile.Name
+		file.zipFile = zipFile
+		pkg.Files = append(pkg.Files, file)
+	}
+
+	if err = pkg.validate(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func readZipFile(zipFile *zip.File, limit int64) ([]byte, error) {
+	if zipFile.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("file %s is too large", zipFile.Name)
+	}
+	reader, err := zipFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("can not open file %s, error: %v", zipFile.Name, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, limit))
+	if err != nil {
+		return nil, fmt.Errorf("can not read file %s, error: %v", zipFile.Name, err)
+	}
+	return data, nil
+}
+
+// Open returns file content reader, either from read package archive or from Data
+func (f *File) Open() (io.ReadCloser, error) {
+	if f.zipFile == nil {
+		return io.NopCloser(bytes.NewReader(f.Data)), nil
+	}
+	reader, err := f.zipFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("can not open file %s, error: %v", f.zipFile.Name, err)
+	}
+	return reader, nil
+}
+
+func parseFileName(name string) (*File, error) {
+	folder, filename := path.Split(name)
+	if filename == "" {
+		return nil, fmt.Errorf("unexpected file %s", name)
+	}
+	switch strings.TrimSuffix(folder, "/") {
+	case testsFolder:
+		file := &File{Resource: resource.TestInput}
+		if testName, ok := strings.CutSuffix(filename, answerSuffix); ok {
+			file.Resource = resource.TestAnswer
+			filename = testName
+		}
+		testID, err := strconv.ParseUint(filename, 10, 64)
+		if err != nil || testID == 0 {
+			return nil, fmt.Errorf("unexpected test file %s", name)
+		}
+		file.TestID = testID
+		return file, nil
+	case checkerFolder:
+		return &File{Resource: resource.Checker, Name: filename}, nil
+	case interactorFolder:
+		return &File{Resource: resource.Interactor, Name: filename}, nil
+	case solutionsFolder:
+		return &File{Resource: resource.Solution, Name: filename}, nil
+	case statementsFolder:
+		return &File{Resource: resource.Statement, Name: filename}, nil
+	case generatorsFolder:
+		return &File{Resource: resource.Generator, Name: filename}, nil
+	case validatorFolder:
+		return &File{Resource: resource.Validator, Name: filename}, nil
+	case gradersFolder:
+		return &File{Resource: resource.Grader, Name: filename}, nil
+	default:
+		return nil, fmt.Errorf("unexpected file %s", name)
+	}
+}
+
+func (p *Package) validate() error {
+	if p.Problem == nil {
+		return fmt.Errorf("no %s in package", problemFile)
+	}
+
+	inputs := make(map[uint64]struct{})
+	answers := make(map[uint64]struct{})
+	filesCount := make(map[resource.Type]int)
+	generators := p.Problem.TestScript.Generators()
+	for _, file := range p.Files {
+		switch file.Resource {
+		case resource.TestInput:
+			inputs[file.TestID] = struct{}{}
+		case resource.TestAnswer:
+			answers[file.TestID] = struct{}{}
+		case resource.Solution:
+			if p.Problem.FindSolution(file.Name) == nil {
+				return fmt.Errorf("solution %s is not listed in %s", file.Name, problemFile)
+			}
+		case resource.Statement:
+			if p.Problem.FindStatement(file.Name) == nil {
+				return fmt.Errorf("statement %s is not listed in %s", file.Name, problemFile)
+			}
+		case resource.Generator:
+			if !slices.Contains(generators, file.Name) {
+				return fmt.Errorf("generator %s is not used in %s test script", file.Name, problemFile)
+			}
+		case resource.Grader:
+			if p.Problem.FindGrader(file.Name) == nil {
+				return fmt.Errorf("grader %s is not listed in %s", file.Name, problemFile)
+			}
+		}
+		filesCount[file.Resource]++
+	}
+
+	for testID := uint64(1); testID <= p.Problem.TestsNumber; testID++ {
+		if _, ok := inputs[testID]; !ok {
+			return fmt.Errorf("no input of test %d", testID)
+		}
+		if _, ok := answers[testID]; !ok {
+			return fmt.Errorf("no answer of test %d", testID)
+		}
+	}
+	if len(inputs) != int(p.Problem.TestsNumber) || len(answers) != int(p.Problem.TestsNumber) {
+		return fmt.Errorf("package has more tests than %d", p.Problem.TestsNumber)
+	}
+
+	if p.Problem.UsesCheckerBinary() && filesCount[resource.Checker] != 1 {
+		return fmt.Errorf("package should contain exactly one checker")
+	}
+	if p.Problem.Interactive && filesCount[resource.Interactor] != 1 {
+		return fmt.Errorf("package should contain exactly one interactor")
+	}
+	if filesCount[resource.Solution] != len(p.Problem.Solutions) {
+		return fmt.Errorf("not all solutions listed in %s are present in package", problemFile)
+	}
+	if filesCount[resource.Statement] != len(p.Problem.Statements) {
+		return fmt.Errorf("not all statements listed in %s are present in package", problemFile)
+	}
+	if filesCount[resource.Generator] != len(generators) {
+		return fmt.Errorf("not all generators used in %s test script are present in package", problemFile)
+	}
+	if p.Problem.HasValidator && filesCount[resource.Validator] != 1 {
+		return fmt.Errorf("package should contain exactly one validator")
+	}
+	if filesCount[resource.Grader] != len(p.Problem.Graders) {
+		return fmt.Errorf("not all graders listed in %s are present in package", problemFile)
+	}
+	return nil
+}
+
+// Write writes package archive. Problem ID, revision and timestamps are not saved,
+// as they are assigned by installation that imports the package
+func (p *Package) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	problem := *p.Problem
+	problem.ID = 0
+	problem.Revision = 0
+	problem.CreatedAt = time.Time{}
+	problem.UpdatedAt = time.Time{}
+	problemData, err := yaml.Marshal(&problem)
+	if err != nil {
+		return fmt.Errorf("can not marshal problem, error: %v", err)
+	}
+	if err = writeZipFile(archive, problemFile, bytes.NewReader(problemData)); err != nil {
+		return err
+	}
+
+	for _, file := range p.Files {
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeZipFile(archive, file.path(), reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func writeZipFile(archive *zip.Writer, name string, reader io.Reader) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("can not create file %s in package, error: %v", name, err)
+	}
+	if _, err = io.Copy(writer, reader); err != nil {
+		return fmt.Errorf("can not write file %s to package, error: %v", name, err)
+	}
+	return nil
+}
+
+func (f *File) path() string {
+	switch f.Resource {
+	case resource.TestInput:
+		return path.Join(testsFolder, fmt.Sprintf("%02d", f.TestID))
+	case resource.TestAnswer:
+		return path.Join(testsFolder, fmt.Sprintf("%02d%s", f.TestID, answerSuffix))
+	case resource.Checker:
+		return path.Join(checkerFolder, f.Name)
+	case resource.Interactor:
+		return path.Join(interactorFolder, f.Name)
+	case resource.Solution:
+		return path.Join(solutionsFolder, f.Name)
+	case resource.Statement:
+		return path.Join(statementsFolder, f.Name)
+	case resource.Generator:
+		return path.Join(generatorsFolder, f.Name)
+	case resource.Validator:
+		return path.Join(validatorFolder, f.Name)
+	case resource.Grader:
+		return path.Join(gradersFolder, f.Name)
+	default:
+		panic(fmt.Sprintf("resource %v can not be stored in problem package", f.Resource))
+	}
+}
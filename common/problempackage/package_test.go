@@ -0,0 +1,194 @@
+package problempackage
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+	"testing"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/db/models"
+	"testing_system/lib/customfields"
+)
+
+func testPackage() *Package {
+	return &Package{
+		Problem: &models.Problem{
+			ID:          5,
+			Name:        "a+b",
+			Revision:    3,
+			ProblemType: models.ProblemTypeICPC,
+			TimeLimit:   customfields.Time(1000000000),
+			MemoryLimit: customfields.Memory(256 * 1024 * 1024),
+			TestsNumber: 2,
+			Solutions: models.ProblemSolutions{
+				{Name: "main.cpp", Language: "g++", Tag: solutiontag.Main},
+			},
+			Statements: models.ProblemStatements{
+				{Name: "english-problem.pdf", Language: "english", Type: "application/pdf"},
+			},
+		},
+		Files: []*File{
+			{Resource: resource.TestInput, TestID: 1, Data: []byte("1 2")},
+			{Resource: resource.TestAnswer, TestID: 1, Data: []byte("3")},
+			{Resource: resource.TestInput, TestID: 2, Data: []byte("2 2")},
+			{Resource: resource.TestAnswer, TestID: 2, Data: []byte("4")},
+			{Resource: resource.Checker, Name: "check", Data: []byte("checker")},
+			{Resource: resource.Solution, Name: "main.cpp", Data: []byte("int main() {}")},
+			{Resource: resource.Statement, Name: "english-problem.pdf", Data: []byte("%PDF")},
+		},
+	}
+}
+
+func writeAndRead(pkg *Package) (*Package, error) {
+	buf := &bytes.Buffer{}
+	if err := pkg.Write(buf); err != nil {
+		return nil, err
+	}
+	read, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range read.Files {
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		file.Data, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		file.zipFile = nil
+	}
+	return read, nil
+}
+
+func writeZip(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	for name, data := range files {
+		require.NoError(t, writeZipFile(archive, name, strings.NewReader(data)))
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestPackageRoundTrip(t *testing.T) {
+	pkg := testPackage()
+	read, err := writeAndRead(pkg)
+	require.NoError(t, err)
+
+	require.Zero(t, read.Problem.ID)
+	require.Zero(t, read.Problem.Revision)
+	require.Equal(t, pkg.Problem.Name, read.Problem.Name)
+	require.Equal(t, pkg.Problem.TimeLimit, read.Problem.TimeLimit)
+	require.Equal(t, pkg.Problem.MemoryLimit, read.Problem.MemoryLimit)
+	require.Equal(t, pkg.Problem.Solutions, read.Problem.Solutions)
+	require.Equal(t, pkg.Problem.Statements, read.Problem.Statements)
+	require.Equal(t, pkg.Files, read.Files)
+}
+
+func TestPackageValidation(t *testing.T) {
+	t.Run("Missing answer", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Files = append(pkg.Files[:3], pkg.Files[4:]...)
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "no answer of test 2")
+	})
+
+	t.Run("Extra test", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Files = append(pkg.Files, &File{Resource: resource.TestInput, TestID: 3})
+		_, err := writeAndRead(pkg)
+		require.Error(t, err)
+	})
+
+	t.Run("Duplicate test", func(t *testing.T) {
+		problem, err := yaml.Marshal(&models.Problem{Name: "a+b", TestsNumber: 1, CheckerType: models.CheckerTypeStandard})
+		require.NoError(t, err)
+		data := writeZip(t, map[string]string{
+			problemFile: string(problem),
+			"tests/1":   "1 2",
+			"tests/01":  "2 2",
+			"tests/1.a": "3",
+		})
+		_, err = Read(bytes.NewReader(data), int64(len(data)))
+		require.ErrorContains(t, err, "same package file")
+	})
+
+	t.Run("Missing checker", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Files = append(pkg.Files[:4], pkg.Files[5:]...)
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "checker")
+
+		pkg.Problem.CheckerType = models.CheckerTypeStandard
+		pkg.Problem.StandardChecker = "wcmp"
+		_, err = writeAndRead(pkg)
+		require.NoError(t, err)
+	})
+
+	t.Run("Missing interactor", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Problem.Interactive = true
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "interactor")
+	})
+
+	t.Run("Unlisted solution", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Solution, Name: "wa.cpp"})
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "wa.cpp")
+	})
+
+	t.Run("Missing statement", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Files = pkg.Files[:len(pkg.Files)-1]
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "statements")
+	})
+
+	t.Run("Generators and validator", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Problem.TestScript = models.TestScript{{Test: 2, Generator: "gen", Args: []string{"2"}}}
+		pkg.Problem.HasValidator = true
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "generators")
+
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Generator, Name: "gen", Data: []byte("gen")})
+		_, err = writeAndRead(pkg)
+		require.ErrorContains(t, err, "validator")
+
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Validator, Name: "val", Data: []byte("val")})
+		read, err := writeAndRead(pkg)
+		require.NoError(t, err)
+		require.Equal(t, pkg.Problem.TestScript, read.Problem.TestScript)
+		require.Equal(t, pkg.Files, read.Files)
+
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Generator, Name: "unused"})
+		_, err = writeAndRead(pkg)
+		require.ErrorContains(t, err, "unused")
+	})
+
+	t.Run("Graders", func(t *testing.T) {
+		pkg := testPackage()
+		pkg.Problem.Graders = models.ProblemGraders{{Name: "grader.cpp", Language: "g++"}}
+		_, err := writeAndRead(pkg)
+		require.ErrorContains(t, err, "graders")
+
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Grader, Name: "grader.cpp", Data: []byte("int main() {}")})
+		read, err := writeAndRead(pkg)
+		require.NoError(t, err)
+		require.Equal(t, pkg.Problem.Graders, read.Problem.Graders)
+		require.Equal(t, pkg.Files, read.Files)
+
+		pkg.Files = append(pkg.Files, &File{Resource: resource.Grader, Name: "grader.h"})
+		_, err = writeAndRead(pkg)
+		require.ErrorContains(t, err, "grader.h")
+	})
+}
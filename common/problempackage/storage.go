@@ -0,0 +1,138 @@
+package problempackage
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/resource"
+	"testing_system/common/db/models"
+	"time"
+)
+
+// Export loads all resources of current problem revision from storage
+func Export(ctx context.Context, storage *storageconn.Connector, problem *models.Problem) (*Package, error) {
+	pkg := &Package{Problem: problem}
+
+	download := func(file *File, storageFilename string) error {
+		resp := storage.Download(&storageconn.Request{
+			Resource:        file.Resource,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: problem.Revision,
+			TestID:          file.TestID,
+			StorageFilename: storageFilename,
+			DownloadBytes:   true,
+			Ctx:             ctx,
+		})
+		if resp.Error != nil {
+			return fmt.Errorf("can not download problem %d %v, error: %v", problem.ID, file.Resource, resp.Error)
+		}
+		file.Data = resp.RawData
+		if file.Name == "" && file.TestID == 0 {
+			file.Name = resp.Filename
+		}
+		pkg.Files = append(pkg.Files, file)
+		return nil
+	}
+
+	for testID := uint64(1); testID <= problem.TestsNumber; testID++ {
+		if err := download(&File{Resource: resource.TestInput, TestID: testID}, ""); err != nil {
+			return nil, err
+		}
+		if err := download(&File{Resource: resource.TestAnswer, TestID: testID}, ""); err != nil {
+			return nil, err
+		}
+	}
+	if problem.UsesCheckerBinary() {
+		if err := download(&File{Resource: resource.Checker}, ""); err != nil {
+			return nil, err
+		}
+	}
+	if problem.Interactive {
+		if err := download(&File{Resource: resource.Interactor}, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, solution := range problem.Solutions {
+		if err := download(&File{Resource: resource.Solution, Name: solution.Name}, solution.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, statement := range problem.Statements {
+		if err := download(&File{Resource: resource.Statement, Name: statement.Name}, statement.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, generator := range problem.TestScript.Generators() {
+		if err := download(&File{Resource: resource.Generator, Name: generator}, generator); err != nil {
+			return nil, err
+		}
+	}
+	if problem.HasValidator {
+		if err := download(&File{Resource: resource.Validator}, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, grader := range problem.Graders {
+		if err := download(&File{Resource: resource.Grader, Name: grader.Name}, grader.Name); err != nil {
+			return nil, err
+		}
+	}
+	return pkg, nil
+}
+
+// Import creates new problem from package. Problem is created with revision 1 and all package files
+// are uploaded to this revision. If upload fails, problem is deleted
+func Import(ctx context.Context, db *gorm.DB, storage *storageconn.Connector, pkg *Package) (*models.Problem, error) {
+	if err := pkg.validate(); err != nil {
+		return nil, err
+	}
+	problem := *pkg.Problem
+	problem.ID = 0
+	problem.Revision = 1
+	problem.CreatedAt = time.Time{}
+	problem.UpdatedAt = time.Time{}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&problem).Error; err != nil {
+			return err
+		}
+		return tx.Create(models.NewProblemRevision(&problem)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are uploaded outside of transaction, as upload of large package may take long
+	for _, file := range pkg.Files {
+		if err = uploadFile(ctx, storage, &problem, file); err != nil {
+			if deleteErr := db.WithContext(ctx).Delete(&problem).Error; deleteErr != nil {
+				return nil, fmt.Errorf("%v, also can not delete problem %d, error: %v", err, problem.ID, deleteErr)
+			}
+			return nil, err
+		}
+	}
+	return &problem, nil
+}
+
+func uploadFile(ctx context.Context, storage *storageconn.Connector, problem *models.Problem, file *File) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	resp := storage.Upload(&storageconn.Request{
+		Resource:        file.Resource,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		TestID:          file.TestID,
+		File:            reader,
+		StorageFilename: file.Name,
+		Ctx:             ctx,
+	})
+	if resp.Error != nil {
+		return fmt.Errorf("can not upload %s, error: %v", file.path(), resp.Error)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"testing_system/lib/connector"
 	"testing_system/lib/logger"
 
 	swaggo "github.com/swaggo/files"
@@ -40,20 +41,38 @@ func (ts *TestingSystem) InitServer() {
 	ts.Router.GET("/swagger/*any", ginswagger.WrapHandler(swaggo.Handler))
 }
 
+// AuthMiddleware checks that requests to component handlers are signed by one of component secrets.
+// If component has no own auth config, secrets of testing system Auth are used
+func (ts *TestingSystem) AuthMiddleware(config *connector.HandlerAuthConfig) gin.HandlerFunc {
+	if config == nil && ts.Config.Auth != nil {
+		config = &ts.Config.Auth.HandlerAuthConfig
+	}
+	return connector.AuthMiddleware(config)
+}
+
 func (ts *TestingSystem) runServer() {
 	addr := ":" + strconv.Itoa(ts.Config.Port)
 	if ts.Config.Host != nil {
 		addr = *ts.Config.Host + addr
 	}
 	logger.Info("Starting server at " + addr)
+	tlsConfig, err := connector.NewServerTLSConfig(ts.Config.Auth)
+	if err != nil {
+		logger.Panic("Can not set up server tls, error: %v", err)
+	}
 	server := http.Server{
-		Addr:    addr,
-		Handler: ts.Router,
+		Addr:      addr,
+		Handler:   ts.Router,
+		TLSConfig: tlsConfig,
 	}
 	go func() {
 		<-ts.StopCtx.Done()
 		logger.Info("Shutting down server")
 		server.Shutdown(context.Background())
 	}()
-	server.ListenAndServe()
+	if tlsConfig != nil {
+		server.ListenAndServeTLS("", "")
+	} else {
+		server.ListenAndServe()
+	}
 }
@@ -4,18 +4,28 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"github.com/xorcare/pointer"
 	"golang.org/x/net/html/charset"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing_system/common/connectors/storageconn"
 	"testing_system/common/constants/resource"
+	"testing_system/common/constants/standardchecker"
 	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/invoker/checkers"
 	"testing_system/invoker/sandbox"
 	"testing_system/lib/logger"
 )
 
 func (s *JobPipelineState) fullCheckPipeline() error {
+	if !s.job.problem.UsesCheckerBinary() {
+		return s.builtinCheckPipeline()
+	}
+
 	err := s.loadCheckerBinaryFile()
 	if err != nil {
 		return err
@@ -49,8 +59,24 @@ func (s *JobPipelineState) generateCheckerRunConfig() error {
 	}
 
 	s.test.checkConfig.Command = checkerBinaryFile
-	s.test.checkConfig.Args = []string{
-		testInputFile, testOutputFile, testAnswerFile, checkResultFile, checkResultFileArg,
+	switch s.job.problem.CheckerType {
+	case models.CheckerTypeExitCode, models.CheckerTypeEjudge:
+		s.test.checkConfig.Args = []string{testInputFile, testOutputFile, testAnswerFile}
+		s.test.checkConfig.Stdout = &sandbox.IORedirect{FileName: checkOutputFile}
+		s.test.checkConfig.StderrToStdout = true
+	case models.CheckerTypeKattis:
+		s.test.checkConfig.Args = []string{testInputFile, testAnswerFile, kattisFeedbackDir}
+		s.test.checkConfig.Stdin = &sandbox.IORedirect{FileName: testOutputFile}
+		s.test.checkConfig.Stdout = &sandbox.IORedirect{FileName: checkOutputFile}
+		s.test.checkConfig.StderrToStdout = true
+	case models.CheckerTypeCMS:
+		s.test.checkConfig.Args = []string{testInputFile, testAnswerFile, testOutputFile}
+		s.test.checkConfig.Stdout = &sandbox.IORedirect{FileName: checkScoreFile}
+		s.test.checkConfig.Stderr = &sandbox.IORedirect{FileName: checkOutputFile}
+	default:
+		s.test.checkConfig.Args = []string{
+			testInputFile, testOutputFile, testAnswerFile, checkResultFile, checkResultFileArg,
+		}
 	}
 	s.test.checkConfig.Ctx = s.job.stopCtx
 	logger.Trace("Generated checker run config for %s", s.loggerData)
@@ -97,11 +123,18 @@ func (s *JobPipelineState) parseCheckerResult() error {
 		logger.Trace("Check result is not parsed because job is stopped", s.loggerData)
 		return nil
 	}
+	switch s.job.problem.CheckerType {
+	case models.CheckerTypeExitCode, models.CheckerTypeEjudge, models.CheckerTypeKattis:
+		return s.parseExitCodeCheckerResult()
+	case models.CheckerTypeCMS:
+		return s.parseCMSCheckerResult()
+	}
+
 	_, err := os.Stat(filepath.Join(s.sandbox.Dir(), checkResultFile))
 	if err == nil {
 		return s.parseTestlibCheckerResult()
 	} else if errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("no checker result file found, non testlib checkers are not supported")
+		return fmt.Errorf("no checker result file found, checker exited with exit code %d", s.test.checkResult.Statistics.ExitCode)
 	} else {
 		return fmt.Errorf("can not stat checker result file")
 	}
@@ -117,10 +150,7 @@ func (s *JobPipelineState) parseTestlibCheckerResult() error {
 	}
 	defer checkResultReader.Close()
 
-	var checkerResult CheckerResultXML
-	xmlReader := xml.NewDecoder(checkResultReader)
-	xmlReader.CharsetReader = charset.NewReaderLabel
-	err = xmlReader.Decode(&checkerResult)
+	checkerResult, err := decodeTestlibResult(checkResultReader)
 	if err != nil {
 		return fmt.Errorf(
 			"can not parse checker result xml file in appes mode: %s",
@@ -128,6 +158,14 @@ func (s *JobPipelineState) parseTestlibCheckerResult() error {
 		)
 	}
 
+	err = s.applyCheckerResult(checkerResult)
+	if err != nil {
+		return fmt.Errorf("%v, checker exited with exit code %d", err, s.test.checkResult.Statistics.ExitCode)
+	}
+	return nil
+}
+
+func (s *JobPipelineState) applyCheckerResult(checkerResult *CheckerResultXML) error {
 	s.test.checkerOutputReader = s.limitedReader(strings.NewReader(checkerResult.Value))
 	switch checkerResult.Outcome {
 	case "accepted":
@@ -139,41 +177,194 @@ func (s *JobPipelineState) parseTestlibCheckerResult() error {
 		s.test.runResult.Verdict = verdict.CF
 	case "points", "relative-scoring":
 		if checkerResult.Points == nil {
-			return fmt.Errorf(
-				"checker exited with exit code %d and verdict %s, but no points specified",
-				s.test.checkResult.Statistics.ExitCode,
-				checkerResult.Outcome,
-			)
+			return fmt.Errorf("checker verdict is %s, but no points specified", checkerResult.Outcome)
 		} else {
 			s.test.runResult.Verdict = verdict.PT
 			s.test.runResult.Points = checkerResult.Points
 		}
 	default:
-		return fmt.Errorf(
-			"unknown checker verdict %s, checker exited with exit code %d",
-			checkerResult.Outcome,
-			s.test.checkResult.Statistics.ExitCode,
-		)
+		return fmt.Errorf("unknown checker verdict %s", checkerResult.Outcome)
+	}
+
+	logger.Trace("Parsed checker result for %s, checker verdict is %s", s.loggerData, s.test.runResult.Verdict)
+	return nil
+}
+
+func (s *JobPipelineState) parseExitCodeCheckerResult() error {
+	outputFile := checkOutputFile
+	if s.job.problem.CheckerType == models.CheckerTypeKattis {
+		// Kattis validators usually write their messages to feedback dir instead of stdout
+		if _, err := os.Stat(filepath.Join(s.sandbox.Dir(), kattisJudgeMessageFile)); err == nil {
+			outputFile = kattisJudgeMessageFile
+		}
+	}
+	output, err := s.openSandboxFile(outputFile, true)
+	if err != nil {
+		return fmt.Errorf("can not open checker output file, error: %v", err)
+	}
+	s.test.checkerOutputReader = output
+
+	exitCode := s.test.checkResult.Statistics.ExitCode
+	switch s.job.problem.CheckerType {
+	case models.CheckerTypeKattis:
+		switch exitCode {
+		case 42:
+			s.test.runResult.Verdict = verdict.OK
+		case 43:
+			s.test.runResult.Verdict = verdict.WA
+		default:
+			s.test.runResult.Verdict = verdict.CF
+		}
+	default:
+		switch {
+		case exitCode == 0:
+			s.test.runResult.Verdict = verdict.OK
+		case exitCode == 1 && s.job.problem.CheckerType == models.CheckerTypeExitCode:
+			s.test.runResult.Verdict = verdict.WA
+		case (exitCode == 4 || exitCode == 5) && s.job.problem.CheckerType == models.CheckerTypeEjudge:
+			// Ejudge presentation error and wrong answer
+			s.test.runResult.Verdict = verdict.WA
+		default:
+			s.test.runResult.Verdict = verdict.CF
+		}
 	}
 
 	logger.Trace(
-		"Parsed testlib checker result for %s, checker verdict is %s",
-		s.loggerData, s.test.checkResult.Verdict,
+		"Parsed checker exit code %d for %s, checker verdict is %s",
+		exitCode, s.loggerData, s.test.runResult.Verdict,
 	)
 	return nil
 }
 
-func (s *JobPipelineState) uploadTestRunResources() error {
-	err := s.uploadOutput(testOutputFile, resource.TestOutput)
+func (s *JobPipelineState) parseCMSCheckerResult() error {
+	output, err := s.openSandboxFile(checkOutputFile, true)
+	if err != nil {
+		return fmt.Errorf("can not open checker output file, error: %v", err)
+	}
+	s.test.checkerOutputReader = output
+
+	if s.test.checkResult.Statistics.ExitCode != 0 {
+		s.test.runResult.Verdict = verdict.CF
+		logger.Trace("CMS checker exited with exit code %d for %s", s.test.checkResult.Statistics.ExitCode, s.loggerData)
+		return nil
+	}
+	scoreData, err := os.ReadFile(filepath.Join(s.sandbox.Dir(), checkScoreFile))
+	if err != nil {
+		return fmt.Errorf("can not read checker score file, error: %v", err)
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(string(scoreData)), 64)
+	if err != nil || score < 0 || score > 1 {
+		s.test.runResult.Verdict = verdict.CF
+		logger.Trace("CMS checker wrote invalid score %q for %s", scoreData, s.loggerData)
+		return nil
+	}
+
+	switch {
+	case score == 1:
+		s.test.runResult.Verdict = verdict.OK
+	case score == 0:
+		s.test.runResult.Verdict = verdict.WA
+	default:
+		group := s.job.problem.FindTestGroup(s.job.Test)
+		if !s.job.problem.ProblemType.HasTestGroups() || group == nil {
+			s.test.runResult.Verdict = verdict.WA
+			break
+		}
+		var testCost *float64
+		if group.ScoringType == models.TestGroupScoringTypeEachTest {
+			testCost = group.TestScore
+		} else {
+			testCost = group.GroupScore
+		}
+		if testCost == nil {
+			return fmt.Errorf("test group %s has no score", group.Name)
+		}
+		s.test.runResult.Verdict = verdict.PT
+		s.test.runResult.Points = pointer.Float64(score * *testCost)
+	}
+	logger.Trace("Parsed CMS checker score %v for %s, checker verdict is %s", score, s.loggerData, s.test.runResult.Verdict)
+	return nil
+}
+
+func (s *JobPipelineState) builtinCheckPipeline() error {
+	err := s.loadTestAnswerFile()
 	if err != nil {
 		return err
 	}
 
-	err = s.uploadOutput(testErrorFile, resource.TestStderr)
+	s.executeWaitGroup.Add(1)
+	var checkerResult *CheckerResultXML
+	var checkerErr error
+	err = s.runProcess(func() {
+		checkerResult, checkerErr = s.runBuiltinChecker()
+		s.executeWaitGroup.Done()
+	})
+	if err != nil {
+		return fmt.Errorf("can not execute built-in checker, error: %v", err)
+	}
+	s.executeWaitGroup.Wait()
+	if checkerErr != nil {
+		return checkerErr
+	}
+	logger.Trace("Finished built-in checker run for %s", s.loggerData)
+
+	return s.applyCheckerResult(checkerResult)
+}
+
+func (s *JobPipelineState) runBuiltinChecker() (*CheckerResultXML, error) {
+	checker, err := builtinChecker(s.job.problem)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := os.Open(filepath.Join(s.sandbox.Dir(), testOutputFile))
+	if err != nil {
+		return nil, fmt.Errorf("can not open solution output file, error: %v", err)
+	}
+	defer output.Close()
+	answer, err := os.Open(filepath.Join(s.sandbox.Dir(), testAnswerFile))
+	if err != nil {
+		return nil, fmt.Errorf("can not open test answer file, error: %v", err)
+	}
+	defer answer.Close()
+
+	result := checker(output, answer)
+	return &CheckerResultXML{
+		Outcome: result.Outcome,
+		Value:   result.Message,
+	}, nil
+}
+
+func builtinChecker(problem *models.Problem) (checkers.Checker, error) {
+	switch problem.CheckerType {
+	case models.CheckerTypeStandard:
+		if problem.StandardChecker == standardchecker.Floats && problem.CheckerEpsilon != nil {
+			return checkers.FloatsComparator(*problem.CheckerEpsilon), nil
+		}
+		checker, ok := checkers.Get(problem.StandardChecker)
+		if !ok {
+			return nil, fmt.Errorf("unknown standard checker %s", problem.StandardChecker)
+		}
+		return checker, nil
+	default:
+		return nil, fmt.Errorf("unknown built-in checker type %d", problem.CheckerType)
+	}
+}
+
+func (s *JobPipelineState) uploadTestRunResources() error {
+	err := s.uploadOutput(testOutputFile, resource.TestOutput)
 	if err != nil {
 		return err
 	}
 
+	if s.job.problem.ProblemType != models.ProblemTypeOutputOnly {
+		// Output only submissions are not run, so they have no stderr
+		err = s.uploadOutput(testErrorFile, resource.TestStderr)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.uploadCheckerOutput()
 	if err != nil {
 		return err
@@ -196,6 +387,17 @@ func (s *JobPipelineState) uploadCheckerOutput() error {
 	return nil
 }
 
+func decodeTestlibResult(reader io.Reader) (*CheckerResultXML, error) {
+	var result CheckerResultXML
+	xmlReader := xml.NewDecoder(reader)
+	xmlReader.CharsetReader = charset.NewReaderLabel
+	err := xmlReader.Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 type CheckerResultXML struct {
 	Outcome string   `xml:"outcome,attr"`
 	Points  *float64 `xml:"points,attr,omitempty"`
@@ -0,0 +1,89 @@
+package checkers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing_system/common/constants/standardchecker"
+)
+
+// Outcomes are the same as testlib checker outcomes in result xml file
+const (
+	OutcomeAccepted      = "accepted"
+	OutcomeWrongAnswer   = "wrong-answer"
+	OutcomeUnexpectedEOF = "unexpected-eof"
+	OutcomeFail          = "fail"
+)
+
+const (
+	// DefaultEpsilon is used by rcmp checker if problem does not specify epsilon, it is the same as in testlib
+	DefaultEpsilon      = 1.5e-6
+	maxCheckerTokenSize = 16 * 1024 * 1024
+)
+
+type Result struct {
+	Outcome string
+	Message string
+}
+
+// Checker compares solution output with test answer
+type Checker func(output io.Reader, answer io.Reader) *Result
+
+var standardCheckers = map[string]Checker{
+	standardchecker.Tokens:   CompareTokens,
+	standardchecker.Lines:    CompareLines,
+	standardchecker.Integers: CompareIntegers,
+	standardchecker.Floats:   FloatsComparator(DefaultEpsilon),
+	standardchecker.Floats4:  FloatsComparator(1e-4),
+	standardchecker.Floats6:  FloatsComparator(1e-6),
+	standardchecker.Floats9:  FloatsComparator(1e-9),
+	standardchecker.YesNo:    CompareYesNo,
+}
+
+// Get returns standard checker by its testlib name
+func Get(name string) (Checker, bool) {
+	checker, ok := standardCheckers[name]
+	return checker, ok
+}
+
+func result(outcome string, format string, args ...interface{}) *Result {
+	return &Result{
+		Outcome: outcome,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func newScanner(reader io.Reader, split bufio.SplitFunc) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxCheckerTokenSize)
+	scanner.Split(split)
+	return scanner
+}
+
+func englishEnding(n int) string {
+	if n/10%10 == 1 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+func compress(s string) string {
+	if len(s) <= 64 {
+		return s
+	}
+	return s[:30] + "..." + s[len(s)-31:]
+}
+
+func joinTokens(tokens []string) string {
+	return compress(strings.Join(tokens, " "))
+}
@@ -0,0 +1,59 @@
+package checkers
+
+import (
+	"github.com/stretchr/testify/require"
+	"slices"
+	"strings"
+	"testing"
+	"testing_system/common/constants/standardchecker"
+)
+
+func TestStandardCheckers(t *testing.T) {
+	check := func(expectedOutcome string, checkerName string, output string, answer string) {
+		checker, ok := Get(checkerName)
+		require.True(t, ok)
+		res := checker(strings.NewReader(output), strings.NewReader(answer))
+		require.Equal(t, expectedOutcome, res.Outcome, res.Message)
+	}
+
+	check(OutcomeAccepted, "wcmp", "1  2\n3\n\n", "1 2 3")
+	check(OutcomeWrongAnswer, "wcmp", "1 2 4", "1 2 3")
+	check(OutcomeWrongAnswer, "wcmp", "1 2 3 4", "1 2 3")
+	check(OutcomeUnexpectedEOF, "wcmp", "1 2", "1 2 3")
+
+	check(OutcomeAccepted, "lcmp", "1  2 \n3\n\n\n", "1 2\n3")
+	check(OutcomeWrongAnswer, "lcmp", "1\n2 3", "1 2\n3")
+	check(OutcomeUnexpectedEOF, "lcmp", "1 2", "1 2\n3")
+	check(OutcomeWrongAnswer, "lcmp", "1 2\n3\n4", "1 2\n3")
+
+	check(OutcomeAccepted, "ncmp", "-1 2\n3", "-1 2 3")
+	check(OutcomeWrongAnswer, "ncmp", "1 2 3.0", "1 2 3")
+	check(OutcomeFail, "ncmp", "1", "a")
+
+	check(OutcomeAccepted, "rcmp6", "1.0000001 2e9", "1 2000000001")
+	check(OutcomeWrongAnswer, "rcmp6", "1.001", "1")
+	check(OutcomeAccepted, "rcmp4", "1.00001", "1")
+	check(OutcomeWrongAnswer, "rcmp9", "1.00001", "1")
+	check(OutcomeAccepted, "rcmp", "1.000001", "1")
+	check(OutcomeWrongAnswer, "rcmp", "1.00001", "1")
+	check(OutcomeWrongAnswer, "rcmp6", "abc", "1")
+	check(OutcomeFail, "rcmp6", "1", "abc")
+
+	check(OutcomeAccepted, "yesno", "yEs\n", "YES")
+	check(OutcomeWrongAnswer, "yesno", "NO", "YES")
+	check(OutcomeWrongAnswer, "yesno", "maybe", "YES")
+	check(OutcomeUnexpectedEOF, "yesno", "", "NO")
+	check(OutcomeFail, "yesno", "YES", "1")
+
+	_, ok := Get("unknown")
+	require.False(t, ok)
+}
+
+func TestStandardCheckerNames(t *testing.T) {
+	require.Len(t, standardCheckers, len(standardchecker.All))
+	require.True(t, slices.IsSorted(standardchecker.All))
+	for _, name := range standardchecker.All {
+		_, ok := Get(name)
+		require.True(t, ok, name)
+	}
+}
@@ -0,0 +1,204 @@
+package checkers
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CompareTokens works the same way as testlib wcmp checker
+func CompareTokens(output io.Reader, answer io.Reader) *Result {
+	return compareTokensWith(output, answer, "words", func(n int, expected, found string) *Result {
+		if expected != found {
+			return result(OutcomeWrongAnswer,
+				"%d%s words differ - expected: '%s', found: '%s'",
+				n, englishEnding(n), compress(expected), compress(found))
+		}
+		return nil
+	})
+}
+
+// CompareIntegers works the same way as testlib ncmp checker
+func CompareIntegers(output io.Reader, answer io.Reader) *Result {
+	return compareTokensWith(output, answer, "numbers", func(n int, expected, found string) *Result {
+		expectedValue, err := strconv.ParseInt(expected, 10, 64)
+		if err != nil {
+			return result(OutcomeFail, "expected '%s' is not an integer", compress(expected))
+		}
+		foundValue, err := strconv.ParseInt(found, 10, 64)
+		if err != nil {
+			return result(OutcomeWrongAnswer, "expected integer, found: '%s'", compress(found))
+		}
+		if expectedValue != foundValue {
+			return result(OutcomeWrongAnswer,
+				"%d%s numbers differ - expected: '%d', found: '%d'", n, englishEnding(n), expectedValue, foundValue)
+		}
+		return nil
+	})
+}
+
+// FloatsComparator compares tokens as float numbers with absolute or relative error eps, as testlib rcmp checkers do
+func FloatsComparator(eps float64) Checker {
+	return func(output io.Reader, answer io.Reader) *Result {
+		return compareTokensWith(output, answer, "numbers", func(n int, expected, found string) *Result {
+			expectedValue, err := strconv.ParseFloat(expected, 64)
+			if err != nil {
+				return result(OutcomeFail, "expected '%s' is not a float number", compress(expected))
+			}
+			foundValue, err := strconv.ParseFloat(found, 64)
+			if err != nil {
+				return result(OutcomeWrongAnswer, "expected float number, found: '%s'", compress(found))
+			}
+			if !floatsEqual(expectedValue, foundValue, eps) {
+				return result(OutcomeWrongAnswer,
+					"%d%s numbers differ - expected: '%s', found: '%s', error = '%g'",
+					n, englishEnding(n), expected, found, floatsError(expectedValue, foundValue))
+			}
+			return nil
+		})
+	}
+}
+
+// CompareYesNo works the same way as testlib yesno checker, the case of the answer is ignored
+func CompareYesNo(output io.Reader, answer io.Reader) *Result {
+	answerTokens, err := readTokens(answer, 2)
+	if err != nil {
+		return result(OutcomeFail, "can not read answer, error: %v", err)
+	}
+	if len(answerTokens) != 1 || !isYesNo(answerTokens[0]) {
+		return result(OutcomeFail, "YES or NO expected in answer, but '%s' found", joinTokens(answerTokens))
+	}
+	outputTokens, err := readTokens(output, 2)
+	if err != nil {
+		return result(OutcomeWrongAnswer, "can not read output, error: %v", err)
+	}
+	if len(outputTokens) == 0 {
+		return result(OutcomeUnexpectedEOF, "Unexpected EOF in the participants output")
+	}
+	if len(outputTokens) != 1 || !isYesNo(outputTokens[0]) {
+		return result(OutcomeWrongAnswer, "YES or NO expected, but '%s' found", joinTokens(outputTokens))
+	}
+
+	expected := strings.ToUpper(answerTokens[0])
+	found := strings.ToUpper(outputTokens[0])
+	if expected != found {
+		return result(OutcomeWrongAnswer, "expected %s, found %s", expected, found)
+	}
+	return result(OutcomeAccepted, "answer is %s", expected)
+}
+
+// CompareLines works the same way as testlib lcmp checker: lines are compared as sequences of tokens
+func CompareLines(output io.Reader, answer io.Reader) *Result {
+	outputLines, err := readLines(output)
+	if err != nil {
+		return result(OutcomeWrongAnswer, "can not read output, error: %v", err)
+	}
+	answerLines, err := readLines(answer)
+	if err != nil {
+		return result(OutcomeFail, "can not read answer, error: %v", err)
+	}
+
+	for i := 0; i < len(answerLines) || i < len(outputLines); i++ {
+		n := i + 1
+		if i >= len(outputLines) {
+			return result(OutcomeUnexpectedEOF,
+				"Unexpected EOF in the participants output, expected %d lines", len(answerLines))
+		}
+		if i >= len(answerLines) {
+			return result(OutcomeWrongAnswer, "Participant output contains extra lines")
+		}
+		if !slices.Equal(answerLines[i], outputLines[i]) {
+			return result(OutcomeWrongAnswer,
+				"%d%s lines differ - expected: '%s', found: '%s'",
+				n, englishEnding(n), joinTokens(answerLines[i]), joinTokens(outputLines[i]))
+		}
+	}
+	return result(OutcomeAccepted, "%d lines", len(answerLines))
+}
+
+func compareTokensWith(
+	output io.Reader,
+	answer io.Reader,
+	name string,
+	compare func(n int, expected, found string) *Result,
+) *Result {
+	outputScanner := newScanner(output, bufio.ScanWords)
+	answerScanner := newScanner(answer, bufio.ScanWords)
+
+	n := 0
+	for {
+		hasAnswer := answerScanner.Scan()
+		hasOutput := outputScanner.Scan()
+		if answerScanner.Err() != nil {
+			return result(OutcomeFail, "can not read answer, error: %v", answerScanner.Err())
+		}
+		if outputScanner.Err() != nil {
+			return result(OutcomeWrongAnswer, "can not read output, error: %v", outputScanner.Err())
+		}
+		if !hasAnswer && !hasOutput {
+			break
+		}
+		n++
+		if !hasAnswer {
+			return result(OutcomeWrongAnswer, "Participant output contains extra tokens")
+		}
+		if !hasOutput {
+			return result(OutcomeUnexpectedEOF, "Unexpected EOF in the participants output")
+		}
+		if res := compare(n, answerScanner.Text(), outputScanner.Text()); res != nil {
+			return res
+		}
+	}
+	return result(OutcomeAccepted, "%d %s", n, name)
+}
+
+func readTokens(reader io.Reader, limit int) ([]string, error) {
+	scanner := newScanner(reader, bufio.ScanWords)
+	var tokens []string
+	for len(tokens) < limit && scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+func readLines(reader io.Reader) ([][]string, error) {
+	scanner := newScanner(reader, bufio.ScanLines)
+	var lines [][]string
+	for scanner.Scan() {
+		lines = append(lines, strings.Fields(scanner.Text()))
+	}
+	if scanner.Err() != nil {
+		return nil, scanner.Err()
+	}
+	// Trailing empty lines are ignored
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+func isYesNo(token string) bool {
+	token = strings.ToUpper(token)
+	return token == "YES" || token == "NO"
+}
+
+func floatsEqual(expected float64, found float64, eps float64) bool {
+	if math.IsNaN(expected) || math.IsNaN(found) {
+		return math.IsNaN(expected) && math.IsNaN(found)
+	}
+	if math.IsInf(expected, 0) || math.IsInf(found, 0) {
+		return expected == found
+	}
+	return floatsError(expected, found) <= eps+1e-15
+}
+
+func floatsError(expected float64, found float64) float64 {
+	absoluteError := math.Abs(expected - found)
+	if math.Abs(expected) > 1 {
+		return min(absoluteError, absoluteError/math.Abs(expected))
+	}
+	return absoluteError
+}
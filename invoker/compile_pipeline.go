@@ -43,10 +43,20 @@ func (s *JobPipelineState) compilationProcessPipeline() error {
 		return err
 	}
 
+	// Graders are loaded first, so submitted files can not replace them
+	err = s.loadGraderFiles()
+	if err != nil {
+		return err
+	}
+
 	err = s.loadSolutionSourceFile()
 	if err != nil {
 		return err
 	}
+	if s.compile.result != nil {
+		// Submitted archive can not be unpacked, compilation error is already set
+		return nil
+	}
 
 	err = s.setupCompileScript()
 	if err != nil {
@@ -71,7 +81,7 @@ func (s *JobPipelineState) setupCompileScript() error {
 	if !ok {
 		return fmt.Errorf("submission language %s does not exist", s.job.submission.Language)
 	}
-	script, err := s.compile.language.GenerateScript(s.compile.sourceName, solutionBinaryFile)
+	script, err := s.compile.language.GenerateScript(s.compile.sources, solutionBinaryFile, s.compile.graders)
 	if err != nil {
 		return fmt.Errorf("can not generate compile script, error: %v", err)
 	}
@@ -19,11 +19,16 @@ type Language struct {
 	Template *template.Template `yaml:"-"`
 }
 
-func (l *Language) GenerateScript(source string, binary string) ([]byte, error) {
+// GenerateScript fills in compile script template. Besides language TemplateValues, template gets "sources",
+// list of submitted files, "source", the first of them that is the only file of usual submission, "binary" file name
+// and "graders", list of problem grader files that should be compiled with source
+func (l *Language) GenerateScript(sources []string, binary string, graders []string) ([]byte, error) {
 	var script bytes.Buffer
 	values := map[string]interface{}{
-		"source": source,
-		"binary": binary,
+		"source":  sources[0],
+		"sources": sources,
+		"binary":  binary,
+		"graders": graders,
 	}
 	maps.Copy(values, l.TemplateValues)
 
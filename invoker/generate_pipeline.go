@@ -0,0 +1,191 @@
+package invoker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/invoker/sandbox"
+	"testing_system/lib/logger"
+)
+
+// messageHeadSize limits generator and validator messages that are returned in job error
+const messageHeadSize = 1024
+
+func (i *Invoker) fullGenerateTestPipeline(sandbox sandbox.ISandbox, job *Job) {
+	s := i.newPipelineState(sandbox, job)
+	s.test = new(pipelineTestData)
+	s.generate = new(pipelineGenerateData)
+	s.loggerData = fmt.Sprintf(
+		"generate test job: %s submission: %d problem %d revision %d test %d",
+		job.ID,
+		job.submission.ID,
+		job.problem.ID,
+		job.problem.Revision,
+		job.Test,
+	)
+	defer s.checkFinish()
+
+	logger.Trace("Starting test generation for %s", s.loggerData)
+
+	err := s.generateTestProcessPipeline()
+	if err != nil {
+		logger.Error("Error in %s error: %v", s.loggerData, err)
+		s.failJob("job %s error: %v", job.ID, err)
+		return
+	}
+
+	err = s.uploadGeneratedTest()
+	if err != nil {
+		logger.Error("Error in %s error: %v", s.loggerData, err)
+		s.failJob("job %s error: %v", job.ID, err)
+		return
+	}
+
+	s.successJob(s.test.runResult)
+}
+
+func (s *JobPipelineState) generateTestProcessPipeline() error {
+	err := s.initSandbox()
+	if err != nil {
+		return err
+	}
+
+	line := s.job.problem.FindTestScriptLine(s.job.Test)
+	s.generate.inputGenerated = line != nil
+	if line != nil {
+		err = s.loadGeneratorBinaryFile(line.Generator)
+		if err != nil {
+			return err
+		}
+		err = s.executeGeneratorRunCommand(line.Args)
+	} else {
+		err = s.loadTestInput()
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.job.problem.HasValidator {
+		err = s.fullValidatePipeline()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.loadSolutionBinary()
+	if err != nil {
+		return err
+	}
+
+	err = s.generateTestRunConfig()
+	if err != nil {
+		return err
+	}
+
+	if s.job.problem.ProblemType == models.ProblemTypeTwoPhase {
+		return s.fullTwoPhasePipeline()
+	}
+	return s.executeTestRunCommand()
+}
+
+func (s *JobPipelineState) uploadGeneratedTest() error {
+	if s.test.runResult.Verdict != verdict.OK {
+		// Main solution verdict is reported as test result, test is not saved
+		return nil
+	}
+	if s.generate.inputGenerated {
+		err := s.uploadTestFile(testInputFile, resource.TestInput)
+		if err != nil {
+			return err
+		}
+	}
+	return s.uploadTestFile(testOutputFile, resource.TestAnswer)
+}
+
+func (s *JobPipelineState) executeGeneratorRunCommand(args []string) error {
+	s.generate.generatorConfig = &sandbox.ExecuteConfig{
+		RunLimitsConfig: *s.invoker.TS.Config.Invoker.GeneratorLimits,
+		Command:         generatorBinaryFile,
+		Args:            args,
+		Stdout:          &sandbox.IORedirect{FileName: testInputFile},
+		Stderr:          &sandbox.IORedirect{FileName: generatorErrorFile},
+		Ctx:             s.job.stopCtx,
+	}
+
+	s.executeWaitGroup.Add(1)
+	err := s.runProcess(func() {
+		s.generate.generatorResult = s.sandbox.Run(s.generate.generatorConfig)
+		s.executeWaitGroup.Done()
+	})
+	if err != nil {
+		return fmt.Errorf("can not execute generator command, error: %v", err)
+	}
+	s.executeWaitGroup.Wait()
+
+	result := s.generate.generatorResult
+	if result.Err != nil {
+		return fmt.Errorf("error while running generator in sandbox, error: %v", result.Err)
+	}
+	if result.Verdict != verdict.OK {
+		return fmt.Errorf("generator finished with verdict %v, stderr: %s",
+			result.Verdict, s.readSandboxFileHead(generatorErrorFile))
+	}
+	logger.Trace("Generated test input for %s", s.loggerData)
+	return nil
+}
+
+func (s *JobPipelineState) fullValidatePipeline() error {
+	err := s.loadValidatorBinaryFile()
+	if err != nil {
+		return err
+	}
+
+	s.generate.validatorConfig = &sandbox.ExecuteConfig{
+		RunLimitsConfig: *s.invoker.TS.Config.Invoker.GeneratorLimits,
+		Command:         validatorBinaryFile,
+		Stdin:           &sandbox.IORedirect{FileName: testInputFile},
+		Stdout:          &sandbox.IORedirect{FileName: validatorOutputFile},
+		StderrToStdout:  true,
+		Ctx:             s.job.stopCtx,
+	}
+
+	s.executeWaitGroup.Add(1)
+	err = s.runProcess(func() {
+		s.generate.validatorResult = s.sandbox.Run(s.generate.validatorConfig)
+		s.executeWaitGroup.Done()
+	})
+	if err != nil {
+		return fmt.Errorf("can not execute validator command, error: %v", err)
+	}
+	s.executeWaitGroup.Wait()
+
+	result := s.generate.validatorResult
+	if result.Err != nil {
+		return fmt.Errorf("error while running validator in sandbox, error: %v", result.Err)
+	}
+	switch result.Verdict {
+	case verdict.OK:
+		logger.Trace("Validated test input for %s", s.loggerData)
+		return nil
+	case verdict.RT:
+		return fmt.Errorf("validator rejected test input: %s", s.readSandboxFileHead(validatorOutputFile))
+	default:
+		return fmt.Errorf("validator finished with verdict %v", result.Verdict)
+	}
+}
+
+// readSandboxFileHead returns beginning of program message to show it in job error
+func (s *JobPipelineState) readSandboxFileHead(fileName string) string {
+	reader, err := s.openSandboxFile(fileName, false)
+	if err != nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, messageHeadSize))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -30,6 +30,10 @@ func (i *Invoker) handleNewJob(c *gin.Context) {
 		if !i.newTestJob(c, job) {
 			return
 		}
+	case invokerconn.GenerateTestJob:
+		if !i.newGenerateTestJob(c, job) {
+			return
+		}
 	default:
 		connector.RespErr(c, http.StatusBadRequest, "Can not parse job type %v", job.Type)
 		return
@@ -56,7 +60,7 @@ func (i *Invoker) stopJob(c *gin.Context) {
 
 	job, ok := i.ActiveJobs[jobID]
 	if ok {
-		if job.Type == invokerconn.TestJob {
+		if job.Type == invokerconn.TestJob || job.Type == invokerconn.GenerateTestJob {
 			job.stopFunc()
 		}
 	}
@@ -0,0 +1,261 @@
+package invoker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing_system/common/constants/verdict"
+	"testing_system/invoker/sandbox"
+	"testing_system/lib/logger"
+)
+
+// fullInteractionPipeline runs solution together with interactor.
+// After it finishes, s.test.runResult contains solution run result with verdict combined with interactor verdict:
+// OK means that solution output should be checked by checker, WR and CF are final verdicts set by interactor
+func (s *JobPipelineState) fullInteractionPipeline() error {
+	err := s.initInteractorSandbox()
+	if err != nil {
+		return err
+	}
+
+	err = s.loadInteractorFiles()
+	if err != nil {
+		return err
+	}
+
+	err = s.generateInteractorRunConfig()
+	if err != nil {
+		return err
+	}
+
+	err = s.executeInteraction()
+	if err != nil {
+		return err
+	}
+
+	err = s.parseInteractionResult()
+	if err != nil {
+		return err
+	}
+
+	err = s.copyInteractorOutput()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *JobPipelineState) initInteractorSandbox() error {
+	err := s.interactorSandbox.Init()
+	if err != nil {
+		return fmt.Errorf("can not initialize interactor sandbox, error: %v", err)
+	}
+	s.defers = append(s.defers, s.interactorSandbox.Cleanup)
+	return nil
+}
+
+func (s *JobPipelineState) generateInteractorRunConfig() error {
+	s.test.interactConfig = &sandbox.ExecuteConfig{
+		RunLimitsConfig: *s.invoker.TS.Config.Invoker.CheckerLimits,
+	}
+	// Interactor waits for solution, so it should not be killed before solution
+	if s.test.interactConfig.WallTimeLimit < s.test.runConfig.WallTimeLimit {
+		s.test.interactConfig.WallTimeLimit = s.test.runConfig.WallTimeLimit
+	}
+
+	s.test.interactConfig.Command = interactorBinaryFile
+	s.test.interactConfig.Args = []string{
+		testInputFile, testOutputFile, testAnswerFile, interactorResultFile, checkResultFileArg,
+	}
+	s.test.interactConfig.Ctx = s.job.stopCtx
+	logger.Trace("Generated interactor run config for %s", s.loggerData)
+	return nil
+}
+
+func (s *JobPipelineState) executeInteraction() error {
+	solutionStdin, interactorStdout, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("can not create pipe from interactor to solution, error: %v", err)
+	}
+	interactorStdin, solutionStdout, err := os.Pipe()
+	if err != nil {
+		closePipeFiles(solutionStdin, interactorStdout)
+		return fmt.Errorf("can not create pipe from solution to interactor, error: %v", err)
+	}
+
+	s.test.runConfig.Stdin = &sandbox.IORedirect{Input: solutionStdin}
+	s.test.runConfig.Stdout = &sandbox.IORedirect{Output: solutionStdout}
+	s.test.interactConfig.Stdin = &sandbox.IORedirect{Input: interactorStdin}
+	s.test.interactConfig.Stdout = &sandbox.IORedirect{Output: interactorStdout}
+
+	s.executeWaitGroup.Add(1)
+	err = s.runProcess(func() {
+		s.runInteraction(
+			[]*os.File{solutionStdin, solutionStdout},
+			[]*os.File{interactorStdin, interactorStdout},
+		)
+	})
+	if err != nil {
+		closePipeFiles(solutionStdin, solutionStdout, interactorStdin, interactorStdout)
+		return fmt.Errorf("can not execute interaction commands, error: %v", err)
+	}
+	s.executeWaitGroup.Wait()
+
+	if s.test.runResult.Err != nil {
+		return fmt.Errorf("error while running solution in sandbox, error: %v", s.test.runResult.Err)
+	}
+	if s.test.interactResult.Err != nil {
+		return fmt.Errorf("error while running interactor in sandbox, error: %v", s.test.interactResult.Err)
+	}
+	logger.Trace(
+		"Finished interaction for %s with solution verdict %s and interactor verdict %s",
+		s.loggerData, s.test.runResult.Verdict, s.test.interactResult.Verdict,
+	)
+	return nil
+}
+
+// runInteraction runs both processes inside single runner task, so the interaction can not deadlock
+// waiting for free runner thread. Pipe ends are closed after each process exits, so the other side receives EOF
+func (s *JobPipelineState) runInteraction(solutionFiles []*os.File, interactorFiles []*os.File) {
+	var interactorWaitGroup sync.WaitGroup
+	interactorWaitGroup.Add(1)
+	go func() {
+		s.test.interactResult = s.interactorSandbox.Run(s.test.interactConfig)
+		closePipeFiles(interactorFiles...)
+		interactorWaitGroup.Done()
+	}()
+
+	s.test.runResult = s.sandbox.Run(s.test.runConfig)
+	closePipeFiles(solutionFiles...)
+
+	interactorWaitGroup.Wait()
+	s.executeWaitGroup.Done()
+}
+
+func closePipeFiles(files ...*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+func (s *JobPipelineState) parseInteractionResult() error {
+	if s.test.runResult.Verdict == verdict.SK || s.test.interactResult.Verdict == verdict.SK {
+		s.test.runResult.Verdict = verdict.SK
+		logger.Trace("Interaction result is not parsed because job is stopped for %s", s.loggerData)
+		return nil
+	}
+
+	switch s.test.interactResult.Verdict {
+	case verdict.OK, verdict.RT:
+	case verdict.TL:
+		return fmt.Errorf("interactor running took more than %v time", s.test.interactConfig.TimeLimit)
+	case verdict.ML:
+		return fmt.Errorf("interactor running took more than %v memory", s.test.interactConfig.MemoryLimit)
+	case verdict.WL:
+		return fmt.Errorf("interactor running took more than %v wall time", s.test.interactConfig.WallTimeLimit)
+	case verdict.SE:
+		return fmt.Errorf("interactor security violation")
+	default:
+		return fmt.Errorf("unknown interactor sandbox run verdict: %s", s.test.interactResult.Verdict)
+	}
+
+	interactorVerdict, message, err := s.readInteractorVerdict()
+	if err != nil {
+		return err
+	}
+	s.test.checkerOutputReader = s.limitedReader(strings.NewReader(message))
+
+	switch {
+	case interactorVerdict == verdict.CF:
+		// Only in case of interactor CF verdict we accept job as successful
+		s.test.runResult.Verdict = verdict.CF
+	case s.test.runResult.Verdict == verdict.TL ||
+		s.test.runResult.Verdict == verdict.ML ||
+		s.test.runResult.Verdict == verdict.WL ||
+		s.test.runResult.Verdict == verdict.SE:
+		// Solution was killed by sandbox, so interactor verdict is caused by closed pipe
+	case interactorVerdict == verdict.WR:
+		// Solution runtime errors are mostly caused by interactor closing the pipe, so interactor verdict is preferred
+		s.test.runResult.Verdict = verdict.WR
+	}
+
+	logger.Trace(
+		"Parsed interactor result for %s, interactor verdict is %s, final verdict is %s",
+		s.loggerData, interactorVerdict, s.test.runResult.Verdict,
+	)
+	return nil
+}
+
+// readInteractorVerdict reads testlib interactor result file and falls back to testlib exit codes if there is no such file
+func (s *JobPipelineState) readInteractorVerdict() (verdict.Verdict, string, error) {
+	exitCode := s.test.interactResult.Statistics.ExitCode
+	resultReader, err := os.Open(filepath.Join(s.interactorSandbox.Dir(), interactorResultFile))
+	if errors.Is(err, os.ErrNotExist) {
+		switch exitCode {
+		case 0:
+			return verdict.OK, "", nil
+		case 1, 2, 8:
+			return verdict.WR, "", nil
+		case 3:
+			return verdict.CF, "", nil
+		default:
+			return "", "", fmt.Errorf("interactor exited with unknown exit code %d", exitCode)
+		}
+	} else if err != nil {
+		return "", "", fmt.Errorf("can not open interactor result file, error: %v", err)
+	}
+	defer resultReader.Close()
+
+	result, err := decodeTestlibResult(resultReader)
+	if err != nil {
+		return "", "", fmt.Errorf("can not parse interactor result xml file in appes mode: %s", err.Error())
+	}
+
+	switch result.Outcome {
+	case "accepted":
+		return verdict.OK, result.Value, nil
+	case "wrong-answer", "presentation-error", "unexpected-eof":
+		return verdict.WR, result.Value, nil
+	case "fail":
+		return verdict.CF, result.Value, nil
+	default:
+		return "", "", fmt.Errorf(
+			"unsupported interactor verdict %s, interactor exited with exit code %d",
+			result.Outcome,
+			exitCode,
+		)
+	}
+}
+
+// copyInteractorOutput copies interactor output to solution sandbox, so checker and resources upload can use it
+func (s *JobPipelineState) copyInteractorOutput() error {
+	if s.test.runResult.Verdict == verdict.SK {
+		return nil
+	}
+	src := filepath.Join(s.interactorSandbox.Dir(), testOutputFile)
+	_, err := os.Stat(src)
+	if errors.Is(err, os.ErrNotExist) {
+		file, err := os.Create(filepath.Join(s.sandbox.Dir(), testOutputFile))
+		if err != nil {
+			return fmt.Errorf("can not create empty interactor output file, error: %v", err)
+		}
+		return file.Close()
+	} else if err != nil {
+		return fmt.Errorf("can not stat interactor output file, error: %v", err)
+	}
+
+	// Solution could create its own output file, it is replaced by interactor output
+	err = os.Remove(filepath.Join(s.sandbox.Dir(), testOutputFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("can not remove solution output file, error: %v", err)
+	}
+	err = s.copyFileToSandbox(src, testOutputFile, 0644)
+	if err != nil {
+		return fmt.Errorf("can not copy interactor output to sandbox, error: %v", err)
+	}
+	logger.Trace("Copied interactor output to sandbox for %s", s.loggerData)
+	return nil
+}
@@ -52,7 +52,7 @@ func SetupInvoker(ts *common.TestingSystem) error {
 	invoker.initializeSandboxThreads()
 	invoker.initializeRunnerThreads()
 
-	r := ts.Router.Group("/invoker")
+	r := ts.Router.Group("/invoker", ts.AuthMiddleware(ts.Config.Invoker.Auth))
 	r.GET("/status", invoker.handleStatus)
 	r.POST("/job/new", invoker.handleNewJob)
 	r.POST("/reset_cache", invoker.resetCache)
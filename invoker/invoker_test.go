@@ -1,9 +1,13 @@
 package invoker
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,6 +17,7 @@ import (
 	"testing_system/common"
 	"testing_system/common/config"
 	"testing_system/common/connectors/invokerconn"
+	"testing_system/common/constants/standardchecker"
 	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/invoker/compiler"
@@ -22,12 +27,14 @@ import (
 )
 
 type testState struct {
-	t        *testing.T
-	TS       *common.TestingSystem
-	Invoker  *Invoker
-	Sandbox  sandbox.ISandbox
-	Dir      string
-	FilesDir string
+	t       *testing.T
+	TS      *common.TestingSystem
+	Invoker *Invoker
+	Sandbox sandbox.ISandbox
+	// InteractorSandbox is used only for interactive problems
+	InteractorSandbox sandbox.ISandbox
+	Dir               string
+	FilesDir          string
 }
 
 func newTestState(t *testing.T, sandboxType string) *testState {
@@ -71,19 +78,22 @@ func newTestState(t *testing.T, sandboxType string) *testState {
 	}()
 	require.NoError(t, os.CopyFS(ts.FilesDir, os.DirFS("testdata/files")))
 	ts.Sandbox = ts.Invoker.newSandbox(1)
+	ts.InteractorSandbox = ts.Invoker.newSandbox(2)
 	return ts
 }
 
 func (ts *testState) testCompile(submitID uint) *JobPipelineState {
+	return ts.testCompileWithProblem(submitID, &models.Problem{ID: 1})
+}
+
+func (ts *testState) testCompileWithProblem(submitID uint, problem *models.Problem) *JobPipelineState {
 	job := &Job{
 		Job: invokerconn.Job{
 			ID:       "JOB",
 			SubmitID: submitID,
 			Type:     invokerconn.CompileJob,
 		},
-		problem: &models.Problem{
-			ID: 1,
-		},
+		problem: problem,
 		submission: &models.Submission{
 			ID:        submitID,
 			ProblemID: 1,
@@ -96,7 +106,32 @@ func (ts *testState) testCompile(submitID uint) *JobPipelineState {
 		fmt.Sprintf("%s/source/%d/%d.cpp", ts.FilesDir, submitID, submitID),
 		uint64(submitID),
 	))
+	return ts.runCompileJob(job)
+}
+
+func (ts *testState) testCompileArchive(submitID uint, archive []byte) *JobPipelineState {
+	archivePath := filepath.Join(ts.Dir, fmt.Sprintf("source_%d.zip", submitID))
+	require.NoError(ts.t, os.WriteFile(archivePath, archive, 0666))
+	require.NoError(ts.t, ts.Invoker.Storage.Source.Insert(ts.Invoker.Storage.GetEpoch(), archivePath, uint64(submitID)))
 
+	job := &Job{
+		Job: invokerconn.Job{
+			ID:       "JOB",
+			SubmitID: submitID,
+			Type:     invokerconn.CompileJob,
+		},
+		problem: &models.Problem{ID: 1},
+		submission: &models.Submission{
+			ID:            submitID,
+			ProblemID:     1,
+			Language:      "cpp",
+			SourceArchive: true,
+		},
+	}
+	return ts.runCompileJob(job)
+}
+
+func (ts *testState) runCompileJob(job *Job) *JobPipelineState {
 	s := ts.Invoker.newPipelineState(ts.Sandbox, job)
 	s.compile = new(pipelineCompileData)
 	s.loggerData = fmt.Sprintf("compile job: %s submission: %d", job.ID, job.submission.ID)
@@ -139,6 +174,93 @@ func testCompileSandbox(t *testing.T, sandboxType string) {
 	require.Equal(t, verdict.CE, s.compile.result.Verdict)
 	s.finish()
 
+	// Grader with main function is compiled together with source that implements solve function
+	for _, name := range []string{"grader.cpp", "grader.h"} {
+		require.NoError(t, ts.Invoker.Storage.Grader.InsertNamed(
+			ts.Invoker.Storage.GetEpoch(),
+			filepath.Join(ts.FilesDir, "grader", "1", name),
+			name,
+			1, 0,
+		))
+	}
+	s = ts.testCompileWithProblem(3, &models.Problem{
+		ID: 1,
+		Graders: models.ProblemGraders{
+			{Name: "grader.cpp", Language: "cpp"},
+			{Name: "grader.h", Language: "cpp"},
+			{Name: "grader.py", Language: "python"},
+		},
+	})
+	require.Equal(t, verdict.CD, s.compile.result.Verdict)
+	require.Equal(t, []string{"grader.cpp", "grader.h"}, s.compile.graders)
+
+	cmd = exec.Command(filepath.Join(s.sandbox.Dir(), solutionBinaryFile))
+	cmd.Stdin = strings.NewReader("1")
+	stdout.Reset()
+	cmd.Stdout = &stdout
+	require.NoError(t, cmd.Run())
+	require.Equal(t, "2", strings.TrimSpace(stdout.String()))
+	s.finish()
+
+	ts.Invoker.RunnerThreads.stop()
+}
+
+func TestCompileArchive(t *testing.T) {
+	t.Run("Simple sandbox", func(t *testing.T) { testCompileArchiveSandbox(t, "simple") })
+
+	t.Run("Isolate sandbox", func(t *testing.T) {
+		_, err := os.Stat("/usr/local/bin/isolate")
+		if err != nil {
+			t.Skip("No isolate installed on current device, skipping isolate tests")
+		} else {
+			testCompileArchiveSandbox(t, "isolate")
+		}
+	})
+}
+
+func testCompileArchiveSandbox(t *testing.T, sandboxType string) {
+	ts := newTestState(t, sandboxType)
+
+	t.Run("Several files", func(t *testing.T) {
+		s := ts.testCompileArchive(111, zipArchive(t, map[string]string{
+			"main.cpp":      "#include <iostream>\n#include \"lib/solve.h\"\nint main() { int a; std::cin >> a; std::cout << solve(a); }\n",
+			"lib/solve.h":   "int solve(int a);\n",
+			"lib/solve.cpp": "#include \"solve.h\"\nint solve(int a) { return a * 2; }\n",
+		}))
+		defer s.finish()
+		require.Equal(t, verdict.CD, s.compile.result.Verdict)
+		require.Equal(t, []string{"lib/solve.cpp", "lib/solve.h", "main.cpp"}, s.compile.sources)
+
+		cmd := exec.Command(filepath.Join(s.sandbox.Dir(), solutionBinaryFile))
+		cmd.Stdin = strings.NewReader("21")
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		require.Equal(t, "42", string(output))
+	})
+
+	t.Run("Not archive", func(t *testing.T) {
+		s := ts.testCompileArchive(112, []byte("int main() {}"))
+		defer s.finish()
+		require.Equal(t, verdict.CE, s.compile.result.Verdict)
+		message, err := io.ReadAll(s.compile.messageReader)
+		require.NoError(t, err)
+		require.Contains(t, string(message), "not zip archive")
+	})
+
+	t.Run("File outside of archive", func(t *testing.T) {
+		s := ts.testCompileArchive(113, zipArchive(t, map[string]string{"../main.cpp": "int main() {}"}))
+		defer s.finish()
+		require.Equal(t, verdict.CE, s.compile.result.Verdict)
+		_, err := os.Stat(filepath.Join(filepath.Dir(s.sandbox.Dir()), "main.cpp"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Empty archive", func(t *testing.T) {
+		s := ts.testCompileArchive(114, zipArchive(t, map[string]string{}))
+		defer s.finish()
+		require.Equal(t, verdict.CE, s.compile.result.Verdict)
+	})
+
 	ts.Invoker.RunnerThreads.stop()
 }
 
@@ -146,13 +268,13 @@ func (ts *testState) addProblem(problemID uint) {
 	require.NoError(ts.t, ts.Invoker.Storage.TestInput.Insert(
 		ts.Invoker.Storage.GetEpoch(),
 		fmt.Sprintf("%s/test_input/%d-1/1", ts.FilesDir, problemID),
-		uint64(problemID), 1,
+		uint64(problemID), 0, 1,
 	))
 
 	require.NoError(ts.t, ts.Invoker.Storage.TestAnswer.Insert(
 		ts.Invoker.Storage.GetEpoch(),
 		fmt.Sprintf("%s/test_answer/%d-1/1.a", ts.FilesDir, problemID),
-		uint64(problemID), 1,
+		uint64(problemID), 0, 1,
 	))
 
 	checkerDir := fmt.Sprintf("%s/checker/%d", ts.FilesDir, problemID)
@@ -168,7 +290,25 @@ func (ts *testState) addProblem(problemID uint) {
 	require.NoError(ts.t, ts.Invoker.Storage.Checker.Insert(
 		ts.Invoker.Storage.GetEpoch(),
 		filepath.Join(checkerDir, "check"),
-		uint64(problemID),
+		uint64(problemID), 0,
+	))
+}
+
+func (ts *testState) addInteractor(problemID uint) {
+	interactorDir := fmt.Sprintf("%s/interactor/%d", ts.FilesDir, problemID)
+
+	testlib, err := os.ReadFile(filepath.Join(ts.FilesDir, "checker", "testlib.h"))
+	require.NoError(ts.t, err)
+	require.NoError(ts.t, os.WriteFile(filepath.Join(interactorDir, "testlib.h"), testlib, 0666))
+
+	cmd := exec.Command("g++", "interactor.cpp", "-std=c++17", "-o", "interactor")
+	cmd.Dir = interactorDir
+	require.NoError(ts.t, cmd.Run())
+
+	require.NoError(ts.t, ts.Invoker.Storage.Interactor.Insert(
+		ts.Invoker.Storage.GetEpoch(),
+		filepath.Join(interactorDir, "interactor"),
+		uint64(problemID), 0,
 	))
 }
 
@@ -184,6 +324,7 @@ func (ts *testState) prepareTestRun(submitID uint, problemID uint) *JobPipelineS
 			ID:          problemID,
 			TestsNumber: 1,
 		},
+		storageEpoch: ts.Invoker.Storage.GetEpoch(),
 		submission: &models.Submission{
 			ID:        submitID,
 			ProblemID: 1,
@@ -205,6 +346,7 @@ func (ts *testState) prepareTestRun(submitID uint, problemID uint) *JobPipelineS
 	))
 
 	s := ts.Invoker.newPipelineState(ts.Sandbox, job)
+	s.interactorSandbox = ts.InteractorSandbox
 	s.test = new(pipelineTestData)
 	s.loggerData = fmt.Sprintf(
 		"test job: %s submission: %d problem %d test %d",
@@ -218,7 +360,12 @@ func (ts *testState) prepareTestRun(submitID uint, problemID uint) *JobPipelineS
 }
 
 func (ts *testState) testRun(submitID uint, problemID uint) *sandbox.RunResult {
+	return ts.testRunWithProblem(submitID, problemID, func(*models.Problem) {})
+}
+
+func (ts *testState) testRunWithProblem(submitID uint, problemID uint, setup func(problem *models.Problem)) *sandbox.RunResult {
 	s := ts.prepareTestRun(submitID, problemID)
+	setup(s.job.problem)
 	defer s.finish()
 
 	require.NoError(ts.t, s.testingProcessPipeline())
@@ -262,11 +409,59 @@ func testRunSandbox(t *testing.T, sandboxType string) {
 		require.Equal(t, verdict.ML, res.Verdict)
 	}
 
+	for _, standardChecker := range []string{
+		standardchecker.Tokens, standardchecker.Lines, standardchecker.Integers, standardchecker.Floats6,
+	} {
+		// Solutions binaries are inserted to storage again, so the cache should be reset
+		ts.Invoker.Storage.Reset()
+		ts.addProblem(1)
+		setup := func(problem *models.Problem) {
+			problem.CheckerType = models.CheckerTypeStandard
+			problem.StandardChecker = standardChecker
+		}
+		res = ts.testRunWithProblem(3, 1, setup)
+		require.Equal(t, verdict.OK, res.Verdict)
+		res = ts.testRunWithProblem(6, 1, setup)
+		require.Equal(t, verdict.WA, res.Verdict)
+	}
+
 	ts.addProblem(2)
 	res = ts.testRun(8, 2)
 	require.Equal(t, verdict.PT, res.Verdict)
 	require.EqualValues(t, 5, *res.Points)
 
+	ts.Invoker.Storage.Reset()
+	ts.addProblem(3)
+	kattisSetup := func(problem *models.Problem) {
+		problem.CheckerType = models.CheckerTypeKattis
+	}
+	res = ts.testRunWithProblem(3, 3, kattisSetup)
+	require.Equal(t, verdict.OK, res.Verdict)
+	res = ts.testRunWithProblem(6, 3, kattisSetup)
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	ts.Invoker.Storage.Reset()
+	ts.addProblem(4)
+	cmsSetup := func(problem *models.Problem) {
+		problem.CheckerType = models.CheckerTypeCMS
+		problem.ProblemType = models.ProblemTypeIOI
+		problem.TestGroups = models.TestGroups{{
+			Name:        "1",
+			FirstTest:   1,
+			LastTest:    1,
+			TestScore:   pointer.Float64(10),
+			ScoringType: models.TestGroupScoringTypeEachTest,
+		}}
+	}
+	res = ts.testRunWithProblem(3, 4, cmsSetup)
+	require.Equal(t, verdict.OK, res.Verdict)
+	// CMS checker gives 0.5 score to answer that differs by 2
+	res = ts.testRunWithProblem(6, 4, cmsSetup)
+	require.Equal(t, verdict.PT, res.Verdict)
+	require.EqualValues(t, 5, *res.Points)
+
+	ts.Invoker.Storage.Reset()
+	ts.addProblem(1)
 	{
 		s := ts.prepareTestRun(9, 1)
 		defer s.finish()
@@ -283,3 +478,271 @@ func testRunSandbox(t *testing.T, sandboxType string) {
 
 	ts.Invoker.RunnerThreads.stop()
 }
+
+func (ts *testState) testInteractiveRun(submitID uint, problemID uint) *sandbox.RunResult {
+	return ts.testRunWithProblem(submitID, problemID, func(problem *models.Problem) {
+		problem.Interactive = true
+	})
+}
+
+func TestInteractiveRun(t *testing.T) {
+	t.Run("Simple sandbox", func(t *testing.T) { testInteractiveRunSandbox(t, "simple") })
+
+	t.Run("Isolate sandbox", func(t *testing.T) {
+		_, err := os.Stat("/usr/local/bin/isolate")
+		if err != nil {
+			t.Skip("No isolate installed on current device, skipping isolate tests")
+		} else {
+			testInteractiveRunSandbox(t, "isolate")
+		}
+	})
+}
+
+func testInteractiveRunSandbox(t *testing.T, sandboxType string) {
+	ts := newTestState(t, sandboxType)
+	ts.addProblem(1)
+	ts.addInteractor(1)
+
+	res := ts.testInteractiveRun(3, 1)
+	require.Equal(t, verdict.OK, res.Verdict)
+
+	res = ts.testInteractiveRun(6, 1)
+	require.Equal(t, verdict.WR, res.Verdict)
+
+	res = ts.testInteractiveRun(5, 1)
+	require.Equal(t, verdict.TL, res.Verdict)
+
+	// Solution does not see test input, its own output file is replaced by interactor output
+	res = ts.testInteractiveRun(12, 1)
+	require.Equal(t, verdict.OK, res.Verdict)
+
+	ts.Invoker.RunnerThreads.stop()
+}
+
+func (ts *testState) addGeneratorAndValidator(problemID uint) {
+	generatorDir := fmt.Sprintf("%s/generator/%d", ts.FilesDir, problemID)
+	cmd := exec.Command("g++", "gen.cpp", "-std=c++17", "-o", "gen")
+	cmd.Dir = generatorDir
+	require.NoError(ts.t, cmd.Run())
+	require.NoError(ts.t, ts.Invoker.Storage.Generator.InsertNamed(
+		ts.Invoker.Storage.GetEpoch(),
+		filepath.Join(generatorDir, "gen"),
+		"gen",
+		uint64(problemID), 0,
+	))
+
+	validatorDir := fmt.Sprintf("%s/validator/%d", ts.FilesDir, problemID)
+	cmd = exec.Command("g++", "val.cpp", "-std=c++17", "-o", "val")
+	cmd.Dir = validatorDir
+	require.NoError(ts.t, cmd.Run())
+	require.NoError(ts.t, ts.Invoker.Storage.Validator.Insert(
+		ts.Invoker.Storage.GetEpoch(),
+		filepath.Join(validatorDir, "val"),
+		uint64(problemID), 0,
+	))
+}
+
+func (ts *testState) testGenerateTest(submitID uint, problemID uint, script models.TestScript) (*JobPipelineState, error) {
+	// Each generation uses new cache epoch, as solution binary is inserted to cache for every run
+	ts.Invoker.Storage.Reset()
+	require.NoError(ts.t, ts.Invoker.Storage.TestInput.Insert(
+		ts.Invoker.Storage.GetEpoch(),
+		fmt.Sprintf("%s/test_input/%d-1/1", ts.FilesDir, problemID),
+		uint64(problemID), 0, 1,
+	))
+	ts.addGeneratorAndValidator(problemID)
+
+	s := ts.prepareTestRun(submitID, problemID)
+	s.job.Type = invokerconn.GenerateTestJob
+	s.job.problem.TestScript = script
+	s.job.problem.HasValidator = true
+	s.generate = new(pipelineGenerateData)
+	return s, s.generateTestProcessPipeline()
+}
+
+func TestGenerateTest(t *testing.T) {
+	t.Run("Simple sandbox", func(t *testing.T) { testGenerateTestSandbox(t, "simple") })
+
+	t.Run("Isolate sandbox", func(t *testing.T) {
+		_, err := os.Stat("/usr/local/bin/isolate")
+		if err != nil {
+			t.Skip("No isolate installed on current device, skipping isolate tests")
+		} else {
+			testGenerateTestSandbox(t, "isolate")
+		}
+	})
+}
+
+func testGenerateTestSandbox(t *testing.T, sandboxType string) {
+	ts := newTestState(t, sandboxType)
+
+	readSandboxFile := func(s *JobPipelineState, name string) string {
+		data, err := os.ReadFile(filepath.Join(s.sandbox.Dir(), name))
+		require.NoError(t, err)
+		return strings.TrimSpace(string(data))
+	}
+
+	t.Run("Generated input", func(t *testing.T) {
+		s, err := ts.testGenerateTest(3, 1, models.TestScript{{Test: 1, Generator: "gen", Args: []string{"5"}}})
+		defer s.finish()
+		require.NoError(t, err)
+		require.True(t, s.generate.inputGenerated)
+		require.Equal(t, verdict.OK, s.test.runResult.Verdict)
+		require.Equal(t, "5", readSandboxFile(s, testInputFile))
+		require.Equal(t, "6", readSandboxFile(s, testOutputFile))
+	})
+
+	t.Run("Uploaded input", func(t *testing.T) {
+		s, err := ts.testGenerateTest(3, 1, nil)
+		defer s.finish()
+		require.NoError(t, err)
+		require.False(t, s.generate.inputGenerated)
+		require.Equal(t, "1", readSandboxFile(s, testInputFile))
+		require.Equal(t, "2", readSandboxFile(s, testOutputFile))
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		s, err := ts.testGenerateTest(3, 1, models.TestScript{{Test: 1, Generator: "gen", Args: []string{"-5"}}})
+		defer s.finish()
+		require.ErrorContains(t, err, "validator rejected test input: a should be non-negative integer")
+	})
+
+	t.Run("Generator failed", func(t *testing.T) {
+		s, err := ts.testGenerateTest(3, 1, models.TestScript{{Test: 1, Generator: "gen"}})
+		defer s.finish()
+		require.ErrorContains(t, err, "expected one argument")
+	})
+
+	t.Run("Main solution failed", func(t *testing.T) {
+		s, err := ts.testGenerateTest(5, 1, models.TestScript{{Test: 1, Generator: "gen", Args: []string{"5"}}})
+		defer s.finish()
+		require.NoError(t, err)
+		require.Equal(t, verdict.TL, s.test.runResult.Verdict)
+	})
+
+	ts.Invoker.RunnerThreads.stop()
+}
+
+func (ts *testState) testOutputOnlyRun(submitID uint, problemID uint, archive []byte) *sandbox.RunResult {
+	archivePath := filepath.Join(ts.Dir, fmt.Sprintf("outputs_%d.zip", submitID))
+	require.NoError(ts.t, os.WriteFile(archivePath, archive, 0666))
+	require.NoError(ts.t, ts.Invoker.Storage.Source.Insert(ts.Invoker.Storage.GetEpoch(), archivePath, uint64(submitID)))
+
+	job := &Job{
+		Job: invokerconn.Job{
+			ID:       "JOB",
+			SubmitID: submitID,
+			Type:     invokerconn.TestJob,
+			Test:     1,
+		},
+		problem: &models.Problem{
+			ID:          problemID,
+			ProblemType: models.ProblemTypeOutputOnly,
+			TestsNumber: 1,
+		},
+		storageEpoch: ts.Invoker.Storage.GetEpoch(),
+		submission: &models.Submission{
+			ID:        submitID,
+			ProblemID: problemID,
+		},
+	}
+	s := ts.Invoker.newPipelineState(ts.Sandbox, job)
+	s.test = new(pipelineTestData)
+	s.loggerData = fmt.Sprintf("output only test job: %s submission: %d", job.ID, job.submission.ID)
+	defer s.finish()
+
+	require.NoError(ts.t, s.outputOnlyProcessPipeline())
+	return s.test.runResult
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, data := range files {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestOutputOnlyRun(t *testing.T) {
+	t.Run("Simple sandbox", func(t *testing.T) { testOutputOnlyRunSandbox(t, "simple") })
+
+	t.Run("Isolate sandbox", func(t *testing.T) {
+		_, err := os.Stat("/usr/local/bin/isolate")
+		if err != nil {
+			t.Skip("No isolate installed on current device, skipping isolate tests")
+		} else {
+			testOutputOnlyRunSandbox(t, "isolate")
+		}
+	})
+}
+
+func testOutputOnlyRunSandbox(t *testing.T, sandboxType string) {
+	ts := newTestState(t, sandboxType)
+	ts.addProblem(1)
+
+	res := ts.testOutputOnlyRun(101, 1, zipArchive(t, map[string]string{"1.out": "2\n", "2.out": "5\n"}))
+	require.Equal(t, verdict.OK, res.Verdict)
+
+	res = ts.testOutputOnlyRun(102, 1, zipArchive(t, map[string]string{"outputs/01": "2\n"}))
+	require.Equal(t, verdict.OK, res.Verdict)
+
+	res = ts.testOutputOnlyRun(103, 1, zipArchive(t, map[string]string{"1.txt": "3\n"}))
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	res = ts.testOutputOnlyRun(104, 1, zipArchive(t, map[string]string{"2.out": "2\n"}))
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	res = ts.testOutputOnlyRun(105, 1, []byte("2\n"))
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	// Output of the test can not be chosen if several files have its number
+	res = ts.testOutputOnlyRun(106, 1, zipArchive(t, map[string]string{"1.out": "2\n", "01.txt": "2\n"}))
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	ts.Invoker.RunnerThreads.stop()
+}
+
+func TestTwoPhaseRun(t *testing.T) {
+	t.Run("Simple sandbox", func(t *testing.T) { testTwoPhaseRunSandbox(t, "simple") })
+
+	t.Run("Isolate sandbox", func(t *testing.T) {
+		_, err := os.Stat("/usr/local/bin/isolate")
+		if err != nil {
+			t.Skip("No isolate installed on current device, skipping isolate tests")
+		} else {
+			testTwoPhaseRunSandbox(t, "isolate")
+		}
+	})
+}
+
+func testTwoPhaseRunSandbox(t *testing.T, sandboxType string) {
+	ts := newTestState(t, sandboxType)
+	ts.addProblem(1)
+	setup := func(problem *models.Problem) {
+		problem.ProblemType = models.ProblemTypeTwoPhase
+	}
+
+	// The first phase multiplies input by 10, the second phase divides it back and adds 1
+	res := ts.testRunWithProblem(10, 1, setup)
+	require.Equal(t, verdict.OK, res.Verdict)
+	require.NotNil(t, res.Statistics)
+
+	// Solution that ignores phase argument adds 1 twice
+	res = ts.testRunWithProblem(3, 1, setup)
+	require.Equal(t, verdict.WA, res.Verdict)
+
+	// Verdict of failed first phase is final
+	res = ts.testRunWithProblem(11, 1, setup)
+	require.Equal(t, verdict.RT, res.Verdict)
+
+	// Both phases share time limit
+	res = ts.testRunWithProblem(13, 1, setup)
+	require.Equal(t, verdict.TL, res.Verdict)
+
+	ts.Invoker.RunnerThreads.stop()
+}
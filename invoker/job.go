@@ -53,17 +53,20 @@ func (i *Invoker) initJob(c *gin.Context, job *Job) bool {
 	}
 	job.submission = &submission
 
-	var problem models.Problem
-	if err := i.TS.DB.WithContext(c).Find(&problem, job.submission.ProblemID).Error; err != nil {
+	problem, err := models.LoadProblemRevision(
+		i.TS.DB.WithContext(c), job.submission.ProblemID, job.submission.ProblemRevision,
+	)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			connector.RespErr(c, http.StatusBadRequest, "Problem %d not found", job.submission.ProblemID)
+			connector.RespErr(c, http.StatusBadRequest,
+				"Problem %d revision %d not found", job.submission.ProblemID, job.submission.ProblemRevision)
 		} else {
 			logger.Error("Error while finding problem in db, error: %s", err.Error())
 			connector.RespErr(c, http.StatusInternalServerError, "DB Error")
-			return false
 		}
+		return false
 	}
-	job.problem = &problem
+	job.problem = problem
 	return true
 }
 
@@ -71,6 +74,12 @@ func (i *Invoker) newCompileJob(c *gin.Context, job *Job) bool {
 	i.Storage.Source.Lock(job.storageEpoch, uint64(job.submission.ID))
 	job.defers = append(job.defers, func() { i.Storage.Source.Unlock(job.storageEpoch, uint64(job.submission.ID)) })
 
+	problemID, revision := uint64(job.problem.ID), job.problem.Revision
+	for _, name := range job.problem.LanguageGraders(job.submission.Language) {
+		i.Storage.Grader.LockNamed(job.storageEpoch, name, problemID, revision)
+		job.defers = append(job.defers, func() { i.Storage.Grader.UnlockNamed(job.storageEpoch, name, problemID, revision) })
+	}
+
 	err := i.SandboxThreads.add(job)
 	if err != nil {
 		logger.Error("Error while adding compile job %s to sandbox queue, error: %s", job.ID, err.Error())
@@ -89,19 +98,32 @@ func (i *Invoker) newTestJob(c *gin.Context, job *Job) bool {
 		return false
 	}
 
-	i.Storage.Binary.Lock(job.storageEpoch, uint64(job.submission.ID))
-	job.defers = append(job.defers, func() { i.Storage.Binary.Unlock(job.storageEpoch, uint64(job.submission.ID)) })
+	if job.problem.ProblemType == models.ProblemTypeOutputOnly {
+		// Output only submission source is archive of outputs
+		i.Storage.Source.Lock(job.storageEpoch, uint64(job.submission.ID))
+		job.defers = append(job.defers, func() { i.Storage.Source.Unlock(job.storageEpoch, uint64(job.submission.ID)) })
+	} else {
+		i.Storage.Binary.Lock(job.storageEpoch, uint64(job.submission.ID))
+		job.defers = append(job.defers, func() { i.Storage.Binary.Unlock(job.storageEpoch, uint64(job.submission.ID)) })
+	}
 
-	i.Storage.TestInput.Lock(job.storageEpoch, uint64(job.problem.ID), job.Test)
-	job.defers = append(job.defers, func() { i.Storage.TestInput.Unlock(job.storageEpoch, uint64(job.problem.ID), job.Test) })
+	problemID, revision := uint64(job.problem.ID), job.problem.Revision
 
-	i.Storage.TestAnswer.Lock(job.storageEpoch, uint64(job.problem.ID), job.Test)
-	job.defers = append(job.defers, func() { i.Storage.TestAnswer.Unlock(job.storageEpoch, uint64(job.problem.ID), job.Test) })
+	i.Storage.TestInput.Lock(job.storageEpoch, problemID, revision, job.Test)
+	job.defers = append(job.defers, func() { i.Storage.TestInput.Unlock(job.storageEpoch, problemID, revision, job.Test) })
 
-	i.Storage.Checker.Lock(job.storageEpoch, uint64(job.problem.ID))
-	job.defers = append(job.defers, func() { i.Storage.Checker.Unlock(job.storageEpoch, uint64(job.problem.ID)) })
+	i.Storage.TestAnswer.Lock(job.storageEpoch, problemID, revision, job.Test)
+	job.defers = append(job.defers, func() { i.Storage.TestAnswer.Unlock(job.storageEpoch, problemID, revision, job.Test) })
 
-	// TODO: interactor
+	if job.problem.UsesCheckerBinary() {
+		i.Storage.Checker.Lock(job.storageEpoch, problemID, revision)
+		job.defers = append(job.defers, func() { i.Storage.Checker.Unlock(job.storageEpoch, problemID, revision) })
+	}
+
+	if job.problem.Interactive {
+		i.Storage.Interactor.Lock(job.storageEpoch, problemID, revision)
+		job.defers = append(job.defers, func() { i.Storage.Interactor.Unlock(job.storageEpoch, problemID, revision) })
+	}
 
 	err := i.SandboxThreads.add(job)
 	if err != nil {
@@ -111,3 +133,45 @@ func (i *Invoker) newTestJob(c *gin.Context, job *Job) bool {
 	}
 	return true
 }
+
+func (i *Invoker) newGenerateTestJob(c *gin.Context, job *Job) bool {
+	if job.Test <= 0 || job.Test > job.problem.TestsNumber {
+		connector.RespErr(c,
+			http.StatusBadRequest,
+			"%d test required, tests in problem %d are numbered from 1 to %d",
+			job.Test, job.problem.ID, job.problem.TestsNumber)
+		return false
+	}
+	if job.problem.Interactive {
+		connector.RespErr(c, http.StatusBadRequest, "Tests of interactive problem %d can not be generated", job.problem.ID)
+		return false
+	}
+
+	i.Storage.Binary.Lock(job.storageEpoch, uint64(job.submission.ID))
+	job.defers = append(job.defers, func() { i.Storage.Binary.Unlock(job.storageEpoch, uint64(job.submission.ID)) })
+
+	problemID, revision := uint64(job.problem.ID), job.problem.Revision
+
+	if line := job.problem.FindTestScriptLine(job.Test); line != nil {
+		i.Storage.Generator.LockNamed(job.storageEpoch, line.Generator, problemID, revision)
+		job.defers = append(job.defers, func() {
+			i.Storage.Generator.UnlockNamed(job.storageEpoch, line.Generator, problemID, revision)
+		})
+	} else {
+		i.Storage.TestInput.Lock(job.storageEpoch, problemID, revision, job.Test)
+		job.defers = append(job.defers, func() { i.Storage.TestInput.Unlock(job.storageEpoch, problemID, revision, job.Test) })
+	}
+
+	if job.problem.HasValidator {
+		i.Storage.Validator.Lock(job.storageEpoch, problemID, revision)
+		job.defers = append(job.defers, func() { i.Storage.Validator.Unlock(job.storageEpoch, problemID, revision) })
+	}
+
+	err := i.SandboxThreads.add(job)
+	if err != nil {
+		logger.Error("Error while adding generate test job %s to sandbox queue, error: %s", job.ID, err.Error())
+		connector.RespErr(c, http.StatusInternalServerError, "server error")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package invoker
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing_system/common/constants/verdict"
+	"testing_system/invoker/sandbox"
+	"testing_system/lib/logger"
+)
+
+// outputOnlyProcessPipeline checks output that is submitted for the test instead of running solution
+func (s *JobPipelineState) outputOnlyProcessPipeline() error {
+	err := s.initSandbox()
+	if err != nil {
+		return err
+	}
+
+	err = s.loadSubmittedOutput()
+	if err != nil {
+		return err
+	}
+
+	if s.test.runResult.Verdict != verdict.OK {
+		s.test.hasResources = false
+		return nil
+	}
+	s.test.hasResources = true
+
+	err = s.loadTestInput()
+	if err != nil {
+		return err
+	}
+
+	return s.fullCheckPipeline()
+}
+
+// loadSubmittedOutput extracts test output from submitted archive to sandbox.
+// Missing output and archive that can not be read are treated as empty output, so checker decides verdict.
+// Archive with several outputs for the test is rejected with WA
+func (s *JobPipelineState) loadSubmittedOutput() error {
+	source, err := s.loadResource(s.invoker.Storage.Source, uint64(s.job.submission.ID))
+	if err != nil {
+		return fmt.Errorf("can not get submitted outputs archive, error: %v", err)
+	}
+
+	s.test.runConfig = new(sandbox.ExecuteConfig)
+	fillInTestRunConfigLimits(s.test.runConfig, s.job.problem)
+	s.test.runResult = &sandbox.RunResult{Verdict: verdict.OK}
+
+	output, err := os.OpenFile(
+		filepath.Join(s.sandbox.Dir(), testOutputFile), os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_EXCL, 0644,
+	)
+	if err != nil {
+		return fmt.Errorf("can not create output file in sandbox, error: %v", err)
+	}
+	defer output.Close()
+
+	archive, err := zip.OpenReader(*source)
+	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			logger.Trace("Submitted outputs are not zip archive, output is empty for %s", s.loggerData)
+			return nil
+		}
+		return fmt.Errorf("can not open submitted outputs archive, error: %v", err)
+	}
+	defer archive.Close()
+
+	file, err := findSubmittedOutput(archive.File, s.job.Test)
+	if err != nil {
+		s.test.runResult.Verdict = verdict.WA
+		logger.Trace("Invalid submitted outputs archive for %s, error: %v", s.loggerData, err)
+		return nil
+	}
+	if file == nil {
+		logger.Trace("No output is submitted for %s", s.loggerData)
+		return nil
+	}
+	reader, err := file.Open()
+	if err != nil {
+		logger.Trace("Can not open submitted output %s for %s, error: %v", file.Name, s.loggerData, err)
+		return nil
+	}
+	defer reader.Close()
+
+	limit := int64(s.test.runConfig.MaxOutputSize)
+	written, err := io.Copy(output, io.LimitReader(reader, limit+1))
+	if err != nil {
+		logger.Trace("Can not extract submitted output %s for %s, error: %v", file.Name, s.loggerData, err)
+		return output.Truncate(0)
+	}
+	if written > limit {
+		s.test.runResult.Verdict = verdict.WA
+		logger.Trace("Submitted output %s is larger than %v for %s", file.Name, s.test.runConfig.MaxOutputSize, s.loggerData)
+		return nil
+	}
+	logger.Trace("Loaded submitted output %s to sandbox for %s", file.Name, s.loggerData)
+	return nil
+}
+
+// findSubmittedOutput returns archive file named by test number, e.g. "1", "01", "1.out" or "outputs/1.txt".
+// Several files with the test number are ambiguous, so they are an error
+func findSubmittedOutput(files []*zip.File, test uint64) (*zip.File, error) {
+	var found *zip.File
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Base(file.Name)
+		name = strings.TrimSuffix(name, path.Ext(name))
+		if number, err := strconv.ParseUint(name, 10, 64); err == nil && number == test {
+			if found != nil {
+				return nil, fmt.Errorf("files %s and %s are both outputs of test %d", found.Name, file.Name, test)
+			}
+			found = file
+		}
+	}
+	return found, nil
+}
@@ -17,11 +17,14 @@ type JobPipelineState struct {
 	job     *Job
 	invoker *Invoker
 	sandbox sandbox.ISandbox
+	// interactorSandbox is used only for interactive problems
+	interactorSandbox sandbox.ISandbox
 
 	executeWaitGroup sync.WaitGroup
 
-	compile *pipelineCompileData
-	test    *pipelineTestData
+	compile  *pipelineCompileData
+	test     *pipelineTestData
+	generate *pipelineGenerateData
 
 	metrics *masterconn.InvokerJobMetrics
 
@@ -36,7 +39,8 @@ type pipelineCompileData struct {
 	config   *sandbox.ExecuteConfig
 	result   *sandbox.RunResult
 
-	sourceName    string
+	sources       []string
+	graders       []string
 	messageReader io.Reader
 }
 
@@ -44,13 +48,30 @@ type pipelineTestData struct {
 	runConfig *sandbox.ExecuteConfig
 	runResult *sandbox.RunResult
 
+	// firstRunConfig and firstRunResult are used only for two phase problems
+	firstRunConfig *sandbox.ExecuteConfig
+	firstRunResult *sandbox.RunResult
+
 	checkConfig *sandbox.ExecuteConfig
 	checkResult *sandbox.RunResult
 
+	interactConfig *sandbox.ExecuteConfig
+	interactResult *sandbox.RunResult
+
 	checkerOutputReader io.Reader
 	hasResources        bool
 }
 
+type pipelineGenerateData struct {
+	generatorConfig *sandbox.ExecuteConfig
+	generatorResult *sandbox.RunResult
+
+	validatorConfig *sandbox.ExecuteConfig
+	validatorResult *sandbox.RunResult
+
+	inputGenerated bool
+}
+
 func (i *Invoker) newPipelineState(sandbox sandbox.ISandbox, job *Job) *JobPipelineState {
 	s := &JobPipelineState{
 		sandbox: sandbox,
@@ -23,6 +23,17 @@ const (
 	checkerBinaryFile      = "check"
 	checkResultFile        = "check_result.xml"
 	checkOutputFile        = "checker_output.txt"
+	checkScoreFile         = "check_score.txt"
+	kattisFeedbackDir      = "."
+	kattisJudgeMessageFile = "judgemessage.txt"
+	interactorBinaryFile   = "interactor"
+	interactorResultFile   = "interactor_result.xml"
+	generatorBinaryFile    = "generator"
+	generatorErrorFile     = "generator_stderr.txt"
+	validatorBinaryFile    = "validator"
+	validatorOutputFile    = "validator_output.txt"
+	intermediateFile       = "intermediate.txt"
+	firstPhaseErrorFile    = "first_phase_stderr.txt"
 )
 
 func (s *JobPipelineState) loadSolutionBinary() error {
@@ -39,7 +50,9 @@ func (s *JobPipelineState) loadSolutionBinary() error {
 }
 
 func (s *JobPipelineState) loadTestInput() error {
-	testInput, err := s.loadResource(s.invoker.Storage.TestInput, uint64(s.job.problem.ID), s.job.Test)
+	testInput, err := s.loadResource(
+		s.invoker.Storage.TestInput, uint64(s.job.problem.ID), s.job.problem.Revision, s.job.Test,
+	)
 	if err != nil {
 		return fmt.Errorf("can not get test input, error: %v", err)
 	}
@@ -52,7 +65,7 @@ func (s *JobPipelineState) loadTestInput() error {
 }
 
 func (s *JobPipelineState) loadCheckerBinaryFile() error {
-	checker, err := s.loadResource(s.invoker.Storage.Checker, uint64(s.job.problem.ID))
+	checker, err := s.loadResource(s.invoker.Storage.Checker, uint64(s.job.problem.ID), s.job.problem.Revision)
 	if err != nil {
 		return fmt.Errorf("can not get checker binary, error: %v", err)
 	}
@@ -65,7 +78,9 @@ func (s *JobPipelineState) loadCheckerBinaryFile() error {
 }
 
 func (s *JobPipelineState) loadTestAnswerFile() error {
-	testAnswer, err := s.loadResource(s.invoker.Storage.TestAnswer, uint64(s.job.problem.ID), s.job.Test)
+	testAnswer, err := s.loadResource(
+		s.invoker.Storage.TestAnswer, uint64(s.job.problem.ID), s.job.problem.Revision, s.job.Test,
+	)
 	if err != nil {
 		return fmt.Errorf("can not get test answer, error: %s", err.Error())
 	}
@@ -77,6 +92,67 @@ func (s *JobPipelineState) loadTestAnswerFile() error {
 	return nil
 }
 
+func (s *JobPipelineState) loadInteractorFiles() error {
+	interactor, err := s.loadResource(s.invoker.Storage.Interactor, uint64(s.job.problem.ID), s.job.problem.Revision)
+	if err != nil {
+		return fmt.Errorf("can not get interactor binary, error: %v", err)
+	}
+	err = s.copyFileToDir(s.interactorSandbox.Dir(), *interactor, interactorBinaryFile, 0755)
+	if err != nil {
+		return fmt.Errorf("can not copy interactor binary to sandbox, error: %v", err)
+	}
+
+	testInput, err := s.loadResource(
+		s.invoker.Storage.TestInput, uint64(s.job.problem.ID), s.job.problem.Revision, s.job.Test,
+	)
+	if err != nil {
+		return fmt.Errorf("can not get test input, error: %v", err)
+	}
+	err = s.copyFileToDir(s.interactorSandbox.Dir(), *testInput, testInputFile, 0644)
+	if err != nil {
+		return fmt.Errorf("can not copy test input to interactor sandbox, error: %v", err)
+	}
+
+	testAnswer, err := s.loadResource(
+		s.invoker.Storage.TestAnswer, uint64(s.job.problem.ID), s.job.problem.Revision, s.job.Test,
+	)
+	if err != nil {
+		return fmt.Errorf("can not get test answer, error: %v", err)
+	}
+	err = s.copyFileToDir(s.interactorSandbox.Dir(), *testAnswer, testAnswerFile, 0644)
+	if err != nil {
+		return fmt.Errorf("can not copy test answer to interactor sandbox, error: %v", err)
+	}
+	logger.Trace("Loaded interactor files to sandbox for %s", s.loggerData)
+	return nil
+}
+
+func (s *JobPipelineState) loadGeneratorBinaryFile(name string) error {
+	generator, err := s.loadNamedResource(s.invoker.Storage.Generator, name, uint64(s.job.problem.ID), s.job.problem.Revision)
+	if err != nil {
+		return fmt.Errorf("can not get generator %s binary, error: %v", name, err)
+	}
+	err = s.copyFileToSandbox(*generator, generatorBinaryFile, 0755)
+	if err != nil {
+		return fmt.Errorf("can not copy generator binary to sandbox, error: %v", err)
+	}
+	logger.Trace("Loaded generator %s binary to sandbox for %s", name, s.loggerData)
+	return nil
+}
+
+func (s *JobPipelineState) loadValidatorBinaryFile() error {
+	validator, err := s.loadResource(s.invoker.Storage.Validator, uint64(s.job.problem.ID), s.job.problem.Revision)
+	if err != nil {
+		return fmt.Errorf("can not get validator binary, error: %v", err)
+	}
+	err = s.copyFileToSandbox(*validator, validatorBinaryFile, 0755)
+	if err != nil {
+		return fmt.Errorf("can not copy validator binary to sandbox, error: %v", err)
+	}
+	logger.Trace("Loaded validator binary to sandbox for %s", s.loggerData)
+	return nil
+}
+
 func (s *JobPipelineState) loadSolutionSourceFile() error {
 	source, err := s.loadResource(s.invoker.Storage.Source, uint64(s.job.submission.ID))
 	if err != nil {
@@ -85,15 +161,39 @@ func (s *JobPipelineState) loadSolutionSourceFile() error {
 	if s.compile == nil {
 		return fmt.Errorf("can not save solution source, pipeline compile field not initialized")
 	}
-	s.compile.sourceName = "source_" + filepath.Base(*source)
-	err = s.copyFileToSandbox(*source, s.compile.sourceName, 0644)
+	if s.job.submission.SourceArchive {
+		return s.unpackSolutionSources(*source)
+	}
+	sourceName := "source_" + filepath.Base(*source)
+	err = s.copyFileToSandbox(*source, sourceName, 0644)
 	if err != nil {
 		return fmt.Errorf("can not copy submission source to sandbox, error: %v", err)
 	}
+	s.compile.sources = []string{sourceName}
 	logger.Trace("Loaded source to sandbox for %s", s.loggerData)
 	return nil
 }
 
+// loadGraderFiles puts problem graders of submission language to sandbox with their own names
+func (s *JobPipelineState) loadGraderFiles() error {
+	graders := s.job.problem.LanguageGraders(s.job.submission.Language)
+	for _, name := range graders {
+		grader, err := s.loadNamedResource(s.invoker.Storage.Grader, name, uint64(s.job.problem.ID), s.job.problem.Revision)
+		if err != nil {
+			return fmt.Errorf("can not get grader %s, error: %v", name, err)
+		}
+		err = s.copyFileToSandbox(*grader, name, 0644)
+		if err != nil {
+			return fmt.Errorf("can not copy grader %s to sandbox, error: %v", name, err)
+		}
+	}
+	s.compile.graders = graders
+	if len(graders) > 0 {
+		logger.Trace("Loaded %d graders to sandbox for %s", len(graders), s.loggerData)
+	}
+	return nil
+}
+
 func (s *JobPipelineState) uploadBinary() error {
 	reader, err := s.openSandboxFile(solutionBinaryFile, false)
 	if err != nil {
@@ -132,21 +232,46 @@ func (s *JobPipelineState) uploadOutput(fileName string, resourceType resource.T
 	return nil
 }
 
+// uploadTestFile saves generated test input or answer to problem revision that master created for generation
+func (s *JobPipelineState) uploadTestFile(fileName string, resourceType resource.Type) error {
+	reader, err := s.openSandboxFile(fileName, false)
+	if err != nil {
+		return fmt.Errorf("can not open %v file, error: %v", resourceType, err)
+	}
+
+	request := &storageconn.Request{
+		Resource:        resourceType,
+		ProblemID:       uint64(s.job.problem.ID),
+		ProblemRevision: s.job.problem.Revision,
+		TestID:          s.job.Test,
+		File:            reader,
+	}
+	resp := s.uploadResource(request)
+	if resp.Error != nil {
+		return fmt.Errorf("can not upload %v file to storage, error: %v", resourceType, resp.Error)
+	}
+	logger.Trace("Sent %v file to storage for %s", resourceType, s.loggerData)
+	return nil
+}
+
 func (s *JobPipelineState) copyFileToSandbox(src string, dst string, perm os.FileMode) error {
+	return s.copyFileToDir(s.sandbox.Dir(), src, dst, perm)
+}
+
+func (s *JobPipelineState) copyFileToDir(dir string, src string, dst string, perm os.FileMode) error {
 	defer updateMetrics(&s.metrics.FileActionsDuration, time.Now())
 	srcReader, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer srcReader.Close()
-	dstWriter, err := os.OpenFile(filepath.Join(s.sandbox.Dir(), dst), os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_EXCL, perm)
+	dstWriter, err := os.OpenFile(filepath.Join(dir, dst), os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_EXCL, perm)
 	if err != nil {
 		return err
 	}
 	defer dstWriter.Close()
 	_, err = io.Copy(dstWriter, srcReader)
-
-	return nil
+	return err
 }
 
 func (s *JobPipelineState) openSandboxFile(fileName string, limit bool) (io.Reader, error) {
@@ -175,6 +300,12 @@ func (s *JobPipelineState) loadResource(getter *storage.CacheGetter, args ...uin
 	return res, err
 }
 
+func (s *JobPipelineState) loadNamedResource(getter *storage.CacheGetter, name string, args ...uint64) (*string, error) {
+	defer updateMetrics(&s.metrics.ResourceWaitDuration, time.Now())
+	res, err := getter.GetNamed(s.job.storageEpoch, name, args...)
+	return res, err
+}
+
 func (s *JobPipelineState) uploadResource(request *storageconn.Request) *storageconn.Response {
 	defer updateMetrics(&s.metrics.SendResultDuration, time.Now())
 	resp := s.invoker.TS.StorageConn.Upload(request)
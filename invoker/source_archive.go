@@ -0,0 +1,95 @@
+package invoker
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing_system/common/constants/verdict"
+	"testing_system/invoker/sandbox"
+	"testing_system/lib/logger"
+)
+
+// maxSourceArchiveSize limits total size of files unpacked from submitted archive
+const maxSourceArchiveSize = 64 * 1024 * 1024
+
+// unpackSolutionSources extracts submitted archive to sandbox keeping relative file paths.
+// Archive that can not be unpacked gives compilation error with the reason as compilation message
+func (s *JobPipelineState) unpackSolutionSources(archivePath string) error {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			return s.failSourceUnpacking("Submitted source is not zip archive")
+		}
+		return fmt.Errorf("can not open submitted source archive, error: %v", err)
+	}
+	defer archive.Close()
+
+	var size int64
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(file.Name)) {
+			return s.failSourceUnpacking("Archive file %s is outside of archive root", file.Name)
+		}
+		name := path.Clean(file.Name)
+
+		written, err := s.unpackSourceFile(file, name, maxSourceArchiveSize-size)
+		if err != nil {
+			return s.failSourceUnpacking("Can not unpack archive file %s, error: %v", file.Name, err)
+		}
+		size += written
+		if size > maxSourceArchiveSize {
+			return s.failSourceUnpacking("Unpacked archive is larger than %d bytes", maxSourceArchiveSize)
+		}
+		s.compile.sources = append(s.compile.sources, name)
+	}
+	if len(s.compile.sources) == 0 {
+		return s.failSourceUnpacking("Submitted archive has no files")
+	}
+	slices.Sort(s.compile.sources)
+
+	logger.Trace("Unpacked %d source files to sandbox for %s", len(s.compile.sources), s.loggerData)
+	return nil
+}
+
+// unpackSourceFile writes at most limit+1 bytes of archive file, so caller can detect that limit is exceeded.
+// Existing files, e.g. problem graders or files repeated in archive, are not overwritten
+func (s *JobPipelineState) unpackSourceFile(file *zip.File, name string, limit int64) (int64, error) {
+	dst := filepath.Join(s.sandbox.Dir(), filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return 0, err
+	}
+	writer, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return 0, fmt.Errorf("file already exists")
+		}
+		return 0, err
+	}
+	defer writer.Close()
+
+	reader, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	return io.Copy(writer, io.LimitReader(reader, limit+1))
+}
+
+// failSourceUnpacking sets compilation error when submitted archive can not be unpacked
+func (s *JobPipelineState) failSourceUnpacking(format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	s.compile.result = &sandbox.RunResult{Verdict: verdict.CE}
+	s.compile.messageReader = strings.NewReader(message)
+	logger.Trace("Submitted archive can not be unpacked for %s, error: %s", s.loggerData, message)
+	return nil
+}
@@ -23,12 +23,15 @@ type cacheKey struct {
 
 	Resource resource.Type `json:"resource"`
 
-	// If resource is part of problem, ProblemID is used
-	ProblemID uint64 `json:"problemID"`
+	// If resource is part of problem, ProblemID and ProblemRevision are used
+	ProblemID       uint64 `json:"problemID"`
+	ProblemRevision uint64 `json:"problemRevision"`
 	// If resource is part of submit, SubmitID is used
 	SubmitID uint64 `json:"submitID"`
 	// If resource is a test, TestID should be specified
 	TestID uint64 `json:"testID"`
+	// If problem has several resources of same type, e.g. generators, Filename selects one of them
+	Filename string `json:"filename"`
 }
 
 type CacheGetter struct {
@@ -48,11 +51,35 @@ func (c *CacheGetter) Unlock(epoch int, vals ...uint64) error {
 	return c.Cache.Unlock(c.keyGen(epoch, vals...))
 }
 
+// GetNamed, LockNamed and UnlockNamed are used for resources that are selected by storage filename
+func (c *CacheGetter) GetNamed(epoch int, name string, vals ...uint64) (*string, error) {
+	return c.Cache.Get(c.namedKeyGen(epoch, name, vals))
+}
+
+func (c *CacheGetter) LockNamed(epoch int, name string, vals ...uint64) {
+	c.Cache.Lock(c.namedKeyGen(epoch, name, vals))
+}
+
+func (c *CacheGetter) UnlockNamed(epoch int, name string, vals ...uint64) error {
+	return c.Cache.Unlock(c.namedKeyGen(epoch, name, vals))
+}
+
+func (c *CacheGetter) namedKeyGen(epoch int, name string, vals []uint64) cacheKey {
+	key := c.keyGen(epoch, vals...)
+	key.Filename = name
+	return key
+}
+
 // Insert can be used only for testing
 func (c *CacheGetter) Insert(epoch int, file string, vals ...uint64) error {
 	return c.Cache.Insert(c.keyGen(epoch, vals...), &file, 1)
 }
 
+// InsertNamed can be used only for testing
+func (c *CacheGetter) InsertNamed(epoch int, file string, name string, vals ...uint64) error {
+	return c.Cache.Insert(c.namedKeyGen(epoch, name, vals), &file, 1)
+}
+
 func newSourceCache(commonCache *commonCache) *CacheGetter {
 	return &CacheGetter{
 		Cache: commonCache,
@@ -84,7 +111,34 @@ func newInteractorCache(commonCache *commonCache) *CacheGetter {
 	return &CacheGetter{
 		Cache: commonCache,
 		keyGen: func(epoch int, vals ...uint64) cacheKey {
-			return submitKeyGen(epoch, resource.Interactor, vals)
+			return problemIDKeyGen(epoch, resource.Interactor, vals)
+		},
+	}
+}
+
+func newGeneratorCache(commonCache *commonCache) *CacheGetter {
+	return &CacheGetter{
+		Cache: commonCache,
+		keyGen: func(epoch int, vals ...uint64) cacheKey {
+			return problemIDKeyGen(epoch, resource.Generator, vals)
+		},
+	}
+}
+
+func newValidatorCache(commonCache *commonCache) *CacheGetter {
+	return &CacheGetter{
+		Cache: commonCache,
+		keyGen: func(epoch int, vals ...uint64) cacheKey {
+			return problemIDKeyGen(epoch, resource.Validator, vals)
+		},
+	}
+}
+
+func newGraderCache(commonCache *commonCache) *CacheGetter {
+	return &CacheGetter{
+		Cache: commonCache,
+		keyGen: func(epoch int, vals ...uint64) cacheKey {
+			return problemIDKeyGen(epoch, resource.Grader, vals)
 		},
 	}
 }
@@ -104,15 +158,16 @@ func newTestAnswerCache(commonCache *commonCache) *CacheGetter {
 }
 
 func problemIDKeyGen(epoch int, resource resource.Type, vals []uint64) cacheKey {
-	if len(vals) != 1 {
+	if len(vals) != 2 {
 		logger.PanicLevel(3,
-			"wrong usage of storage cache, can not get problem %s for id %v, too many ids passed",
+			"wrong usage of storage cache, can not get problem %s for ids %v, problem id and revision should be passed",
 			resource.String(), vals)
 	}
 	key := cacheKey{
-		Epoch:     epoch,
-		Resource:  resource,
-		ProblemID: vals[0],
+		Epoch:           epoch,
+		Resource:        resource,
+		ProblemID:       vals[0],
+		ProblemRevision: vals[1],
 	}
 	return key
 }
@@ -132,16 +187,17 @@ func submitKeyGen(epoch int, resource resource.Type, vals []uint64) cacheKey {
 }
 
 func testKeyGen(epoch int, resource resource.Type, vals []uint64) cacheKey {
-	if len(vals) != 2 {
+	if len(vals) != 3 {
 		logger.PanicLevel(3,
-			"wrong usage of storage cache, can not get problem test for ids %v, exactly 2 ids should be passed",
+			"wrong usage of storage cache, can not get problem test for ids %v, exactly 3 ids should be passed",
 			vals)
 	}
 	key := cacheKey{
-		Epoch:     epoch,
-		Resource:  resource,
-		ProblemID: vals[0],
-		TestID:    vals[1],
+		Epoch:           epoch,
+		Resource:        resource,
+		ProblemID:       vals[0],
+		ProblemRevision: vals[1],
+		TestID:          vals[2],
 	}
 	return key
 }
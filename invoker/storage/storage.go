@@ -24,6 +24,9 @@ type InvokerStorage struct {
 	Binary     *CacheGetter
 	Checker    *CacheGetter
 	Interactor *CacheGetter
+	Generator  *CacheGetter
+	Validator  *CacheGetter
+	Grader     *CacheGetter
 	TestInput  *CacheGetter
 	TestAnswer *CacheGetter
 
@@ -50,6 +53,9 @@ func NewInvokerStorage(ts *common.TestingSystem) *InvokerStorage {
 	s.Binary = newBinaryCache(s.cache)
 	s.Checker = newCheckerCache(s.cache)
 	s.Interactor = newInteractorCache(s.cache)
+	s.Generator = newGeneratorCache(s.cache)
+	s.Validator = newValidatorCache(s.cache)
+	s.Grader = newGraderCache(s.cache)
 	s.TestInput = newTestInputCache(s.cache)
 	s.TestAnswer = newTestAnswerCache(s.cache)
 	logger.Info("Created invoker storage")
@@ -70,10 +76,12 @@ func (s *InvokerStorage) GetEpoch() int {
 
 func (s *InvokerStorage) getFiles(key cacheKey) (*string, error, uint64) {
 	request := &storageconn.Request{
-		Resource:  key.Resource,
-		ProblemID: key.ProblemID,
-		SubmitID:  key.SubmitID,
-		TestID:    key.TestID,
+		Resource:        key.Resource,
+		ProblemID:       key.ProblemID,
+		ProblemRevision: key.ProblemRevision,
+		SubmitID:        key.SubmitID,
+		TestID:          key.TestID,
+		StorageFilename: key.Filename,
 	}
 	setRequestBaseFolder(request, filepath.Join(s.ts.Config.Invoker.CachePath, strconv.Itoa(key.Epoch)))
 	response := s.ts.StorageConn.Download(request)
@@ -92,10 +100,21 @@ func setRequestBaseFolder(request *storageconn.Request, parent string) {
 	switch request.Resource {
 	case resource.SourceCode, resource.CompiledBinary, resource.CompileOutput:
 		request.DownloadFolder = filepath.Join(request.DownloadFolder, strconv.FormatUint(request.SubmitID, 10))
-	case resource.Checker, resource.Interactor:
-		request.DownloadFolder = filepath.Join(request.DownloadFolder, strconv.FormatUint(request.ProblemID, 10))
+	case resource.Checker, resource.Interactor, resource.Validator:
+		request.DownloadFolder = filepath.Join(
+			request.DownloadFolder, fmt.Sprintf("%d-%d", request.ProblemID, request.ProblemRevision),
+		)
+	case resource.Generator, resource.Grader:
+		// Each generator and grader has its own folder, as folder is removed with cached file
+		request.DownloadFolder = filepath.Join(
+			request.DownloadFolder,
+			fmt.Sprintf("%d-%d", request.ProblemID, request.ProblemRevision),
+			request.StorageFilename,
+		)
 	case resource.TestInput, resource.TestAnswer:
-		request.DownloadFolder = filepath.Join(request.DownloadFolder, fmt.Sprintf("%d-%d", request.ProblemID, request.TestID))
+		request.DownloadFolder = filepath.Join(
+			request.DownloadFolder, fmt.Sprintf("%d-%d-%d", request.ProblemID, request.ProblemRevision, request.TestID),
+		)
 	default:
 		logger.Panic("Can not fill base folder for storageconn request of type %v", request.Resource)
 	}
@@ -13,8 +13,9 @@ const (
 	checkResultFileArg = "-appes"
 )
 
-func (i *Invoker) fullTestingPipeline(sandbox sandbox.ISandbox, job *Job) {
+func (i *Invoker) fullTestingPipeline(sandbox sandbox.ISandbox, interactorSandbox sandbox.ISandbox, job *Job) {
 	s := i.newPipelineState(sandbox, job)
+	s.interactorSandbox = interactorSandbox
 	s.test = new(pipelineTestData)
 	s.loggerData = fmt.Sprintf(
 		"test job: %s submission: %d problem %d test %d",
@@ -27,7 +28,12 @@ func (i *Invoker) fullTestingPipeline(sandbox sandbox.ISandbox, job *Job) {
 
 	logger.Trace("Starting testing for %s", s.loggerData)
 
-	err := s.testingProcessPipeline()
+	var err error
+	if job.problem.ProblemType == models.ProblemTypeOutputOnly {
+		err = s.outputOnlyProcessPipeline()
+	} else {
+		err = s.testingProcessPipeline()
+	}
 	if err != nil {
 		logger.Error("Error in %s error: %v", s.loggerData, err)
 		s.failJob("job %s error: %v", job.ID, err)
@@ -57,9 +63,12 @@ func (s *JobPipelineState) testingProcessPipeline() error {
 		return err
 	}
 
-	err = s.loadTestInput()
-	if err != nil {
-		return err
+	if !s.job.problem.Interactive {
+		// Interactive solution reads test only through interactor, so input is loaded just before checking
+		err = s.loadTestInput()
+		if err != nil {
+			return err
+		}
 	}
 
 	err = s.generateTestRunConfig()
@@ -67,17 +76,36 @@ func (s *JobPipelineState) testingProcessPipeline() error {
 		return err
 	}
 
-	err = s.executeTestRunCommand()
+	if s.job.problem.Interactive {
+		err = s.fullInteractionPipeline()
+	} else if s.job.problem.ProblemType == models.ProblemTypeTwoPhase {
+		err = s.fullTwoPhasePipeline()
+	} else {
+		err = s.executeTestRunCommand()
+	}
 	if err != nil {
 		return err
 	}
 
+	if s.test.runResult.Verdict == verdict.WR || s.test.runResult.Verdict == verdict.CF {
+		// Interactor has already decided verdict, so checker is not run
+		s.test.hasResources = true
+		return nil
+	}
+
 	if s.test.runResult.Verdict != verdict.OK {
 		s.test.hasResources = false
 		return nil
 	}
 	s.test.hasResources = true
 
+	if s.job.problem.Interactive {
+		err = s.loadTestInput()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.fullCheckPipeline()
 	if err != nil {
 		return err
@@ -91,14 +119,15 @@ func (s *JobPipelineState) generateTestRunConfig() error {
 	fillInTestRunConfigLimits(s.test.runConfig, s.job.problem)
 
 	s.test.runConfig.Command = solutionBinaryFile
-	s.test.runConfig.Stdin = &sandbox.IORedirect{FileName: testInputFile}
-	s.test.runConfig.Stdout = &sandbox.IORedirect{FileName: testOutputFile}
+	if !s.job.problem.Interactive {
+		// For interactive problems stdin and stdout are connected to interactor
+		s.test.runConfig.Stdin = &sandbox.IORedirect{FileName: testInputFile}
+		s.test.runConfig.Stdout = &sandbox.IORedirect{FileName: testOutputFile}
+	}
 	s.test.runConfig.Stderr = &sandbox.IORedirect{FileName: testErrorFile}
 
 	s.test.runConfig.Ctx = s.job.stopCtx
 
-	// TODO: support interactive problems
-
 	logger.Trace("Generated test run config for %s", s.loggerData)
 	return nil
 }
@@ -0,0 +1,19 @@
+#include <fstream>
+#include <iostream>
+#include <string>
+
+int main(int argc, char* argv[]) {
+  if (argc != 2) {
+    return 1;
+  }
+  int a;
+  std::cin >> a;
+  if (std::string(argv[1]) == "1") {
+    std::cout << a * 10 << std::endl;
+  } else if (std::ifstream("input.txt").good()) {
+    // The second run should not see test input
+    std::cout << 0 << std::endl;
+  } else {
+    std::cout << a / 10 + 1 << std::endl;
+  }
+}
@@ -0,0 +1,9 @@
+#include <iostream>
+#include <string>
+
+int main(int argc, char* argv[]) {
+  if (argc != 2 || std::string(argv[1]) == "1") {
+    return 1;
+  }
+  std::cout << 2 << std::endl;
+}
@@ -0,0 +1,14 @@
+#include <fstream>
+#include <iostream>
+
+int main() {
+  int a;
+  std::cin >> a;
+  // Solution should not see test input, and its own output file should be replaced by interactor output
+  std::ofstream("output.txt") << a - 1 << std::endl;
+  if (std::ifstream("input.txt").good()) {
+    std::cout << a - 1 << std::endl;
+  } else {
+    std::cout << a + 1 << std::endl;
+  }
+}
@@ -0,0 +1,23 @@
+#include <ctime>
+#include <iostream>
+#include <string>
+
+int main(int argc, char* argv[]) {
+  if (argc != 2) {
+    return 1;
+  }
+  int a;
+  std::cin >> a;
+  // Each phase fits in time limit, but both phases together do not
+  volatile long long res = 0;
+  while (clock() < CLOCKS_PER_SEC * 7 / 10) {
+    for (int i = 0; i < 1000000; i++) {
+      res += i;
+    }
+  }
+  if (std::string(argv[1]) == "1") {
+    std::cout << a * 10 << std::endl;
+  } else {
+    std::cout << a / 10 + 1 << std::endl;
+  }
+}
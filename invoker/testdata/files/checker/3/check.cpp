@@ -0,0 +1,15 @@
+#include <fstream>
+#include <iostream>
+
+// Kattis output validator: input answer feedback_dir < output
+int main(int argc, char *argv[]) {
+    std::ifstream ans(argv[2]);
+    std::ofstream judgeMessage(std::string(argv[3]) + "/judgemessage.txt");
+    long long expected, found;
+    ans >> expected;
+    if (!(std::cin >> found) || found != expected) {
+        judgeMessage << "expected " << expected << std::endl;
+        return 43;
+    }
+    return 42;
+}
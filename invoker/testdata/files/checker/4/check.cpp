@@ -0,0 +1,24 @@
+#include <cstdlib>
+#include <fstream>
+#include <iostream>
+
+// CMS checker: input answer output, score to stdout, message to stderr
+int main(int argc, char *argv[]) {
+    std::ifstream ans(argv[2]), out(argv[3]);
+    long long expected, found;
+    ans >> expected;
+    if (!(out >> found)) {
+        std::cout << 0 << std::endl;
+        std::cerr << "no output" << std::endl;
+    } else if (found == expected) {
+        std::cout << 1 << std::endl;
+        std::cerr << "ok" << std::endl;
+    } else if (std::llabs(found - expected) == 2) {
+        std::cout << 0.5 << std::endl;
+        std::cerr << "almost" << std::endl;
+    } else {
+        std::cout << 0 << std::endl;
+        std::cerr << "wrong" << std::endl;
+    }
+    return 0;
+}
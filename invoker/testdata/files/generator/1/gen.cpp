@@ -0,0 +1,9 @@
+#include <iostream>
+
+int main(int argc, char* argv[]) {
+  if (argc != 2) {
+    std::cerr << "expected one argument" << std::endl;
+    return 1;
+  }
+  std::cout << argv[1] << std::endl;
+}
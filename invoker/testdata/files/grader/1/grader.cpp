@@ -0,0 +1,8 @@
+#include <iostream>
+#include "grader.h"
+
+int main() {
+  int a;
+  std::cin >> a;
+  std::cout << solve(a) << std::endl;
+}
@@ -0,0 +1,15 @@
+#include "testlib.h"
+
+int main(int argc, char *argv[]) {
+    registerInteraction(argc, argv);
+
+    int a = inf.readInt();
+    std::cout << a << std::endl;
+
+    int b = ouf.readInt();
+    tout << b << std::endl;
+    if (b != a + 1) {
+        quitf(_wa, "expected %d, found %d", a + 1, b);
+    }
+    quitf(_ok, "answer is correct");
+}
@@ -0,0 +1,5 @@
+#include "grader.h"
+
+int solve(int a) {
+  return a + 1;
+}
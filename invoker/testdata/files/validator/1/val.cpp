@@ -0,0 +1,9 @@
+#include <iostream>
+
+int main() {
+  int a;
+  if (!(std::cin >> a) || a < 0) {
+    std::cerr << "a should be non-negative integer" << std::endl;
+    return 1;
+  }
+}
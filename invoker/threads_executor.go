@@ -143,8 +143,11 @@ func (i *Invoker) initializeSandboxThreads() {
 
 	for id := 1; id <= i.SandboxThreads.threadsCount; id++ {
 		s := i.newSandbox(id)
+		// Interactor sandboxes are placed after all solution sandboxes
+		interactorSandbox := i.newSandbox(id + i.SandboxThreads.threadsCount)
 
 		i.TS.AddDefer(s.Delete)
+		i.TS.AddDefer(interactorSandbox.Delete)
 
 		i.TS.AddProcess(func() {
 			i.SandboxThreads.runThread(id, func(job *Job) {
@@ -152,7 +155,9 @@ func (i *Invoker) initializeSandboxThreads() {
 				case invokerconn.CompileJob:
 					i.fullCompilationPipeline(s, job)
 				case invokerconn.TestJob:
-					i.fullTestingPipeline(s, job)
+					i.fullTestingPipeline(s, interactorSandbox, job)
+				case invokerconn.GenerateTestJob:
+					i.fullGenerateTestPipeline(s, job)
 				default:
 					logger.Panic("Unknown job type %d", job.Type)
 				}
@@ -0,0 +1,112 @@
+package invoker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/verdict"
+	"testing_system/invoker/sandbox"
+	"testing_system/lib/logger"
+)
+
+const (
+	firstPhaseArg  = "1"
+	secondPhaseArg = "2"
+)
+
+// fullTwoPhasePipeline runs solution twice, the first run output is passed to the second run as its input.
+// Time limit is shared by both runs, so the second run gets only time left after the first run.
+// It expects s.test.runConfig to be generated for usual run.
+// After it finishes, s.test.runResult contains the first run result if it failed, or the second run result
+// with statistics of both runs otherwise
+func (s *JobPipelineState) fullTwoPhasePipeline() error {
+	err := s.generateFirstPhaseRunConfig()
+	if err != nil {
+		return err
+	}
+
+	err = s.executeFirstPhaseRunCommand()
+	if err != nil {
+		return err
+	}
+
+	if s.test.firstRunResult.Verdict != verdict.OK {
+		s.test.runResult = s.test.firstRunResult
+		return nil
+	}
+	firstStatistics := s.test.firstRunResult.Statistics
+	if firstStatistics.Time >= s.test.runConfig.TimeLimit {
+		s.test.runResult = &sandbox.RunResult{Verdict: verdict.TL, Statistics: firstStatistics}
+		return nil
+	}
+
+	// During testing, the second run should see only the first run output. Test input is loaded back for checker.
+	// Generated input is not in storage yet, but main solution is trusted during test generation, so input is kept
+	hideInput := s.generate == nil
+	if hideInput {
+		err = os.Remove(filepath.Join(s.sandbox.Dir(), testInputFile))
+		if err != nil {
+			return fmt.Errorf("can not hide test input from second phase, error: %v", err)
+		}
+	}
+
+	s.test.runConfig.Args = []string{secondPhaseArg}
+	s.test.runConfig.Stdin = &sandbox.IORedirect{FileName: intermediateFile}
+	s.test.runConfig.TimeLimit -= firstStatistics.Time
+	if firstStatistics.WallTime < s.test.runConfig.WallTimeLimit {
+		s.test.runConfig.WallTimeLimit -= firstStatistics.WallTime
+	}
+	err = s.executeTestRunCommand()
+	if err != nil {
+		return err
+	}
+	s.test.runResult.Statistics = sumPhaseStatistics(firstStatistics, s.test.runResult.Statistics)
+
+	if hideInput {
+		return s.loadTestInput()
+	}
+	return nil
+}
+
+func (s *JobPipelineState) generateFirstPhaseRunConfig() error {
+	config := *s.test.runConfig
+	config.Args = []string{firstPhaseArg}
+	config.Stdout = &sandbox.IORedirect{FileName: intermediateFile}
+	config.Stderr = &sandbox.IORedirect{FileName: firstPhaseErrorFile}
+	s.test.firstRunConfig = &config
+
+	logger.Trace("Generated first phase run config for %s", s.loggerData)
+	return nil
+}
+
+func (s *JobPipelineState) executeFirstPhaseRunCommand() error {
+	s.executeWaitGroup.Add(1)
+	err := s.runProcess(func() {
+		s.test.firstRunResult = s.sandbox.Run(s.test.firstRunConfig)
+		s.executeWaitGroup.Done()
+	})
+	if err != nil {
+		return fmt.Errorf("can not execute first phase command, error: %v", err)
+	}
+	s.executeWaitGroup.Wait()
+
+	if s.test.firstRunResult.Err != nil {
+		return fmt.Errorf("error while running first phase in sandbox, error: %v", s.test.firstRunResult.Err)
+	}
+	logger.Trace("Finished first phase run for %s with verdict %s", s.loggerData, s.test.firstRunResult.Verdict)
+	return nil
+}
+
+// sumPhaseStatistics returns time of both runs and max memory of them, exit code is taken from the second run
+func sumPhaseStatistics(first, second *masterconn.JobResultStatistics) *masterconn.JobResultStatistics {
+	if first == nil || second == nil {
+		return second
+	}
+	return &masterconn.JobResultStatistics{
+		Time:     first.Time + second.Time,
+		Memory:   max(first.Memory, second.Memory),
+		WallTime: first.WallTime + second.WallTime,
+		ExitCode: second.ExitCode,
+	}
+}
@@ -0,0 +1,321 @@
+package connector
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	TimestampHeader = "X-TS-Timestamp"
+	NonceHeader     = "X-TS-Nonce"
+	SignatureHeader = "X-TS-Signature"
+	// ContentHashHeader is hex SHA-256 of request body, it is signed instead of the body itself
+	ContentHashHeader = "Content-SHA256"
+
+	DefaultMaxClockSkew = 5 * time.Minute
+)
+
+// ErrBodyHashMismatch is returned when signed request body is read and it does not match signed hash
+var ErrBodyHashMismatch = errors.New("request body does not match signed hash")
+
+// AuthConfig configures authentication of outgoing requests of a single connection
+type AuthConfig struct {
+	// Secret is used to sign requests with HMAC-SHA256, it should be in Secrets of the receiving server.
+	// Signature covers method, path with query, body hash, time and unique nonce of request, so signed request
+	// can not be modified or replayed. Use TLS if request bodies should be hidden
+	Secret string `yaml:"Secret,omitempty"`
+
+	// TLS enables client certificates, connection address should use https scheme
+	TLS *ClientTLSConfig `yaml:"TLS,omitempty"`
+}
+
+type ClientTLSConfig struct {
+	// CAFile is used to verify server certificate, system pool is used if it is empty
+	CAFile     string `yaml:"CAFile,omitempty"`
+	CertFile   string `yaml:"CertFile"`
+	KeyFile    string `yaml:"KeyFile"`
+	ServerName string `yaml:"ServerName,omitempty"`
+}
+
+// HandlerAuthConfig configures authentication of incoming requests to handlers of single component
+type HandlerAuthConfig struct {
+	// Secrets lists accepted request signing secrets, several secrets can be used for rotation
+	Secrets []string `yaml:"Secrets,omitempty"`
+	// MaxClockSkew is maximal difference between request timestamp and server time, DefaultMaxClockSkew by default
+	MaxClockSkew time.Duration `yaml:"MaxClockSkew,omitempty"`
+}
+
+// ServerAuthConfig configures authentication of incoming requests to server
+type ServerAuthConfig struct {
+	HandlerAuthConfig `yaml:",inline"`
+
+	// TLS enables https server, client certificates are required if ClientCAFile is set
+	TLS *ServerTLSConfig `yaml:"TLS,omitempty"`
+}
+
+type ServerTLSConfig struct {
+	CertFile     string `yaml:"CertFile"`
+	KeyFile      string `yaml:"KeyFile"`
+	ClientCAFile string `yaml:"ClientCAFile,omitempty"`
+}
+
+// SetupClientAuth makes client sign all requests and use client certificates according to config
+func SetupClientAuth(client *resty.Client, config *AuthConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.TLS != nil {
+		tlsConfig, err := newClientTLSConfig(config.TLS)
+		if err != nil {
+			return err
+		}
+		client.SetTLSClientConfig(tlsConfig)
+	}
+	if config.Secret != "" {
+		secret := []byte(config.Secret)
+		client.SetPreRequestHook(func(_ *resty.Client, r *http.Request) error {
+			bodyHash, err := requestBodyHash(r)
+			if err != nil {
+				return fmt.Errorf("can not hash request body, error: %v", err)
+			}
+			nonce, err := newNonce()
+			if err != nil {
+				return fmt.Errorf("can not generate request nonce, error: %v", err)
+			}
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			r.Header.Set(TimestampHeader, timestamp)
+			r.Header.Set(NonceHeader, nonce)
+			r.Header.Set(ContentHashHeader, bodyHash)
+			r.Header.Set(SignatureHeader, sign(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, bodyHash))
+			return nil
+		})
+	}
+	return nil
+}
+
+// AuthMiddleware rejects requests that are not signed with any of handler secrets.
+// Body is checked against signed hash while handler reads it, so reading fails with ErrBodyHashMismatch
+// when modified body is read till the end. If no secrets are configured, all requests are accepted
+func AuthMiddleware(config *HandlerAuthConfig) gin.HandlerFunc {
+	if config == nil || len(config.Secrets) == 0 {
+		return func(c *gin.Context) {}
+	}
+	secrets := make([][]byte, 0, len(config.Secrets))
+	for _, secret := range config.Secrets {
+		secrets = append(secrets, []byte(secret))
+	}
+	maxClockSkew := config.MaxClockSkew
+	if maxClockSkew == 0 {
+		maxClockSkew = DefaultMaxClockSkew
+	}
+	nonces := newNonceCache()
+
+	return func(c *gin.Context) {
+		timestamp := c.GetHeader(TimestampHeader)
+		nonce := c.GetHeader(NonceHeader)
+		signature := c.GetHeader(SignatureHeader)
+		bodyHash := c.GetHeader(ContentHashHeader)
+		if timestamp == "" || nonce == "" || signature == "" || bodyHash == "" {
+			RespErr(c, http.StatusUnauthorized, "request is not signed")
+			c.Abort()
+			return
+		}
+
+		unixTime, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			RespErr(c, http.StatusUnauthorized, "invalid request timestamp")
+			c.Abort()
+			return
+		}
+		skew := time.Since(time.Unix(unixTime, 0))
+		if skew > maxClockSkew || skew < -maxClockSkew {
+			RespErr(c, http.StatusUnauthorized, "request timestamp is too far from server time")
+			c.Abort()
+			return
+		}
+
+		for _, secret := range secrets {
+			expected := sign(secret, c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, bodyHash)
+			if !hmac.Equal([]byte(expected), []byte(signature)) {
+				continue
+			}
+			// Request can not be replayed after nonce expires, because its timestamp is checked too
+			if !nonces.add(nonce, time.Unix(unixTime, 0).Add(maxClockSkew)) {
+				RespErr(c, http.StatusUnauthorized, "request is replayed")
+				c.Abort()
+				return
+			}
+			if c.Request.Body != nil {
+				c.Request.Body = &hashVerifyingReader{body: c.Request.Body, hash: sha256.New(), expected: bodyHash}
+			}
+			return
+		}
+		RespErr(c, http.StatusUnauthorized, "invalid request signature")
+		c.Abort()
+	}
+}
+
+// nonceCache stores nonces of accepted requests until request timestamps become too old
+type nonceCache struct {
+	mutex     sync.Mutex
+	expires   map[string]time.Time
+	nextPurge time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{expires: make(map[string]time.Time)}
+}
+
+// add returns false if nonce is already used by not expired request
+func (n *nonceCache) add(nonce string, expire time.Time) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now()
+	if now.After(n.nextPurge) {
+		for usedNonce, usedExpire := range n.expires {
+			if now.After(usedExpire) {
+				delete(n.expires, usedNonce)
+			}
+		}
+		n.nextPurge = now.Add(time.Minute)
+	}
+
+	if usedExpire, ok := n.expires[nonce]; ok && !now.After(usedExpire) {
+		return false
+	}
+	n.expires[nonce] = expire
+	return true
+}
+
+func newNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// hashVerifyingReader hashes request body while it is read and fails at its end if body does not match
+// signed hash, so large bodies, e.g. storage uploads, are not kept in memory to check signature
+type hashVerifyingReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (r *hashVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		return n, ErrBodyHashMismatch
+	}
+	return n, err
+}
+
+func (r *hashVerifyingReader) Close() error {
+	return r.body.Close()
+}
+
+// requestBodyHash returns hex SHA-256 of request body, body can still be read after it
+func requestBodyHash(r *http.Request) (string, error) {
+	hash := sha256.New()
+	switch {
+	case r.Body == nil || r.Body == http.NoBody:
+	case r.GetBody != nil:
+		body, err := r.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err = io.Copy(hash, body); err != nil {
+			return "", err
+		}
+	default:
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// NewServerTLSConfig creates tls config for server, it returns nil if tls is not configured
+func NewServerTLSConfig(config *ServerAuthConfig) (*tls.Config, error) {
+	if config == nil || config.TLS == nil {
+		return nil, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(config.TLS.CertFile, config.TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can not load server certificate, error: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if config.TLS.ClientCAFile != "" {
+		tlsConfig.ClientCAs, err = loadCertPool(config.TLS.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func newClientTLSConfig(config *ClientTLSConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can not load client certificate, error: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ServerName:   config.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if config.CAFile != "" {
+		tlsConfig.RootCAs, err = loadCertPool(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can not read CA file %s, error: %v", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+	return pool, nil
+}
+
+func sign(secret []byte, method string, requestURI string, timestamp string, nonce string, bodyHash string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n" + bodyHash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
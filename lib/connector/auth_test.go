@@ -0,0 +1,87 @@
+package connector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSigning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/open", func(c *gin.Context) { RespOK(c, nil) })
+	protected := router.Group("/protected", AuthMiddleware(&HandlerAuthConfig{Secrets: []string{"old", "new"}}))
+	protected.GET("/get", func(c *gin.Context) { RespOK(c, c.Query("value")) })
+	protected.POST("/post/:id", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			RespErr(c, http.StatusBadRequest, "%v", err)
+			return
+		}
+		RespOK(c, c.Param("id"))
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	newClient := func(config *AuthConfig) *resty.Client {
+		client := resty.New().SetBaseURL(server.URL)
+		require.NoError(t, SetupClientAuth(client, config))
+		return client
+	}
+
+	client := newClient(&AuthConfig{Secret: "new"})
+	value, err := Receive[string](client.R().SetQueryParam("value", "a b&c"), "/protected/get", http.MethodGet)
+	require.NoError(t, err)
+	require.Equal(t, "a b&c", *value)
+	id, err := Receive[string](client.R().SetPathParam("id", "7").SetBody("body"), "/protected/post/{id}", http.MethodPost)
+	require.NoError(t, err)
+	require.Equal(t, "7", *id)
+
+	_, err = Receive[string](newClient(&AuthConfig{Secret: "old"}).R(), "/protected/get", http.MethodGet)
+	require.NoError(t, err)
+
+	for _, config := range []*AuthConfig{nil, {Secret: "wrong"}} {
+		_, err = Receive[string](newClient(config).R(), "/protected/get", http.MethodGet)
+		var connectorErr *Error
+		require.ErrorAs(t, err, &connectorErr)
+		require.Equal(t, http.StatusUnauthorized, connectorErr.Code)
+	}
+	require.NoError(t, ReceiveEmpty(newClient(nil).R(), "/open", http.MethodGet))
+
+	signedRequest := func(timestamp string, nonce string, signedBody string, body string) *resty.Response {
+		hash := sha256.Sum256([]byte(signedBody))
+		bodyHash := hex.EncodeToString(hash[:])
+		signature := sign([]byte("new"), http.MethodPost, "/protected/post/1", timestamp, nonce, bodyHash)
+		resp, err := resty.New().R().
+			SetHeader(TimestampHeader, timestamp).
+			SetHeader(NonceHeader, nonce).
+			SetHeader(ContentHashHeader, bodyHash).
+			SetHeader(SignatureHeader, signature).
+			SetBody(body).
+			Post(server.URL + "/protected/post/1")
+		require.NoError(t, err)
+		return resp
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// Modified body can not be read by handler
+	resp := signedRequest(now, "nonce1", "body", "other")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	require.Contains(t, resp.String(), ErrBodyHashMismatch.Error())
+
+	// The same request can not be sent twice
+	require.Equal(t, http.StatusOK, signedRequest(now, "nonce2", "body", "body").StatusCode())
+	require.Equal(t, http.StatusUnauthorized, signedRequest(now, "nonce2", "body", "body").StatusCode())
+
+	// Replayed request with old timestamp is rejected
+	require.Equal(t, http.StatusUnauthorized, signedRequest("1000", "nonce3", "body", "body").StatusCode())
+}
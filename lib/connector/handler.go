@@ -3,6 +3,7 @@ package connector
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,3 +31,16 @@ func RespErr(c *gin.Context, code int, errf string, values ...interface{}) {
 		Error: fmt.Sprintf(errf, values...),
 	})
 }
+
+// ParseOptionalID parses optional id request parameter, empty value is parsed as nil
+func ParseOptionalID(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, err
+	}
+	result := uint(id)
+	return &result, nil
+}
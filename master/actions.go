@@ -1,15 +1,27 @@
 package master
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing_system/common/connectors/masterconn"
 	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
 	"testing_system/common/constants/resource"
 	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/lib/logger"
+	"time"
 
 	"github.com/cenkalti/backoff/v5"
 	"github.com/gin-gonic/gin"
@@ -54,19 +66,107 @@ func (m *Master) loadProblem(c *gin.Context, problemID uint) *models.Problem {
 	return nil
 }
 
-func (m *Master) saveSubmissionInStorage(c *gin.Context, submission *models.Submission, file *multipart.FileHeader) bool {
+// sourceArchiveName is storage name of source that is packed from several submitted files
+const sourceArchiveName = "source.zip"
+
+// submissionSource is submitted source that is stored as a single file
+type submissionSource struct {
+	name    string
+	data    []byte
+	archive bool
+}
+
+// readSubmissionSource reads submitted source files. Several files are packed to zip archive, single zip file
+// is also treated as archive. Archives are unpacked by invoker before compilation
+func readSubmissionSource(files []*multipart.FileHeader) (*submissionSource, error) {
+	if len(files) == 1 {
+		data, err := readFormFile(files[0])
+		if err != nil {
+			return nil, err
+		}
+		return &submissionSource{
+			name:    files[0].Filename,
+			data:    data,
+			archive: strings.EqualFold(filepath.Ext(files[0].Filename), ".zip"),
+		}, nil
+	}
+
+	// Files are sorted, so same files always give same archive and source hash
+	files = slices.SortedFunc(slices.Values(files), func(a, b *multipart.FileHeader) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	for i, file := range files {
+		if i > 0 && files[i-1].Filename == file.Filename {
+			return nil, fmt.Errorf("file %s is submitted twice", file.Filename)
+		}
+		data, err := readFormFile(file)
+		if err != nil {
+			return nil, err
+		}
+		writer, err := archive.Create(file.Filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = writer.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return &submissionSource{name: sourceArchiveName, data: buf.Bytes(), archive: true}, nil
+}
+
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
 	reader, err := file.Open()
 	if err != nil {
-		c.String(http.StatusBadRequest, "failed to read source code")
-		return false
+		return nil, err
 	}
 	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func hashSubmissionSource(source *submissionSource) string {
+	hash := sha256.Sum256(source.data)
+	return hex.EncodeToString(hash[:])
+}
+
+// findCachedSubmission finds finished submission with same source and language that was tested
+// against current problem revision. Nil is returned if there is no such submission
+func (m *Master) findCachedSubmission(
+	c *gin.Context,
+	problem *models.Problem,
+	submission *models.Submission,
+) (*models.Submission, bool) {
+	cached := new(models.Submission)
+	err := m.ts.DB.WithContext(c).
+		Where("problem_id = ? AND problem_revision = ?", problem.ID, problem.Revision).
+		Where("language = ? AND source_hash = ?", submission.Language, submission.SourceHash).
+		Where("source_archive = ?", submission.SourceArchive).
+		Where("verdict NOT IN ?", []verdict.Verdict{verdict.RU, verdict.CF, verdict.CL}).
+		Order("id DESC").
+		First(cached).
+		Error
+	if err == nil {
+		return cached, true
+	}
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, true
+	}
+	logger.Error("failed to find cached submission in db, error: %s", err.Error())
+	c.String(http.StatusInternalServerError, "internal server error")
+	return nil, false
+}
+
+func (m *Master) saveSubmissionInStorage(c *gin.Context, submission *models.Submission, source *submissionSource) bool {
 	request := &storageconn.Request{
 		Resource:        resource.SourceCode,
 		SubmitID:        uint64(submission.ID),
-		StorageFilename: file.Filename,
-		File:            reader,
+		StorageFilename: source.name,
+		File:            bytes.NewReader(source.data),
 		Ctx:             c,
 	}
 
@@ -79,20 +179,71 @@ func (m *Master) saveSubmissionInStorage(c *gin.Context, submission *models.Subm
 	return true
 }
 
-func (m *Master) saveSubmissionInDB(c *gin.Context, problemID uint, language string) *models.Submission {
-	submission := &models.Submission{
-		ProblemID: problemID,
-		Language:  language,
-		Verdict:   verdict.RU,
-	}
+func (m *Master) saveSubmissionInDB(c *gin.Context, submission *models.Submission) bool {
+	submission.Verdict = verdict.RU
 
 	if err := m.ts.DB.WithContext(c).Save(submission).Error; err != nil {
 		logger.Error("failed to save submission to db, error: %s", err.Error())
 		c.String(http.StatusInternalServerError, "internal server error")
-		return nil
+		return false
+	}
+
+	return true
+}
+
+func (m *Master) loadSubmission(c *gin.Context, submissionID uint) *models.Submission {
+	submission := new(models.Submission)
+	err := m.ts.DB.WithContext(c.Request.Context()).First(submission, submissionID).Error
+	if err == nil {
+		return submission
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "Submission not found")
+	} else {
+		logger.Error("failed to find submission in db, error: %s", err.Error())
+		c.String(http.StatusInternalServerError, "internal server error")
+	}
+	return nil
+}
+
+func (m *Master) checkUserExists(c *gin.Context, userID uint) bool {
+	err := m.ts.DB.WithContext(c).First(new(models.User), userID).Error
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "User not found")
+	} else {
+		logger.Error("failed to find user in db, error: %s", err.Error())
+		c.String(http.StatusInternalServerError, "internal server error")
 	}
+	return false
+}
 
-	return submission
+func (m *Master) checkContestSubmission(c *gin.Context, contestID uint, problemID uint) bool {
+	contest := new(models.Contest)
+	err := m.ts.DB.WithContext(c).Preload("Problems").First(contest, contestID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.String(http.StatusNotFound, "Contest not found")
+		} else {
+			logger.Error("failed to find contest in db, error: %s", err.Error())
+			c.String(http.StatusInternalServerError, "internal server error")
+		}
+		return false
+	}
+
+	if !contest.HasProblem(problemID) {
+		c.String(http.StatusBadRequest, "Problem %d is not in contest %d", problemID, contestID)
+		return false
+	}
+	if !contest.IsRunning(time.Now()) {
+		c.String(http.StatusBadRequest, "Contest %d is not running", contestID)
+		return false
+	}
+	return true
 }
 
 func (m *Master) removeSubmissionFromDB(ctx context.Context, submission *models.Submission) error {
@@ -124,7 +275,121 @@ func (m *Master) finishSubmissionTesting(ctx context.Context, submission *models
 		return err
 	}
 	// We remove submission from status only after result is uploaded to database
-	m.queue.Status().FinishSubmissionTesting(submission.ID)
+	m.queue.Status().FinishSubmissionTesting(submission)
 	m.ts.Metrics.MasterQueueSize.Sub(1)
+	m.sendWebhooks(submission)
+	return nil
+}
+
+// setSubmissionFailed sets check failed verdict to submission that can not be tested
+func setSubmissionFailed(submission *models.Submission, reason string) {
+	submission.Verdict = verdict.CF
+	submission.CompilationResult = &models.TestResult{
+		Verdict: verdict.CF,
+		Error:   reason,
+	}
+}
+
+// failSubmission saves submission that can not be tested with check failed verdict
+func (m *Master) failSubmission(submission *models.Submission, reason string) {
+	setSubmissionFailed(submission, reason)
+	m.retryUntilOK(func(ctx context.Context, submission *models.Submission) error {
+		if err := m.ts.DB.WithContext(ctx).Save(submission).Error; err != nil {
+			logger.Error("failed to save failed submission %d to db, error: %v", submission.ID, err)
+			return err
+		}
+		return nil
+	}, submission)
+}
+
+func (m *Master) findSubmissionsForRejudge(c *gin.Context, request *masterconn.RejudgeRequest) []*models.Submission {
+	query := m.ts.DB.WithContext(c).Model(&models.Submission{}).
+		Where("verdict <> ?", verdict.RU).
+		Where("invocation_id IS NULL").
+		Where("generates_tests = ?", false)
+	if request.SubmissionID != nil {
+		query = query.Where("id = ?", *request.SubmissionID)
+	}
+	if request.ProblemID != nil {
+		query = query.Where("problem_id = ?", *request.ProblemID)
+	}
+	if request.Language != nil {
+		query = query.Where("language = ?", *request.Language)
+	}
+	if len(request.Verdicts) > 0 {
+		query = query.Where("verdict IN ?", request.Verdicts)
+	}
+	if request.FromSubmissionID != nil {
+		query = query.Where("id >= ?", *request.FromSubmissionID)
+	}
+	if request.ToSubmissionID != nil {
+		query = query.Where("id <= ?", *request.ToSubmissionID)
+	}
+
+	submissions := make([]*models.Submission, 0)
+	if err := query.Order("id").Find(&submissions).Error; err != nil {
+		logger.Error("failed to find submissions for rejudge, error: %s", err.Error())
+		c.String(http.StatusInternalServerError, "internal server error")
+		return nil
+	}
+	return submissions
+}
+
+// rejudgeSubmission resets results of submission claimed in queue status and submits it to queue again.
+// Claim is released if submission can not be rejudged
+func (m *Master) rejudgeSubmission(
+	c *gin.Context,
+	problem *models.Problem,
+	submission *models.Submission,
+	rejudgePriority priority.Priority,
+) error {
+	oldSubmission := *submission
+	history := models.NewSubmissionHistory(submission)
+	submission.ResetResults()
+	submission.ProblemRevision = problem.Revision
+
+	err := m.ts.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		return tx.Save(submission).Error
+	})
+	if err != nil {
+		m.queue.Status().ReleaseSubmission(submission.ID)
+		return fmt.Errorf("can not reset submission results, error: %v", err)
+	}
+
+	// Rejudge priority is used only for scheduling, submission keeps its own priority
+	if err = m.queue.SubmitWithPriority(problem, submission, rejudgePriority); err != nil {
+		// Submission stays claimed until old results are restored, so it can not be rejudged meanwhile
+		m.retryUntilOK(func(ctx context.Context, _ *models.Submission) error {
+			if err := m.restoreSubmissionResults(ctx, &oldSubmission, history); err != nil {
+				return err
+			}
+			m.queue.Status().ReleaseSubmission(submission.ID)
+			return nil
+		}, submission)
+		return fmt.Errorf("can not submit submission to queue, error: %v", err)
+	}
+	m.ts.Metrics.MasterQueueSize.Inc()
+	logger.Trace("rejudge submission, id: %d, problem: %d, old verdict: %s", submission.ID, problem.ID, oldSubmission.Verdict)
+	return nil
+}
+
+func (m *Master) restoreSubmissionResults(
+	ctx context.Context,
+	submission *models.Submission,
+	history *models.SubmissionHistory,
+) error {
+	err := m.ts.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(history).Error; err != nil {
+			return err
+		}
+		return tx.Save(submission).Error
+	})
+	if err != nil {
+		logger.Error("failed to restore submission %d results, error: %v", submission.ID, err)
+		return err
+	}
 	return nil
 }
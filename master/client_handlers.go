@@ -2,10 +2,16 @@ package master
 
 import (
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
 	"strconv"
 	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/lib/connector"
 	"testing_system/lib/logger"
+	"time"
 )
 
 // @Summary Submit
@@ -15,7 +21,11 @@ import (
 // @Produce json
 // @Param ProblemID formData uint true "Problem ID" example:"228"
 // @Param Language formData string true "Programming language" example:"g++"
-// @Param Solution formData file true "Source code"
+// @Param Priority formData string false "Submission priority, contest by default" example:"practice"
+// @Param UserID formData uint false "Submission author ID" example:"1"
+// @Param ContestID formData uint false "Contest ID, problem should be in contest and contest should be running" example:"1"
+// @Param ForceRun formData bool false "Test submission even if identical source was already tested" example:"true"
+// @Param Solution formData file true "Source code, several files or a single zip file are unpacked before compilation"
 // @Success 200 {object} masterconn.SubmissionResponse
 // @Failure 400 {object} string
 // @Failure 404 {object} string
@@ -24,6 +34,7 @@ import (
 func (m *Master) handleNewSubmission(c *gin.Context) {
 	problemIDStr := c.PostForm("ProblemID")
 	language := c.PostForm("Language")
+	submissionPriority := priority.Priority(c.DefaultPostForm("Priority", string(priority.Default)))
 
 	problemID, err := strconv.ParseUint(problemIDStr, 10, 0)
 	if err != nil {
@@ -31,27 +42,98 @@ func (m *Master) handleNewSubmission(c *gin.Context) {
 		return
 	}
 
-	file, err := c.FormFile("Solution")
-	if err != nil {
+	if submissionPriority == "" {
+		submissionPriority = priority.Default
+	}
+	if !submissionPriority.IsValid() {
+		c.String(http.StatusBadRequest, "Unknown priority %s", submissionPriority)
+		return
+	}
+
+	userID, ok := parseOptionalID(c, "UserID", c.PostForm("UserID"))
+	if !ok {
+		return
+	}
+	contestID, ok := parseOptionalID(c, "ContestID", c.PostForm("ContestID"))
+	if !ok {
+		return
+	}
+
+	forceRun := false
+	if forceRunStr := c.PostForm("ForceRun"); forceRunStr != "" {
+		forceRun, err = strconv.ParseBool(forceRunStr)
+		if err != nil {
+			c.String(http.StatusBadRequest, "ForceRun is not bool")
+			return
+		}
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["Solution"]) == 0 {
 		c.String(http.StatusBadRequest, "No source code")
 		return
 	}
+	source, err := readSubmissionSource(form.File["Solution"])
+	if err != nil {
+		c.String(http.StatusBadRequest, "failed to read source code, error: %v", err)
+		return
+	}
 
 	problem := m.loadProblem(c, uint(problemID))
 	if problem == nil {
 		return
 	}
 
-	submission := m.saveSubmissionInDB(c, uint(problemID), language)
-	if submission == nil {
+	if userID != nil && !m.checkUserExists(c, *userID) {
+		return
+	}
+	if contestID != nil && !m.checkContestSubmission(c, *contestID, problem.ID) {
+		return
+	}
+
+	submission := &models.Submission{
+		ProblemID:       uint(problemID),
+		ProblemRevision: problem.Revision,
+		Language:        language,
+		Priority:        submissionPriority,
+		UserID:          userID,
+		ContestID:       contestID,
+		SourceHash:      hashSubmissionSource(source),
+		SourceArchive:   source.archive,
+	}
+
+	var cached *models.Submission
+	if !forceRun {
+		if cached, ok = m.findCachedSubmission(c, problem, submission); !ok {
+			return
+		}
+	}
+
+	if !m.saveSubmissionInDB(c, submission) {
 		return
 	}
 
-	if !m.saveSubmissionInStorage(c, submission, file) {
+	if !m.saveSubmissionInStorage(c, submission, source) {
 		m.retryUntilOK(m.removeSubmissionFromDB, submission)
 		return
 	}
 
+	if cached != nil {
+		submission.CopyResults(cached)
+		if err = m.ts.DB.WithContext(c).Save(submission).Error; err != nil {
+			m.retryUntilOK(m.removeSubmissionFromDB, submission)
+			m.retryUntilOK(m.removeSubmissionFromStorage, submission)
+
+			logger.Error("failed to save cached results of submission %d, error: %s", submission.ID, err.Error())
+			c.String(http.StatusInternalServerError, "internal server error")
+			return
+		}
+		logger.Trace("new submission, id: %d, results are copied from submission %d", submission.ID, *submission.CachedFromID)
+		m.sendWebhooks(submission)
+		c.JSON(http.StatusOK, masterconn.SubmissionResponse{SubmissionID: submission.ID})
+		return
+	}
+
 	logger.Trace("new submission, id: %d, problem: %d, language: %s", submission.ID, problem.ID, language)
 
 	if err = m.queue.Submit(problem, submission); err != nil {
@@ -66,7 +148,121 @@ func (m *Master) handleNewSubmission(c *gin.Context) {
 
 	m.invokerRegistry.SendJobs()
 
-	c.JSON(http.StatusOK, masterconn.SubmissionResponse{submission.ID})
+	c.JSON(http.StatusOK, masterconn.SubmissionResponse{SubmissionID: submission.ID})
+}
+
+// @Summary Rejudge
+// @Description Rejudge submissions selected by submission id, problem id or filter. Old results are kept in submission history
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param request body masterconn.RejudgeRequest true "Rejudge filter"
+// @Success 200 {object} masterconn.RejudgeResponse
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/rejudge [post]
+func (m *Master) handleRejudge(c *gin.Context) {
+	var request masterconn.RejudgeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "Invalid rejudge request: %v", err)
+		return
+	}
+	if !request.HasFilter() {
+		c.String(http.StatusBadRequest, "No rejudge filter specified")
+		return
+	}
+	rejudgePriority := priority.Rejudge
+	if request.Priority != nil {
+		rejudgePriority = *request.Priority
+	}
+	if !rejudgePriority.IsValid() {
+		c.String(http.StatusBadRequest, "Unknown priority %s", rejudgePriority)
+		return
+	}
+
+	submissions := m.findSubmissionsForRejudge(c, &request)
+	if submissions == nil {
+		return
+	}
+
+	problems := make(map[uint]*models.Problem)
+	for _, submission := range submissions {
+		if _, ok := problems[submission.ProblemID]; ok {
+			continue
+		}
+		problem := m.loadProblem(c, submission.ProblemID)
+		if problem == nil {
+			return
+		}
+		problems[problem.ID] = problem
+	}
+
+	if request.ResetInvokerCache {
+		if err := m.invokerRegistry.ResetCache(); err != nil {
+			logger.Error("failed to reset invoker cache before rejudge, error: %v", err)
+			c.String(http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+
+	response := masterconn.RejudgeResponse{
+		SubmissionIDs:        make([]uint, 0),
+		SkippedSubmissionIDs: make([]uint, 0),
+		FailedSubmissionIDs:  make([]uint, 0),
+	}
+	for _, submission := range submissions {
+		// Claim is checked and taken at once, so concurrent rejudges can not reset the same submission twice
+		if !m.queue.Status().ClaimSubmission(submission.ID) {
+			response.SkippedSubmissionIDs = append(response.SkippedSubmissionIDs, submission.ID)
+			continue
+		}
+		err := m.rejudgeSubmission(c, problems[submission.ProblemID], submission, rejudgePriority)
+		if err != nil {
+			logger.Error("failed to rejudge submission %d, error: %v", submission.ID, err)
+			response.FailedSubmissionIDs = append(response.FailedSubmissionIDs, submission.ID)
+			continue
+		}
+		response.SubmissionIDs = append(response.SubmissionIDs, submission.ID)
+	}
+	logger.Trace(
+		"rejudging %d submissions, skipped %d, failed %d",
+		len(response.SubmissionIDs), len(response.SkippedSubmissionIDs), len(response.FailedSubmissionIDs),
+	)
+
+	m.invokerRegistry.SendJobs()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Cancel submission
+// @Description Stop testing of submission, it is saved with cancelled verdict and results of finished tests
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param request body masterconn.CancelRequest true "Submission to cancel"
+// @Success 200 {object} masterconn.SubmissionResponse
+// @Failure 400 {object} string
+// @Router /master/cancel [post]
+func (m *Master) handleCancelSubmission(c *gin.Context) {
+	var request masterconn.CancelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "Invalid cancel request: %v", err)
+		return
+	}
+
+	submission, err := m.invokerRegistry.CancelSubmission(request.SubmissionID)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Can not cancel submission %d: %v", request.SubmissionID, err)
+		return
+	}
+	m.queue.Status().UpdateSubmission(submission)
+	logger.Trace("submission #%d is cancelled, saving results to db", submission.ID)
+	m.retryUntilOK(m.finishSubmissionTesting, submission)
+
+	m.invokerRegistry.SendJobs()
+
+	c.JSON(http.StatusOK, masterconn.SubmissionResponse{SubmissionID: submission.ID})
 }
 
 // @Summary Status
@@ -100,3 +296,79 @@ func (m *Master) handleResetInvokerCache(c *gin.Context) {
 	}
 	c.String(http.StatusOK, "OK")
 }
+
+// parseOptionalID parses optional id from form or query value and responds with error if it is invalid
+func parseOptionalID(c *gin.Context, field string, value string) (*uint, bool) {
+	id, err := connector.ParseOptionalID(value)
+	if err != nil {
+		c.String(http.StatusBadRequest, "%s is not uint", field)
+		return nil, false
+	}
+	return id, true
+}
+
+// @Summary Submission events
+// @Description Stream of submission updates as server-sent events. Without SubmissionID all submissions are streamed.
+// @Description Current state of testing submissions is sent first, stream of single submission ends after it is finished
+// @Tags Client
+// @Produce text/event-stream
+// @Param SubmissionID query uint false "Submission ID" example:"1"
+// @Param UserID query uint false "Only events of submissions of this user are streamed" example:"1"
+// @Success 200 {object} masterconn.SubmissionEvent
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/events [get]
+func (m *Master) handleSubmissionEvents(c *gin.Context) {
+	submissionID, ok := parseOptionalID(c, "SubmissionID", c.Query("SubmissionID"))
+	if !ok {
+		return
+	}
+	userID, ok := parseOptionalID(c, "UserID", c.Query("UserID"))
+	if !ok {
+		return
+	}
+
+	events, unsubscribe := m.queue.Status().Subscribe(submissionID, userID)
+	defer unsubscribe()
+
+	if submissionID != nil && !m.queue.Status().HasSubmission(*submissionID) {
+		submission := m.loadSubmission(c, *submissionID)
+		if submission == nil {
+			return
+		}
+		if userID != nil && (submission.UserID == nil || *submission.UserID != *userID) {
+			c.String(http.StatusNotFound, "Submission not found")
+			return
+		}
+		if submission.Verdict != verdict.RU {
+			c.SSEvent(string(masterconn.SubmissionEventFinished), &masterconn.SubmissionEvent{
+				Type:       masterconn.SubmissionEventFinished,
+				Submission: submission,
+			})
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(masterconn.SubmissionEventsKeepAlive)
+	defer keepAlive.Stop()
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return submissionID == nil || event.Type != masterconn.SubmissionEventFinished
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		case <-m.ts.StopCtx.Done():
+			return false
+		}
+	})
+}
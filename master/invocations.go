@@ -0,0 +1,372 @@
+package master
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/lib/customfields"
+	"testing_system/lib/logger"
+	"time"
+)
+
+// @Summary New invocation
+// @Description Test problem reference solutions on all tests of current problem revision.
+// @Description Each solution is tested as separate submission with author priority
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param request body masterconn.InvocationRequest true "Problem and solutions"
+// @Success 200 {object} masterconn.InvocationResponse
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/invocation [post]
+func (m *Master) handleNewInvocation(c *gin.Context) {
+	var request masterconn.InvocationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "Invalid invocation request: %v", err)
+		return
+	}
+
+	problem := m.loadProblem(c, request.ProblemID)
+	if problem == nil {
+		return
+	}
+	solutions := selectInvocationSolutions(c, problem, request.Solutions)
+	if solutions == nil {
+		return
+	}
+
+	sources := make([][]byte, len(solutions))
+	for i, solution := range solutions {
+		resp := m.ts.StorageConn.Download(&storageconn.Request{
+			Resource:        resource.Solution,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: problem.Revision,
+			StorageFilename: solution.Name,
+			DownloadBytes:   true,
+			Ctx:             c,
+		})
+		if resp.Error != nil {
+			logger.Error("failed to load problem %d solution %s, error: %v", problem.ID, solution.Name, resp.Error)
+			c.String(http.StatusInternalServerError, "internal server error")
+			return
+		}
+		sources[i] = resp.RawData
+	}
+
+	invocation := &models.Invocation{
+		ProblemID:       problem.ID,
+		ProblemRevision: problem.Revision,
+	}
+	submissions := make([]*models.Submission, len(solutions))
+	err := m.ts.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(invocation).Error; err != nil {
+			return err
+		}
+		for i, solution := range solutions {
+			submissions[i] = &models.Submission{
+				ProblemID:       problem.ID,
+				ProblemRevision: problem.Revision,
+				Language:        solution.Language,
+				Priority:        priority.Author,
+				InvocationID:    &invocation.ID,
+				Verdict:         verdict.RU,
+			}
+			if err := tx.Create(submissions[i]).Error; err != nil {
+				return err
+			}
+			invocation.Solutions = append(invocation.Solutions, &models.InvocationSolution{
+				Name:         solution.Name,
+				Language:     solution.Language,
+				Tag:          solution.Tag,
+				SubmissionID: submissions[i].ID,
+			})
+		}
+		return tx.Save(invocation).Error
+	})
+	if err != nil {
+		logger.Error("failed to save invocation of problem %d to db, error: %v", problem.ID, err)
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	for i, submission := range submissions {
+		request := &storageconn.Request{
+			Resource:        resource.SourceCode,
+			SubmitID:        uint64(submission.ID),
+			StorageFilename: solutions[i].Name,
+			File:            bytes.NewReader(sources[i]),
+			Ctx:             c,
+		}
+		if err = m.ts.StorageConn.Upload(request).Error; err != nil {
+			logger.Error("failed to save invocation %d solution %s, error: %v", invocation.ID, solutions[i].Name, err)
+			m.failSubmission(submission, "failed to save solution source")
+			continue
+		}
+		if err = m.queue.Submit(problem, submission); err != nil {
+			logger.Error("failed to submit invocation %d solution %s, error: %v", invocation.ID, solutions[i].Name, err)
+			m.failSubmission(submission, err.Error())
+			continue
+		}
+		m.ts.Metrics.MasterQueueSize.Inc()
+	}
+	logger.Trace("new invocation, id: %d, problem: %d, solutions: %d", invocation.ID, problem.ID, len(solutions))
+
+	m.invokerRegistry.SendJobs()
+
+	c.JSON(http.StatusOK, masterconn.InvocationResponse{InvocationID: invocation.ID})
+}
+
+// @Summary Invocation
+// @Description Results of invocation solutions on each test. Results of testing solutions are taken from queue status
+// @Tags Client
+// @Produce json
+// @Param InvocationID query uint true "Invocation ID" example:"1"
+// @Success 200 {object} masterconn.InvocationResult
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/invocation [get]
+func (m *Master) handleGetInvocation(c *gin.Context) {
+	result := m.loadInvocationResult(c)
+	if result == nil {
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Suggest time limit
+// @Description Suggest problem time limit from finished invocation: max time of main and accepted solutions
+// @Description is multiplied by TimeLimitFactor from master config and rounded up to 100ms
+// @Tags Client
+// @Produce json
+// @Param InvocationID query uint true "Invocation ID" example:"1"
+// @Success 200 {object} masterconn.TimeLimitSuggestion
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/invocation/time_limit [get]
+func (m *Master) handleSuggestTimeLimit(c *gin.Context) {
+	result := m.loadInvocationResult(c)
+	if result == nil {
+		return
+	}
+	suggestion, err := suggestTimeLimit(result, m.ts.Config.Master.TimeLimitFactor)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Can not suggest time limit: %v", err)
+		return
+	}
+	c.JSON(http.StatusOK, suggestion)
+}
+
+func (m *Master) loadInvocationResult(c *gin.Context) *masterconn.InvocationResult {
+	invocationID, err := strconv.ParseUint(c.Query("InvocationID"), 10, 0)
+	if err != nil {
+		c.String(http.StatusBadRequest, "InvocationID is not uint")
+		return nil
+	}
+
+	invocation := new(models.Invocation)
+	err = m.ts.DB.WithContext(c).First(invocation, invocationID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.String(http.StatusNotFound, "Invocation not found")
+		} else {
+			logger.Error("failed to find invocation in db, error: %s", err.Error())
+			c.String(http.StatusInternalServerError, "internal server error")
+		}
+		return nil
+	}
+
+	var submissions []*models.Submission
+	err = m.ts.DB.WithContext(c).Where("invocation_id = ?", invocation.ID).Find(&submissions).Error
+	if err != nil {
+		logger.Error("failed to find invocation %d submissions in db, error: %s", invocation.ID, err.Error())
+		c.String(http.StatusInternalServerError, "internal server error")
+		return nil
+	}
+	submissionByID := make(map[uint]*models.Submission)
+	testingIDs := make(map[uint]struct{})
+	for _, submission := range submissions {
+		if testing := m.queue.Status().GetSubmission(submission.ID); testing != nil {
+			submission = testing
+			testingIDs[submission.ID] = struct{}{}
+		}
+		submissionByID[submission.ID] = submission
+	}
+
+	return buildInvocationResult(invocation, submissionByID, testingIDs)
+}
+
+func selectInvocationSolutions(c *gin.Context, problem *models.Problem, names []string) []*models.ProblemSolution {
+	solutions := make([]*models.ProblemSolution, 0)
+	if len(names) == 0 {
+		for _, solution := range problem.Solutions {
+			if solution.Tag != solutiontag.DoNotRun {
+				solutions = append(solutions, solution)
+			}
+		}
+	} else {
+		for _, name := range names {
+			solution := problem.FindSolution(name)
+			if solution == nil {
+				c.String(http.StatusNotFound, "Problem %d has no solution %s", problem.ID, name)
+				return nil
+			}
+			if !slices.Contains(solutions, solution) {
+				solutions = append(solutions, solution)
+			}
+		}
+	}
+	if len(solutions) == 0 {
+		c.String(http.StatusBadRequest, "Problem %d has no solutions to test", problem.ID)
+		return nil
+	}
+	return solutions
+}
+
+func buildInvocationResult(
+	invocation *models.Invocation,
+	submissionByID map[uint]*models.Submission,
+	testingIDs map[uint]struct{},
+) *masterconn.InvocationResult {
+	result := &masterconn.InvocationResult{
+		ID:              invocation.ID,
+		ProblemID:       invocation.ProblemID,
+		ProblemRevision: invocation.ProblemRevision,
+		Finished:        true,
+		Mismatched:      make([]string, 0),
+		Solutions:       make([]*masterconn.InvocationSolutionResult, 0, len(invocation.Solutions)),
+	}
+	for _, solution := range invocation.Solutions {
+		solutionResult := &masterconn.InvocationSolutionResult{
+			InvocationSolution: *solution,
+			Verdict:            verdict.RU,
+			TestResults:        make(models.TestResults, 0),
+		}
+		result.Solutions = append(result.Solutions, solutionResult)
+
+		submission, ok := submissionByID[solution.SubmissionID]
+		if !ok {
+			result.Finished = false
+			continue
+		}
+		solutionResult.Verdict = submission.Verdict
+		solutionResult.CompilationResult = submission.CompilationResult
+		if submission.TestResults != nil {
+			solutionResult.TestResults = submission.TestResults
+		}
+		for _, testResult := range submission.TestResults {
+			if testResult.Time != nil {
+				solutionResult.MaxTime = max(solutionResult.MaxTime, *testResult.Time)
+			}
+			if testResult.Memory != nil {
+				solutionResult.MaxMemory = max(solutionResult.MaxMemory, *testResult.Memory)
+			}
+		}
+
+		// Verdict of testing submission may be set before all tests are finished
+		if _, testing := testingIDs[submission.ID]; testing || submission.Verdict == verdict.RU {
+			result.Finished = false
+			continue
+		}
+		matches := solution.Tag.Matches(submissionVerdicts(submission))
+		solutionResult.MatchesTag = &matches
+		if !matches {
+			result.Mismatched = append(result.Mismatched, solution.Name)
+		}
+	}
+	return result
+}
+
+// submissionVerdicts returns verdicts of all tests of submission, compilation verdict is added if it is not CD
+func submissionVerdicts(submission *models.Submission) []verdict.Verdict {
+	verdicts := make([]verdict.Verdict, 0, len(submission.TestResults)+1)
+	if submission.CompilationResult != nil && submission.CompilationResult.Verdict != verdict.CD {
+		verdicts = append(verdicts, submission.CompilationResult.Verdict)
+	}
+	for _, testResult := range submission.TestResults {
+		verdicts = append(verdicts, testResult.Verdict)
+	}
+	return verdicts
+}
+
+// timeLimitRounding is granularity of suggested time limits
+const timeLimitRounding = customfields.Time(100 * time.Millisecond)
+
+func suggestTimeLimit(result *masterconn.InvocationResult, factor float64) (*masterconn.TimeLimitSuggestion, error) {
+	if !result.Finished {
+		return nil, fmt.Errorf("invocation %d is not finished", result.ID)
+	}
+	suggestion := &masterconn.TimeLimitSuggestion{
+		InvocationID:     result.ID,
+		ProblemID:        result.ProblemID,
+		ProblemRevision:  result.ProblemRevision,
+		Factor:           factor,
+		PassingSolutions: make([]string, 0),
+	}
+
+	accepted := 0
+	for _, solution := range result.Solutions {
+		if solution.Tag != solutiontag.Main && solution.Tag != solutiontag.Accepted {
+			continue
+		}
+		if solution.Verdict != verdict.OK {
+			return nil, fmt.Errorf("solution %s is tagged as %s, but has verdict %s", solution.Name, solution.Tag, solution.Verdict)
+		}
+		suggestion.MaxAcceptedTime = max(suggestion.MaxAcceptedTime, solution.MaxTime)
+		accepted++
+	}
+	if accepted == 0 {
+		return nil, fmt.Errorf("invocation %d has no main or accepted solutions", result.ID)
+	}
+
+	rounded := math.Ceil(float64(suggestion.MaxAcceptedTime) * factor / float64(timeLimitRounding))
+	suggestion.TimeLimit = max(customfields.Time(rounded)*timeLimitRounding, timeLimitRounding)
+
+	for _, solution := range result.Solutions {
+		if solution.Tag != solutiontag.TimeLimit && solution.Tag != solutiontag.TimeLimitOrMemoryLimit {
+			continue
+		}
+		if !exceedsTimeLimit(solution, suggestion.TimeLimit) {
+			suggestion.PassingSolutions = append(suggestion.PassingSolutions, solution.Name)
+		}
+	}
+	return suggestion, nil
+}
+
+// exceedsTimeLimit checks that solution fails some test with timeLimit. Time limit or memory limit solutions
+// also fail on tests with memory limit verdict
+func exceedsTimeLimit(solution *masterconn.InvocationSolutionResult, timeLimit customfields.Time) bool {
+	for _, testResult := range solution.TestResults {
+		switch testResult.Verdict {
+		case verdict.TL:
+			if testResult.Time != nil && *testResult.Time > timeLimit {
+				return true
+			}
+		case verdict.WL:
+			if testResult.WallTime != nil && *testResult.WallTime > timeLimit {
+				return true
+			}
+		case verdict.ML:
+			if solution.Tag == solutiontag.TimeLimitOrMemoryLimit {
+				return true
+			}
+		}
+	}
+	return false
+}
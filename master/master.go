@@ -29,9 +29,13 @@ func SetupMaster(ts *common.TestingSystem) error {
 		invokerRegistry: registry.NewInvokerRegistry(queue, ts),
 	}
 
+	if err := master.recoverSubmissions(); err != nil {
+		return err
+	}
+
 	ts.AddProcess(master.sendingJobsLoop)
 
-	router := ts.Router.Group("/master")
+	router := ts.Router.Group("/master", ts.AuthMiddleware(ts.Config.Master.Auth))
 
 	// invoker handlers
 	r := router.Group("/invoker")
@@ -40,8 +44,15 @@ func SetupMaster(ts *common.TestingSystem) error {
 
 	// client handlers
 	router.POST("/submit", master.handleNewSubmission)
+	router.POST("/rejudge", master.handleRejudge)
+	router.POST("/cancel", master.handleCancelSubmission)
 	router.GET("/status", master.handleStatus)
+	router.GET("/events", master.handleSubmissionEvents)
 	router.POST("/reset_invoker_cache", master.handleResetInvokerCache)
+	router.POST("/invocation", master.handleNewInvocation)
+	router.GET("/invocation", master.handleGetInvocation)
+	router.GET("/invocation/time_limit", master.handleSuggestTimeLimit)
+	router.POST("/generate_tests", master.handleGenerateTests)
 
 	return nil
 }
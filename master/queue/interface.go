@@ -2,8 +2,10 @@ package queue
 
 import (
 	"testing_system/common"
+	"testing_system/common/config"
 	"testing_system/common/connectors/invokerconn"
 	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/priority"
 	"testing_system/common/db/models"
 	"testing_system/master/queue/jobgenerators"
 	"testing_system/master/queue/queuestatus"
@@ -23,6 +25,14 @@ type IQueue interface {
 	// Submit processes a new submission; you SHOULD NOT submit the same pointer twice
 	Submit(problem *models.Problem, submission *models.Submission) error
 
+	// SubmitWithPriority is Submit that schedules submission with given priority instead of submission priority,
+	// e.g. for rejudge. Submission priority is not changed
+	SubmitWithPriority(
+		problem *models.Problem,
+		submission *models.Submission,
+		submissionPriority priority.Priority,
+	) error
+
 	// JobCompleted returns not nil if submission status is finalized
 	JobCompleted(jobResult *masterconn.InvokerJobResult) (submission *models.Submission, err error)
 
@@ -33,17 +43,36 @@ type IQueue interface {
 	// NextJob returns a new job or nil if no jobs to do; each job should be completed or rescheduled
 	NextJob() *invokerconn.Job
 
+	// CancelSubmission drops all jobs of submission and returns it with cancelled verdict;
+	// results of its jobs that are still testing are not accepted by queue after that
+	CancelSubmission(submissionID uint) (submission *models.Submission, err error)
+
 	Status() *queuestatus.QueueStatus
 }
 
 func NewQueue(ts *common.TestingSystem) IQueue {
+	weights := priority.DefaultWeights
+	fairShareBy := config.FairShareBySubmission
+	if ts.Config != nil && ts.Config.Master != nil {
+		weights = ts.Config.Master.QueueWeights
+		fairShareBy = ts.Config.Master.FairShareBy
+	}
+	classes := make(map[priority.Priority]*queueClass)
+	for p, weight := range weights {
+		classes[p] = newQueueClass(weight)
+	}
+
 	return &Queue{
 		ts:                       ts,
+		classes:                  classes,
 		jobIDToOriginalJobID:     make(map[string]string),
 		newFailedJobs:            make([]*invokerconn.Job, 0),
 		originalJobIDToJob:       make(map[string]*invokerconn.Job),
 		originalJobIDToGenerator: make(map[string]jobgenerators.Generator),
 		activeGeneratorIDs:       make(map[string]struct{}),
+		generatorSchedule:        make(map[string]scheduleKey),
+		submissionGenerators:     make(map[uint]*submissionGenerator),
+		fairShareBy:              fairShareBy,
 		status:                   queuestatus.NewQueueStatus(false),
 	}
 }
@@ -21,10 +21,13 @@ type Generator interface {
 }
 
 func NewGenerator(problem *models.Problem, submission *models.Submission, status *queuestatus.QueueStatus) (Generator, error) {
+	if submission.GeneratesTests {
+		return newTestGenerationGenerator(problem, submission, status)
+	}
 	switch problem.ProblemType {
 	case models.ProblemTypeICPC:
 		return newICPCGenerator(problem, submission, status)
-	case models.ProblemTypeIOI:
+	case models.ProblemTypeIOI, models.ProblemTypeOutputOnly, models.ProblemTypeTwoPhase:
 		return NewIOIGenerator(problem, submission, status)
 	default:
 		return nil, fmt.Errorf("unknown problem type %v", problem.ProblemType)
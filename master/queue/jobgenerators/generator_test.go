@@ -359,6 +359,44 @@ func TestICPCGenerator(t *testing.T) {
 		require.Equal(t, verdict.CF, sub.TestResults[0].Verdict)
 		require.Equal(t, verdict.SK, sub.TestResults[1].Verdict)
 	})
+
+	t.Run("Invocation submission is tested on all tests", func(t *testing.T) {
+		problem, submission := fixtureICPCProblem(), fixtureSubmission(1)
+		submission.InvocationID = pointer.Uint(1)
+
+		g, err := NewGenerator(problem, submission, status)
+		require.NoError(t, err)
+		job := nextJob(t, g, 1, invokerconn.CompileJob, 0)
+		sub, err := g.JobCompleted(&masterconn.InvokerJobResult{
+			Job:     job,
+			Verdict: verdict.CD,
+		})
+		require.NoError(t, err)
+		require.Nil(t, sub)
+
+		testVerdicts := []verdict.Verdict{verdict.OK, verdict.WA, verdict.OK, verdict.TL}
+		for range fixtureICPCProblemTestsNumber - len(testVerdicts) {
+			testVerdicts = append(testVerdicts, verdict.OK)
+		}
+		for i, testVerdict := range testVerdicts {
+			job = nextJob(t, g, 1, invokerconn.TestJob, uint64(i)+1)
+			require.Empty(t, job.RequiredJobIDs)
+			sub, err = g.JobCompleted(&masterconn.InvokerJobResult{
+				Job:     job,
+				Verdict: testVerdict,
+			})
+			require.NoError(t, err)
+		}
+		require.NotNil(t, sub)
+		noJobs(t, g)
+
+		require.Equal(t, verdict.WA, sub.Verdict)
+		require.Equal(t, 0., sub.Score)
+		require.Len(t, sub.TestResults, fixtureICPCProblemTestsNumber)
+		for i, result := range sub.TestResults {
+			require.Equal(t, testVerdicts[i], result.Verdict)
+		}
+	})
 }
 
 func TestIOIGenerator(t *testing.T) {
@@ -1281,6 +1319,106 @@ func TestIOIGenerator(t *testing.T) {
 	})
 }
 
+func TestIOIGeneratorInvocation(t *testing.T) {
+	status := queuestatus.NewQueueStatus(true)
+	problem := &models.Problem{
+		ProblemType: models.ProblemTypeIOI,
+		TestsNumber: 4,
+		TestGroups: []*models.TestGroup{
+			{
+				Name:        "group1",
+				FirstTest:   1,
+				LastTest:    2,
+				GroupScore:  pointer.Float64(10),
+				ScoringType: models.TestGroupScoringTypeComplete,
+			},
+			{
+				Name:               "group2",
+				FirstTest:          3,
+				LastTest:           4,
+				GroupScore:         pointer.Float64(20),
+				ScoringType:        models.TestGroupScoringTypeComplete,
+				RequiredGroupNames: []string{"group1"},
+			},
+		},
+	}
+	submission := fixtureSubmission(1)
+	submission.InvocationID = pointer.Uint(1)
+
+	g, err := NewGenerator(problem, submission, status)
+	require.NoError(t, err)
+	job := nextJob(t, g, 1, invokerconn.CompileJob, 0)
+	sub, err := g.JobCompleted(&masterconn.InvokerJobResult{
+		Job:     job,
+		Verdict: verdict.CD,
+	})
+	require.NoError(t, err)
+	require.Nil(t, sub)
+
+	testVerdicts := []verdict.Verdict{verdict.WA, verdict.OK, verdict.OK, verdict.OK}
+	jobs := make([]*invokerconn.Job, 0)
+	for i := range testVerdicts {
+		job = nextJob(t, g, 1, invokerconn.TestJob, uint64(i)+1)
+		require.Empty(t, job.RequiredJobIDs)
+		jobs = append(jobs, job)
+	}
+	noJobs(t, g)
+	for i, job := range jobs {
+		sub, err = g.JobCompleted(&masterconn.InvokerJobResult{
+			Job:     job,
+			Verdict: testVerdicts[i],
+		})
+		require.NoError(t, err)
+	}
+	require.NotNil(t, sub)
+
+	// All tests are tested, but group that depends on failed group gets no points
+	require.Equal(t, verdict.PT, sub.Verdict)
+	require.Equal(t, 0., sub.Score)
+	for i, result := range sub.TestResults {
+		require.Equal(t, testVerdicts[i], result.Verdict)
+	}
+	require.Equal(t, models.GroupResults{
+		{GroupName: "group1"},
+		{GroupName: "group2"},
+	}, sub.GroupResults)
+}
+
+func TestOutputOnlyGenerator(t *testing.T) {
+	status := queuestatus.NewQueueStatus(true)
+	problem := &models.Problem{
+		ProblemType: models.ProblemTypeOutputOnly,
+		TestsNumber: 2,
+		TestGroups: []*models.TestGroup{
+			{
+				Name:        "group1",
+				FirstTest:   1,
+				LastTest:    2,
+				TestScore:   pointer.Float64(10),
+				ScoringType: models.TestGroupScoringTypeEachTest,
+			},
+		},
+	}
+
+	g, err := NewGenerator(problem, fixtureSubmission(1), status)
+	require.NoError(t, err)
+	// Outputs are not compiled, so the first job is already a test job
+	job1 := nextJob(t, g, 1, invokerconn.TestJob, 1)
+	job2 := nextJob(t, g, 1, invokerconn.TestJob, 2)
+	noJobs(t, g)
+
+	sub, err := g.JobCompleted(&masterconn.InvokerJobResult{Job: job1, Verdict: verdict.OK})
+	require.NoError(t, err)
+	require.Nil(t, sub)
+	sub, err = g.JobCompleted(&masterconn.InvokerJobResult{Job: job2, Verdict: verdict.WA})
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+
+	require.Nil(t, sub.CompilationResult)
+	require.Equal(t, verdict.PT, sub.Verdict)
+	require.Equal(t, 10., sub.Score)
+}
+
 func requireEqualTestResult(t *testing.T, expected *models.TestResult, actual *models.TestResult) {
 	require.Equal(t, expected.TestNumber, actual.TestNumber)
 	require.Equal(t, expected.Verdict, actual.Verdict)
@@ -1296,3 +1434,58 @@ func requireEqualTestResult(t *testing.T, expected *models.TestResult, actual *m
 		require.Equal(t, *expected.Memory, *actual.Memory)
 	}
 }
+
+func TestTestGenerationGenerator(t *testing.T) {
+	status := queuestatus.NewQueueStatus(true)
+
+	t.Run("Interactive problem", func(t *testing.T) {
+		problem := &models.Problem{ProblemType: models.ProblemTypeICPC, TestsNumber: 1, Interactive: true}
+		submission := fixtureSubmission(1)
+		submission.GeneratesTests = true
+		_, err := NewGenerator(problem, submission, status)
+		require.Error(t, err)
+	})
+
+	t.Run("All tests are generated", func(t *testing.T) {
+		problem := &models.Problem{
+			ProblemType: models.ProblemTypeIOI,
+			TestsNumber: 3,
+		}
+		submission := fixtureSubmission(1)
+		submission.GeneratesTests = true
+
+		g, err := NewGenerator(problem, submission, status)
+		require.NoError(t, err)
+		job := nextJob(t, g, 1, invokerconn.CompileJob, 0)
+		noJobs(t, g)
+		sub, err := g.JobCompleted(&masterconn.InvokerJobResult{
+			Job:     job,
+			Verdict: verdict.CD,
+		})
+		require.NoError(t, err)
+		require.Nil(t, sub)
+
+		testVerdicts := []verdict.Verdict{verdict.OK, verdict.CF, verdict.OK}
+		jobs := make([]*invokerconn.Job, 0)
+		for i := range testVerdicts {
+			job = nextJob(t, g, 1, invokerconn.GenerateTestJob, uint64(i)+1)
+			require.Empty(t, job.RequiredJobIDs)
+			jobs = append(jobs, job)
+		}
+		noJobs(t, g)
+		for i, job := range jobs {
+			sub, err = g.JobCompleted(&masterconn.InvokerJobResult{
+				Job:     job,
+				Verdict: testVerdicts[i],
+			})
+			require.NoError(t, err)
+		}
+		require.NotNil(t, sub)
+
+		require.Equal(t, verdict.CF, sub.Verdict)
+		require.Len(t, sub.TestResults, len(testVerdicts))
+		for i, result := range sub.TestResults {
+			require.Equal(t, testVerdicts[i], result.Verdict)
+		}
+	})
+}
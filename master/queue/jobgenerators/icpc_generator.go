@@ -22,6 +22,10 @@ type ICPCGenerator struct {
 	state              generatorState
 	firstTestToGive    uint64
 	testedPrefixLength uint64
+	// testAllTests disables skipping tests after the first failed one
+	testAllTests bool
+	// testJobType is invokerconn.GenerateTestJob for test generation and invokerconn.TestJob otherwise
+	testJobType invokerconn.JobType
 
 	givenJobs           map[string]*invokerconn.Job
 	internalTestResults map[uint64]*models.TestResult
@@ -53,10 +57,12 @@ func (i *ICPCGenerator) NextJob() *invokerconn.Job {
 	} else if i.firstTestToGive > i.problem.TestsNumber {
 		return nil
 	} else {
-		job.Type = invokerconn.TestJob
+		job.Type = i.testJobType
 		job.Test = i.firstTestToGive
-		for givenJobID := range i.givenJobs {
-			job.RequiredJobIDs = append(job.RequiredJobIDs, givenJobID)
+		if !i.testAllTests {
+			for givenJobID := range i.givenJobs {
+				job.RequiredJobIDs = append(job.RequiredJobIDs, givenJobID)
+			}
 		}
 		i.firstTestToGive++
 	}
@@ -90,7 +96,7 @@ func (i *ICPCGenerator) updateSubmissionResult() (*models.Submission, error) {
 		}
 		updated = true
 		i.testedPrefixLength++
-		if i.submission.Verdict != verdict.RU {
+		if i.submission.Verdict != verdict.RU && !i.testAllTests {
 			result = &models.TestResult{
 				TestNumber: i.testedPrefixLength,
 				Verdict:    verdict.SK,
@@ -106,10 +112,11 @@ func (i *ICPCGenerator) updateSubmissionResult() (*models.Submission, error) {
 				i.submission.Verdict = verdict.CF
 			}
 		default:
-			if i.submission.Verdict != verdict.RU {
+			if i.submission.Verdict == verdict.RU {
+				i.submission.Verdict = result.Verdict
+			} else if !i.testAllTests {
 				logger.Panic("Trying to change bad verdict in ICPC problem")
 			}
-			i.submission.Verdict = result.Verdict
 		}
 		i.submission.TestResults = append(i.submission.TestResults, result)
 	}
@@ -146,18 +153,22 @@ func (i *ICPCGenerator) compileJobCompleted(job *invokerconn.Job, result *master
 
 // testJobCompleted must be done with acquired mutex
 func (i *ICPCGenerator) testJobCompleted(job *invokerconn.Job, result *masterconn.InvokerJobResult) {
-	if job.Type != invokerconn.TestJob {
+	if job.Type != i.testJobType {
 		logger.Panic("Treating job %s of type %v as test job", job.ID, job.Type)
 	}
 	switch result.Verdict {
 	case verdict.OK:
 		// skip
 	case verdict.PT, verdict.WA, verdict.RT, verdict.ML, verdict.TL, verdict.WL, verdict.SE, verdict.CF, verdict.SK:
-		i.setFail()
+		if !i.testAllTests {
+			i.setFail()
+		}
 	default:
 		result.Verdict = verdict.CF
 		result.Error = fmt.Sprintf("unknown verdict for test job: %v", result.Verdict)
-		i.setFail()
+		if !i.testAllTests {
+			i.setFail()
+		}
 	}
 	i.internalTestResults[job.Test] = buildTestResult(job, result)
 }
@@ -175,7 +186,7 @@ func (i *ICPCGenerator) JobCompleted(result *masterconn.InvokerJobResult) (*mode
 	switch job.Type {
 	case invokerconn.CompileJob:
 		i.compileJobCompleted(job, result)
-	case invokerconn.TestJob:
+	case invokerconn.TestJob, invokerconn.GenerateTestJob:
 		i.testJobCompleted(job, result)
 	default:
 		logger.Panic("unknown job type for ICPC problem: %v", job.Type)
@@ -185,14 +196,35 @@ func (i *ICPCGenerator) JobCompleted(result *masterconn.InvokerJobResult) (*mode
 }
 
 func newICPCGenerator(problem *models.Problem, submission *models.Submission, status *queuestatus.QueueStatus) (Generator, error) {
+	if problem.ProblemType != models.ProblemTypeICPC {
+		return nil, fmt.Errorf("problem %v is not ICPC", problem.ID)
+	}
+	generator := buildICPCGenerator(problem, submission, status)
+	generator.testAllTests = submission.InvocationID != nil
+	return generator, nil
+}
+
+// newTestGenerationGenerator gives compile job of main solution and GenerateTestJob for every test.
+// All tests are generated even if some of them fail, submission verdict is the first failed test verdict
+func newTestGenerationGenerator(
+	problem *models.Problem,
+	submission *models.Submission,
+	status *queuestatus.QueueStatus,
+) (Generator, error) {
+	if problem.Interactive {
+		return nil, fmt.Errorf("tests of interactive problem %v can not be generated", problem.ID)
+	}
+	generator := buildICPCGenerator(problem, submission, status)
+	generator.testAllTests = true
+	generator.testJobType = invokerconn.GenerateTestJob
+	return generator, nil
+}
+
+func buildICPCGenerator(problem *models.Problem, submission *models.Submission, status *queuestatus.QueueStatus) *ICPCGenerator {
 	id, err := uuid.NewV7()
 	if err != nil {
 		logger.Panic("Can't generate generator id: %w", err)
 	}
-
-	if problem.ProblemType != models.ProblemTypeICPC {
-		return nil, fmt.Errorf("problem %v is not ICPC", problem.ID)
-	}
 	submission.Verdict = verdict.RU
 
 	return &ICPCGenerator{
@@ -204,10 +236,11 @@ func newICPCGenerator(problem *models.Problem, submission *models.Submission, st
 		state:              compilationNotStarted,
 		firstTestToGive:    1,
 		testedPrefixLength: 0,
+		testJobType:        invokerconn.TestJob,
 
 		givenJobs:           make(map[string]*invokerconn.Job),
 		internalTestResults: make(map[uint64]*models.TestResult),
 
 		statusUpdater: status,
-	}, nil
+	}
 }
@@ -32,6 +32,8 @@ type IOIGenerator struct {
 	firstNotCompletedGroup uint64
 	// firstNotGivenTest = first test with internalTestState = testNotGiven; 1-based indexing
 	firstNotGivenTest uint64
+	// testAllTests disables skipping tests after failed ones, groups with failed required groups get no points
+	testAllTests bool
 
 	statusUpdater *queuestatus.QueueStatus
 }
@@ -95,7 +97,7 @@ func (i *IOIGenerator) checkIfGroupsDependenciesOK(problem *models.Problem) erro
 
 func (i *IOIGenerator) prepareGenerator() error {
 	problem := i.problem
-	if problem.ProblemType != models.ProblemTypeIOI {
+	if !problem.ProblemType.HasTestGroups() {
 		return fmt.Errorf("problem %v is not an IOI problem", problem.ID)
 	}
 	// each group with TestGroupScoringTypeEachTest must have TestScore
@@ -183,7 +185,7 @@ func (i *IOIGenerator) NextJob() *invokerconn.Job {
 		job.Test = i.firstNotGivenTest
 
 		for givenJobID, testingJob := range i.givenJobs {
-			if i.doesGroupDependOnJob(groupName, testingJob) {
+			if !i.testAllTests && i.doesGroupDependOnJob(groupName, testingJob) {
 				job.RequiredJobIDs = append(job.RequiredJobIDs, givenJobID)
 			}
 		}
@@ -225,6 +227,13 @@ func (i *IOIGenerator) completeGroupTesting(groupInfo *models.TestGroup) {
 	}
 	score := 0.0
 	groupVerdict := i.calcGroupVerdict(groupInfo)
+	if i.testAllTests && !i.requiredGroupsPassed(groupInfo) {
+		i.submission.GroupResults = append(i.submission.GroupResults, models.GroupResult{
+			GroupName: groupInfo.Name,
+		})
+		i.firstNotCompletedGroup++
+		return
+	}
 	switch groupInfo.ScoringType {
 	case models.TestGroupScoringTypeComplete:
 		if groupVerdict == verdict.OK {
@@ -262,6 +271,16 @@ func (i *IOIGenerator) completeGroupTesting(groupInfo *models.TestGroup) {
 	i.firstNotCompletedGroup++
 }
 
+// requiredGroupsPassed must be called after required groups are completed
+func (i *IOIGenerator) requiredGroupsPassed(groupInfo *models.TestGroup) bool {
+	for _, groupResult := range i.submission.GroupResults {
+		if !groupResult.Passed && slices.Contains(groupInfo.RequiredGroupNames, groupResult.GroupName) {
+			return false
+		}
+	}
+	return true
+}
+
 // updateSubmissionResult must be done with acquired mutex
 func (i *IOIGenerator) updateSubmissionResult() (*models.Submission, error) {
 	updated := false
@@ -305,7 +324,7 @@ TestsLoop:
 				i.submission.TestResults,
 				testResult,
 			)
-			if doesTestPreventTestingGroup(testGroupInfo, testResult.Verdict) {
+			if !i.testAllTests && doesTestPreventTestingGroup(testGroupInfo, testResult.Verdict) {
 				testInternalGroupInfo.shouldMarkFinalTestsSkipped = true
 				for _, group := range i.problem.TestGroups {
 					for _, requiredGroupName := range group.RequiredGroupNames {
@@ -398,7 +417,7 @@ func (i *IOIGenerator) stopGivingNewTestsIfNeeded(
 	testGroupInfo *models.TestGroup,
 	testVerdict verdict.Verdict,
 ) {
-	if !doesTestPreventTestingGroup(testGroupInfo, testVerdict) {
+	if i.testAllTests || !doesTestPreventTestingGroup(testGroupInfo, testVerdict) {
 		return
 	}
 
@@ -524,8 +543,13 @@ func NewIOIGenerator(
 		firstNotCompletedTest:   1,
 		firstNotCompletedGroup:  1,
 		firstNotGivenTest:       1,
+		testAllTests:            submission.InvocationID != nil,
 		statusUpdater:           status,
 	}
+	if problem.ProblemType == models.ProblemTypeOutputOnly {
+		// Submitted outputs are checked as is, so there is nothing to compile
+		generator.state = compilationFinished
+	}
 	generator.submission.Verdict = verdict.RU
 	if err = generator.prepareGenerator(); err != nil {
 		return nil, err
@@ -1,24 +1,32 @@
 package queue
 
 import (
-	"container/list"
 	"fmt"
 	"github.com/google/uuid"
+	"slices"
 	"sync"
 	"testing_system/common"
 	"testing_system/common/connectors/invokerconn"
 	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/lib/logger"
 	"testing_system/master/queue/jobgenerators"
 	"testing_system/master/queue/queuestatus"
 )
 
+type submissionGenerator struct {
+	submission *models.Submission
+	generator  jobgenerators.Generator
+}
+
 type Queue struct {
 	ts *common.TestingSystem
 
-	mutex            sync.Mutex
-	activeGenerators list.List
+	mutex sync.Mutex
+	// classes contain active generators for each submission priority
+	classes map[priority.Priority]*queueClass
 	// in case of reschedule, new ID will be mapped to the first one
 	jobIDToOriginalJobID map[string]string
 	newFailedJobs        []*invokerconn.Job
@@ -26,21 +34,51 @@ type Queue struct {
 
 	originalJobIDToGenerator map[string]jobgenerators.Generator
 	activeGeneratorIDs       map[string]struct{}
+	generatorSchedule        map[string]scheduleKey
+	submissionGenerators     map[uint]*submissionGenerator
+
+	fairShareBy string
 
 	status *queuestatus.QueueStatus
 }
 
 func (q *Queue) Submit(problem *models.Problem, submission *models.Submission) error {
+	return q.SubmitWithPriority(problem, submission, submission.Priority)
+}
+
+func (q *Queue) SubmitWithPriority(
+	problem *models.Problem,
+	submission *models.Submission,
+	submissionPriority priority.Priority,
+) error {
+	if submissionPriority == "" {
+		submissionPriority = priority.Default
+	}
+	if _, ok := q.classes[submissionPriority]; !ok {
+		return fmt.Errorf("submission %d has unknown priority %s", submission.ID, submissionPriority)
+	}
 	generator, err := jobgenerators.NewGenerator(problem, submission, q.status)
 	if err != nil {
 		return err
 	}
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	q.status.AddSubmission(submission)
-	q.activeGenerators.PushBack(generator)
-	q.activeGeneratorIDs[generator.ID()] = struct{}{}
-	logger.Trace("Registered submission %d for problem %d in queue", submission.ID, problem.ID)
+	if err = q.status.AddSubmission(submission); err != nil {
+		return err
+	}
+	q.generatorSchedule[generator.ID()] = scheduleKey{
+		priority: submissionPriority,
+		owner:    q.fairShareOwner(submission),
+	}
+	q.submissionGenerators[submission.ID] = &submissionGenerator{
+		submission: submission,
+		generator:  generator,
+	}
+	q.activateGenerator(generator)
+	logger.Trace(
+		"Registered submission %d for problem %d in queue with priority %s",
+		submission.ID, problem.ID, submissionPriority,
+	)
 	return nil
 }
 
@@ -68,12 +106,55 @@ func (q *Queue) JobCompleted(jobResult *masterconn.InvokerJobResult) (submission
 	delete(q.originalJobIDToGenerator, jobResult.Job.ID)
 
 	if _, ok = q.activeGeneratorIDs[generator.ID()]; !ok {
-		q.activeGenerators.PushBack(generator)
-		q.activeGeneratorIDs[generator.ID()] = struct{}{}
+		q.activateGenerator(generator)
 	}
 
 	logger.Trace("Job %s result is received by queue", wasID)
-	return generator.JobCompleted(jobResult)
+	submission, err = generator.JobCompleted(jobResult)
+	if submission != nil {
+		delete(q.generatorSchedule, generator.ID())
+		delete(q.submissionGenerators, submission.ID)
+	}
+	return submission, err
+}
+
+func (q *Queue) CancelSubmission(submissionID uint) (*models.Submission, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	holder, ok := q.submissionGenerators[submissionID]
+	if !ok {
+		return nil, fmt.Errorf("submission %d is not in queue", submissionID)
+	}
+	generator := holder.generator
+	delete(q.submissionGenerators, submissionID)
+
+	if _, ok = q.activeGeneratorIDs[generator.ID()]; ok {
+		key := q.generatorSchedule[generator.ID()]
+		q.classes[key.priority].removeGenerator(key.owner, generator)
+		delete(q.activeGeneratorIDs, generator.ID())
+	}
+	delete(q.generatorSchedule, generator.ID())
+
+	for originalJobID, jobGenerator := range q.originalJobIDToGenerator {
+		if jobGenerator != generator {
+			continue
+		}
+		delete(q.originalJobIDToGenerator, originalJobID)
+		delete(q.originalJobIDToJob, originalJobID)
+	}
+	for jobID, originalJobID := range q.jobIDToOriginalJobID {
+		if _, ok = q.originalJobIDToJob[originalJobID]; !ok {
+			delete(q.jobIDToOriginalJobID, jobID)
+		}
+	}
+	q.newFailedJobs = slices.DeleteFunc(q.newFailedJobs, func(job *invokerconn.Job) bool {
+		return job.SubmitID == submissionID
+	})
+
+	holder.submission.Verdict = verdict.CL
+	logger.Trace("Cancelled submission %d in queue", submissionID)
+	return holder.submission, nil
 }
 
 func (q *Queue) RescheduleJob(jobID string) error {
@@ -118,20 +199,16 @@ func (q *Queue) NextJob() *invokerconn.Job {
 		logger.Trace("Queue returns rescheduled job %v", job)
 		return job
 	}
-	attempts := q.activeGenerators.Len()
-	for range attempts {
-		generatorListElement := q.activeGenerators.Front()
-		generator := generatorListElement.Value.(jobgenerators.Generator)
-		job := generator.NextJob()
+	candidates := q.classesOrder()
+	for _, class := range candidates {
+		job, generator := q.classes[class].nextJob(q)
 		if job == nil {
-			q.activeGenerators.Remove(generatorListElement)
-			delete(q.activeGeneratorIDs, generator.ID())
 			continue
 		}
-		q.activeGenerators.MoveToBack(generatorListElement)
+		q.chargeClass(class, candidates)
 		q.originalJobIDToGenerator[job.ID] = generator
 		q.originalJobIDToJob[job.ID] = job
-		logger.Trace("Queue returns new job %v", job)
+		logger.Trace("Queue returns new job %v with priority %s", job, class)
 		return job
 	}
 	return nil
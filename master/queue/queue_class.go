@@ -0,0 +1,169 @@
+package queue
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"testing_system/common/config"
+	"testing_system/common/connectors/invokerconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/db/models"
+	"testing_system/master/queue/jobgenerators"
+)
+
+// queueClass holds active generators of submissions with the same priority.
+// Classes share jobs using smooth weighted round-robin, so lower priorities are not starved.
+// Inside the class jobs are shared equally between owners, and each owner shares its jobs between its generators
+type queueClass struct {
+	weight        int
+	currentWeight int
+
+	owners        list.List
+	ownerElements map[string]*list.Element
+}
+
+type queueOwner struct {
+	key        string
+	generators list.List
+}
+
+type scheduleKey struct {
+	priority priority.Priority
+	owner    string
+}
+
+func newQueueClass(weight int) *queueClass {
+	return &queueClass{
+		weight:        weight,
+		ownerElements: make(map[string]*list.Element),
+	}
+}
+
+func (c *queueClass) addGenerator(owner string, generator jobgenerators.Generator) {
+	ownerElement, ok := c.ownerElements[owner]
+	if !ok {
+		ownerElement = c.owners.PushBack(&queueOwner{key: owner})
+		c.ownerElements[owner] = ownerElement
+	}
+	ownerElement.Value.(*queueOwner).generators.PushBack(generator)
+}
+
+func (c *queueClass) removeGenerator(owner string, generator jobgenerators.Generator) {
+	ownerElement, ok := c.ownerElements[owner]
+	if !ok {
+		return
+	}
+	queueOwner := ownerElement.Value.(*queueOwner)
+	for element := queueOwner.generators.Front(); element != nil; element = element.Next() {
+		if element.Value.(jobgenerators.Generator) == generator {
+			queueOwner.generators.Remove(element)
+			break
+		}
+	}
+	if queueOwner.generators.Len() == 0 {
+		c.owners.Remove(ownerElement)
+		delete(c.ownerElements, owner)
+	}
+}
+
+// nextJob round-robins over class owners and their generators, generators without jobs are removed from the class
+func (c *queueClass) nextJob(q *Queue) (*invokerconn.Job, jobgenerators.Generator) {
+	attempts := c.owners.Len()
+	for range attempts {
+		ownerElement := c.owners.Front()
+		owner := ownerElement.Value.(*queueOwner)
+		job, generator := owner.nextJob(q)
+		if owner.generators.Len() == 0 {
+			c.owners.Remove(ownerElement)
+			delete(c.ownerElements, owner.key)
+		} else {
+			c.owners.MoveToBack(ownerElement)
+		}
+		if job != nil {
+			return job, generator
+		}
+	}
+	return nil, nil
+}
+
+func (o *queueOwner) nextJob(q *Queue) (*invokerconn.Job, jobgenerators.Generator) {
+	attempts := o.generators.Len()
+	for range attempts {
+		generatorListElement := o.generators.Front()
+		generator := generatorListElement.Value.(jobgenerators.Generator)
+		job := generator.NextJob()
+		if job == nil {
+			o.generators.Remove(generatorListElement)
+			delete(q.activeGeneratorIDs, generator.ID())
+			continue
+		}
+		o.generators.MoveToBack(generatorListElement)
+		return job, generator
+	}
+	return nil, nil
+}
+
+func (c *queueClass) generatorsCount() int {
+	count := 0
+	for element := c.owners.Front(); element != nil; element = element.Next() {
+		count += element.Value.(*queueOwner).generators.Len()
+	}
+	return count
+}
+
+func (q *Queue) activateGenerator(generator jobgenerators.Generator) {
+	key := q.generatorSchedule[generator.ID()]
+	q.classes[key.priority].addGenerator(key.owner, generator)
+	q.activeGeneratorIDs[generator.ID()] = struct{}{}
+}
+
+func (q *Queue) fairShareOwner(submission *models.Submission) string {
+	switch {
+	case q.fairShareBy == config.FairShareByUser && submission.UserID != nil:
+		return fmt.Sprintf("user-%d", *submission.UserID)
+	case q.fairShareBy == config.FairShareByContest && submission.ContestID != nil:
+		return fmt.Sprintf("contest-%d", *submission.ContestID)
+	default:
+		return fmt.Sprintf("submission-%d", submission.ID)
+	}
+}
+
+// classesOrder returns priorities of classes with active generators in order they should be asked for a job
+func (q *Queue) classesOrder() []priority.Priority {
+	candidates := make([]priority.Priority, 0, len(q.classes))
+	for p, class := range q.classes {
+		if class.owners.Len() > 0 {
+			candidates = append(candidates, p)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b priority.Priority) int {
+		classA, classB := q.classes[a], q.classes[b]
+		if diff := (classB.currentWeight + classB.weight) - (classA.currentWeight + classA.weight); diff != 0 {
+			return diff
+		}
+		return slices.Index(priority.All, a) - slices.Index(priority.All, b)
+	})
+	return candidates
+}
+
+// chargeClass updates weights after job of chosen class is given
+func (q *Queue) chargeClass(chosen priority.Priority, candidates []priority.Priority) {
+	total := 0
+	for p, class := range q.classes {
+		if slices.Contains(candidates, p) {
+			class.currentWeight += class.weight
+			total += class.weight
+		} else {
+			class.currentWeight = 0
+		}
+	}
+	q.classes[chosen].currentWeight -= total
+}
+
+func (q *Queue) activeGeneratorsCount() int {
+	count := 0
+	for _, class := range q.classes {
+		count += class.generatorsCount()
+	}
+	return count
+}
@@ -7,8 +7,10 @@ import (
 	"strings"
 	"testing"
 	"testing_system/common"
+	"testing_system/common/config"
 	"testing_system/common/connectors/invokerconn"
 	"testing_system/common/connectors/masterconn"
+	"testing_system/common/constants/priority"
 	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/common/metrics"
@@ -44,12 +46,29 @@ func doQueueCycles(t *testing.T, q *Queue, cycles int, maxNoJobs int) int {
 	return finishedTasks
 }
 
+// countQueueJobs completes jobs successfully and returns number of completed jobs of each submission
+func countQueueJobs(t *testing.T, q *Queue, jobs int) map[uint]int {
+	jobsCount := make(map[uint]int)
+	for range jobs {
+		job := q.NextJob()
+		require.NotNil(t, job)
+		jobsCount[job.SubmitID]++
+		jobVerdict := verdict.OK
+		if job.Type == invokerconn.CompileJob {
+			jobVerdict = verdict.CD
+		}
+		_, err := q.JobCompleted(&masterconn.InvokerJobResult{Job: job, Verdict: jobVerdict})
+		require.NoError(t, err)
+	}
+	return jobsCount
+}
+
 func isQueueEmpty(q *Queue) bool {
 	return len(q.jobIDToOriginalJobID) == 0 &&
 		len(q.newFailedJobs) == 0 &&
 		len(q.originalJobIDToJob) == 0 &&
 		len(q.originalJobIDToGenerator) == 0 &&
-		q.activeGenerators.Len() == 0
+		q.activeGeneratorsCount() == 0
 }
 
 func createQueue() *Queue {
@@ -260,3 +279,94 @@ func TestQueueWrongJobID(t *testing.T) {
 	assert.Nil(t, sub)
 	assert.NotNil(t, err)
 }
+
+func TestQueuePriorities(t *testing.T) {
+	q := createQueue()
+	problem := models.Problem{
+		TestsNumber: 1000,
+		ProblemType: models.ProblemTypeICPC,
+	}
+	problem.ID = 1
+	contestSubmission := models.Submission{Priority: priority.Contest}
+	// Rejudged submission keeps its own priority, rejudge priority is used only by queue
+	rejudgeSubmission := models.Submission{Priority: priority.Contest}
+	contestSubmission.ID, rejudgeSubmission.ID = 1, 2
+	require.NoError(t, q.SubmitWithPriority(&problem, &rejudgeSubmission, priority.Rejudge))
+	require.Equal(t, priority.Contest, rejudgeSubmission.Priority)
+	require.NoError(t, q.Submit(&problem, &contestSubmission))
+	require.ErrorContains(t, q.Submit(&problem, &models.Submission{ID: 3, Priority: "unknown"}), "unknown")
+	require.ErrorContains(t, q.SubmitWithPriority(&problem, &models.Submission{ID: 4}, "unknown"), "unknown")
+
+	jobsCount := countQueueJobs(t, q, 170)
+	require.Equal(t, 160, jobsCount[contestSubmission.ID])
+	require.Equal(t, 10, jobsCount[rejudgeSubmission.ID])
+}
+
+func TestQueueFairShare(t *testing.T) {
+	ts := &common.TestingSystem{
+		Metrics: metrics.NewCollector(),
+		Config: &config.Config{
+			Master: &config.MasterConfig{
+				QueueWeights: priority.DefaultWeights,
+				FairShareBy:  config.FairShareByUser,
+			},
+		},
+	}
+	q := NewQueue(ts).(*Queue)
+	problem := models.Problem{
+		TestsNumber: 1000,
+		ProblemType: models.ProblemTypeICPC,
+	}
+	problem.ID = 1
+	user1, user2 := uint(1), uint(2)
+	submissions := []*models.Submission{
+		{ID: 1, UserID: &user1},
+		{ID: 2, UserID: &user1},
+		{ID: 3, UserID: &user1},
+		{ID: 4, UserID: &user2},
+	}
+	for _, submission := range submissions {
+		require.NoError(t, q.Submit(&problem, submission))
+	}
+
+	jobsCount := countQueueJobs(t, q, 120)
+	require.Equal(t, 60, jobsCount[1]+jobsCount[2]+jobsCount[3])
+	require.Equal(t, 60, jobsCount[4])
+	require.Equal(t, 20, jobsCount[1])
+}
+
+func TestQueueCancelSubmission(t *testing.T) {
+	q := createQueue()
+	problem := models.Problem{
+		TestsNumber: 10,
+		ProblemType: models.ProblemTypeICPC,
+	}
+	problem.ID = 1
+	cancelled := models.Submission{}
+	other := models.Submission{}
+	cancelled.ID, other.ID = 1, 2
+	require.NoError(t, q.Submit(&problem, &cancelled))
+
+	compileJob := q.NextJob()
+	require.Equal(t, cancelled.ID, compileJob.SubmitID)
+	_, err := q.JobCompleted(&masterconn.InvokerJobResult{Job: compileJob, Verdict: verdict.CD})
+	require.NoError(t, err)
+	testingJob := q.NextJob()
+	rescheduledJob := q.NextJob()
+	require.NoError(t, q.RescheduleJob(rescheduledJob.ID))
+
+	require.NoError(t, q.Submit(&problem, &other))
+	submission, err := q.CancelSubmission(cancelled.ID)
+	require.NoError(t, err)
+	require.Equal(t, verdict.CL, submission.Verdict)
+	_, err = q.CancelSubmission(cancelled.ID)
+	require.Error(t, err)
+
+	// Results of cancelled jobs are not accepted
+	_, err = q.JobCompleted(&masterconn.InvokerJobResult{Job: testingJob, Verdict: verdict.OK})
+	require.Error(t, err)
+
+	finished := doQueueCycles(t, q, 12, 1)
+	require.Equal(t, 1, finished)
+	require.True(t, isQueueEmpty(q))
+}
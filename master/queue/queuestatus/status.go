@@ -2,6 +2,7 @@ package queuestatus
 
 import (
 	"container/list"
+	"fmt"
 	"github.com/google/uuid"
 	"sync"
 	"testing_system/common/connectors/masterconn"
@@ -14,7 +15,11 @@ type QueueStatus struct {
 
 	activeSubmissions          map[uint]*submissionHolder
 	submissionsOrderedByUpdate *list.List
-	isTesting                  bool
+	// claimedSubmissions are going to be added to status, e.g. they are prepared for rejudge
+	claimedSubmissions map[uint]struct{}
+	isTesting          bool
+
+	subscribers map[*subscriber]struct{}
 }
 
 type submissionHolder struct {
@@ -29,34 +34,91 @@ func NewQueueStatus(isTesting bool) *QueueStatus {
 	return &QueueStatus{
 		activeSubmissions:          make(map[uint]*submissionHolder),
 		submissionsOrderedByUpdate: list.New(),
+		claimedSubmissions:         make(map[uint]struct{}),
 		isTesting:                  isTesting,
+		subscribers:                make(map[*subscriber]struct{}),
 	}
 }
 
-func (s *QueueStatus) AddSubmission(submission *models.Submission) {
+// AddSubmission adds submission to status, it fails if submission is already tested.
+// Claim of submission is released after it is added
+func (s *QueueStatus) AddSubmission(submission *models.Submission) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	_, ok := s.activeSubmissions[submission.ID]
 	if ok {
-		logger.Panic("submission %d is added to status twice", submission.ID)
+		return fmt.Errorf("submission %d is already in queue", submission.ID)
 	}
+	delete(s.claimedSubmissions, submission.ID)
+	// Caller keeps changing its submission, so status stores its own copy like UpdateSubmission does
+	submission = copySubmission(submission)
 	holder := &submissionHolder{
 		submission:       submission,
 		lastUpdatedEpoch: s.newEpoch(),
 	}
 	s.activeSubmissions[submission.ID] = holder
 	holder.listPosition = s.submissionsOrderedByUpdate.PushFront(holder)
+	s.publish(masterconn.SubmissionEventTesting, submission)
+	return nil
+}
+
+// ClaimSubmission reserves submission that is going to be added to status.
+// It returns false if submission is already tested or claimed, so only one caller can prepare it for testing.
+// Claim should be released if submission is not added
+func (s *QueueStatus) ClaimSubmission(id uint) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.activeSubmissions[id]; ok {
+		return false
+	}
+	if _, ok := s.claimedSubmissions[id]; ok {
+		return false
+	}
+	s.claimedSubmissions[id] = struct{}{}
+	return true
+}
+
+// ReleaseSubmission removes claim of submission that was not added to status
+func (s *QueueStatus) ReleaseSubmission(id uint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.claimedSubmissions, id)
+}
+
+// HasSubmission reports whether the submission is still being tested, its result is not saved yet
+// or it is claimed for testing
+func (s *QueueStatus) HasSubmission(id uint) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.claimedSubmissions[id]; ok {
+		return true
+	}
+	_, ok := s.activeSubmissions[id]
+	return ok
 }
 
-func (s *QueueStatus) FinishSubmissionTesting(id uint) {
+// GetSubmission returns current state of testing submission or nil if it is not in status
+func (s *QueueStatus) GetSubmission(id uint) *models.Submission {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	holder, ok := s.activeSubmissions[id]
 	if !ok {
-		logger.Panic("Removing submission %d from queue status that is not present in list", id)
+		return nil
+	}
+	return copySubmission(holder.submission)
+}
+
+// FinishSubmissionTesting removes submission from status, it should be called after final result is saved
+func (s *QueueStatus) FinishSubmissionTesting(submission *models.Submission) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	holder, ok := s.activeSubmissions[submission.ID]
+	if !ok {
+		logger.Panic("Removing submission %d from queue status that is not present in list", submission.ID)
 	}
-	delete(s.activeSubmissions, id)
+	delete(s.activeSubmissions, submission.ID)
 	s.submissionsOrderedByUpdate.Remove(holder.listPosition)
+	s.publish(masterconn.SubmissionEventFinished, copySubmission(submission))
 }
 
 func (s *QueueStatus) UpdateSubmission(submission *models.Submission) {
@@ -71,15 +133,10 @@ func (s *QueueStatus) UpdateSubmission(submission *models.Submission) {
 		logger.Panic("Updating submission %d that is not added to queue status", submission.ID)
 	}
 
-	// We will not have data race with pointers here.
-	// All pointers of types: TestResults, CompilationResult, GroupResult
-	// can be only assigned to new values, but they never change
-	submissionCopy := *submission
-	copy(submissionCopy.TestResults, submission.TestResults)
-
 	holder.lastUpdatedEpoch = s.newEpoch()
-	holder.submission = &submissionCopy
+	holder.submission = copySubmission(submission)
 	s.submissionsOrderedByUpdate.MoveToFront(holder.listPosition)
+	s.publish(masterconn.SubmissionEventUpdated, holder.submission)
 }
 
 func (s *QueueStatus) GetStatus(prevEpoch string) *masterconn.Status {
@@ -109,6 +166,16 @@ func (s *QueueStatus) GetStatus(prevEpoch string) *masterconn.Status {
 	return status
 }
 
+// copySubmission is safe without deep copy.
+// All pointers of types: TestResults, CompilationResult, GroupResult
+// can be only assigned to new values, but they never change
+func copySubmission(submission *models.Submission) *models.Submission {
+	submissionCopy := *submission
+	submissionCopy.TestResults = make(models.TestResults, len(submission.TestResults))
+	copy(submissionCopy.TestResults, submission.TestResults)
+	return &submissionCopy
+}
+
 func (s *QueueStatus) newEpoch() string {
 	epoch, err := uuid.NewV7()
 	if err != nil {
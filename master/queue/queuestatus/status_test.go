@@ -0,0 +1,27 @@
+package queuestatus
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing_system/common/db/models"
+)
+
+func TestClaimSubmission(t *testing.T) {
+	status := NewQueueStatus(false)
+
+	require.True(t, status.ClaimSubmission(1))
+	require.False(t, status.ClaimSubmission(1))
+	require.True(t, status.HasSubmission(1))
+
+	status.ReleaseSubmission(1)
+	require.False(t, status.HasSubmission(1))
+
+	require.True(t, status.ClaimSubmission(1))
+	require.NoError(t, status.AddSubmission(&models.Submission{ID: 1}))
+	require.False(t, status.ClaimSubmission(1))
+	require.Error(t, status.AddSubmission(&models.Submission{ID: 1}))
+
+	status.FinishSubmissionTesting(&models.Submission{ID: 1})
+	require.False(t, status.HasSubmission(1))
+	require.True(t, status.ClaimSubmission(1))
+}
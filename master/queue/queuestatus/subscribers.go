@@ -0,0 +1,90 @@
+package queuestatus
+
+import (
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/db/models"
+)
+
+// subscriberBufferSize is number of events that subscriber may not read yet, current state is sent in addition to them
+const subscriberBufferSize = 256
+
+type subscriber struct {
+	submissionID *uint
+	userID       *uint
+	events       chan *masterconn.SubmissionEvent
+}
+
+// Subscribe returns channel with events of submission, or of all submissions if submissionID is nil.
+// If userID is set, only events of submissions of this user are sent.
+// Current state of active submissions is sent first.
+// Channel is closed if subscriber does not read events fast enough, unsubscribe should always be called
+func (s *QueueStatus) Subscribe(submissionID *uint, userID *uint) (<-chan *masterconn.SubmissionEvent, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sub := &subscriber{
+		submissionID: submissionID,
+		userID:       userID,
+	}
+	var snapshot []*models.Submission
+	for element := s.submissionsOrderedByUpdate.Back(); element != nil; element = element.Prev() {
+		holder := element.Value.(*submissionHolder)
+		if sub.matches(holder.submission) {
+			snapshot = append(snapshot, holder.submission)
+		}
+	}
+
+	// Buffer always fits current state, so new subscriber is not dropped when many submissions are tested
+	sub.events = make(chan *masterconn.SubmissionEvent, len(snapshot)+subscriberBufferSize)
+	for _, submission := range snapshot {
+		sub.events <- &masterconn.SubmissionEvent{Type: masterconn.SubmissionEventTesting, Submission: submission}
+	}
+	s.subscribers[sub] = struct{}{}
+
+	return sub.events, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.removeSubscriber(sub)
+	}
+}
+
+// publish should be called with locked mutex
+func (s *QueueStatus) publish(eventType masterconn.SubmissionEventType, submission *models.Submission) {
+	for sub := range s.subscribers {
+		s.sendEvent(sub, eventType, submission)
+	}
+}
+
+func (s *QueueStatus) sendEvent(
+	sub *subscriber,
+	eventType masterconn.SubmissionEventType,
+	submission *models.Submission,
+) {
+	if !sub.matches(submission) {
+		return
+	}
+	select {
+	case sub.events <- &masterconn.SubmissionEvent{Type: eventType, Submission: submission}:
+	default:
+		// Slow subscriber is dropped, it can resubscribe and get current state again
+		s.removeSubscriber(sub)
+	}
+}
+
+func (sub *subscriber) matches(submission *models.Submission) bool {
+	if sub.submissionID != nil && *sub.submissionID != submission.ID {
+		return false
+	}
+	if sub.userID != nil && (submission.UserID == nil || *submission.UserID != *sub.userID) {
+		return false
+	}
+	return true
+}
+
+func (s *QueueStatus) removeSubscriber(sub *subscriber) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
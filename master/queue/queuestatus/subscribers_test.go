@@ -0,0 +1,49 @@
+package queuestatus
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+	"testing"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/db/models"
+)
+
+func TestSubscribeWithManyActiveSubmissions(t *testing.T) {
+	status := NewQueueStatus(false)
+	submissionsCount := 2 * subscriberBufferSize
+	for id := 1; id <= submissionsCount; id++ {
+		status.AddSubmission(&models.Submission{ID: uint(id)})
+	}
+
+	events, unsubscribe := status.Subscribe(nil, nil)
+	defer unsubscribe()
+
+	status.UpdateSubmission(&models.Submission{ID: 1})
+	for id := 1; id <= submissionsCount; id++ {
+		event, ok := <-events
+		require.True(t, ok)
+		require.Equal(t, masterconn.SubmissionEventTesting, event.Type)
+		require.Equal(t, uint(id), event.Submission.ID)
+	}
+	event, ok := <-events
+	require.True(t, ok)
+	require.Equal(t, masterconn.SubmissionEventUpdated, event.Type)
+}
+
+func TestSubscribeUserEvents(t *testing.T) {
+	status := NewQueueStatus(false)
+	userID := uint(1)
+	status.AddSubmission(&models.Submission{ID: 1})
+	status.AddSubmission(&models.Submission{ID: 2, UserID: &userID})
+
+	events, unsubscribe := status.Subscribe(nil, &userID)
+	defer unsubscribe()
+
+	status.AddSubmission(&models.Submission{ID: 3, UserID: pointer.Uint(2)})
+	status.AddSubmission(&models.Submission{ID: 4, UserID: &userID})
+	for _, id := range []uint{2, 4} {
+		event := <-events
+		require.Equal(t, id, event.Submission.ID)
+	}
+	require.Empty(t, events)
+}
@@ -0,0 +1,82 @@
+package master
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/lib/logger"
+)
+
+// recoverSubmissions puts submissions that were being tested before master restart back to the queue.
+// Such submissions have RU verdict in database, their testing is started from scratch.
+// Generator state is intentionally not checkpointed: job generators keep per-test and per-group state only in memory,
+// and restoring it would need all of it to be saved on every finished job. Restart of master is rare,
+// so retesting the submission from test 1 is simpler and gives the same result
+func (m *Master) recoverSubmissions() error {
+	var submissions []*models.Submission
+	err := m.ts.DB.WithContext(m.ts.StopCtx).
+		Where("verdict = ?", verdict.RU).
+		Order("id").
+		Find(&submissions).
+		Error
+	if err != nil {
+		return logger.Error("Can not load testing submissions from db, error: %v", err)
+	}
+
+	type problemRevisionKey struct {
+		problemID uint
+		revision  uint64
+	}
+	problems := make(map[problemRevisionKey]*models.Problem)
+	recovered := 0
+	for _, submission := range submissions {
+		key := problemRevisionKey{problemID: submission.ProblemID, revision: submission.ProblemRevision}
+		problem, ok := problems[key]
+		if !ok {
+			problem, err = models.LoadProblemRevision(m.ts.DB.WithContext(m.ts.StopCtx), key.problemID, key.revision)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				problem = nil
+			} else if err != nil {
+				return logger.Error("Can not load problem %d from db, error: %v", submission.ProblemID, err)
+			}
+			problems[key] = problem
+		}
+
+		submission.ResetResults()
+		if problem == nil {
+			logger.Error(
+				"Can not recover submission %d, problem %d revision %d not found",
+				submission.ID, key.problemID, key.revision,
+			)
+			if err = m.failRecoveredSubmission(submission, "problem revision is not found"); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = m.queue.Submit(problem, submission); err != nil {
+			logger.Error("Can not recover submission %d, error: %v", submission.ID, err)
+			if err = m.failRecoveredSubmission(submission, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		m.ts.Metrics.MasterQueueSize.Inc()
+		recovered++
+	}
+
+	if len(submissions) > 0 {
+		logger.Info("Recovered %d of %d testing submissions", recovered, len(submissions))
+	}
+	return nil
+}
+
+// failRecoveredSubmission saves submission that can not be recovered with check failed verdict.
+// Recovery runs before testing system is started, so the submission is saved without retries
+func (m *Master) failRecoveredSubmission(submission *models.Submission, reason string) error {
+	setSubmissionFailed(submission, reason)
+	if err := m.ts.DB.WithContext(m.ts.StopCtx).Save(submission).Error; err != nil {
+		return logger.Error("Can not save failed submission %d to db, error: %v", submission.ID, err)
+	}
+	return nil
+}
@@ -47,7 +47,10 @@ func newInvoker(status *invokerconn.Status, registry *InvokerRegistry, ts *commo
 
 	invoker := Invoker{
 		ts:            ts,
-		connector:     invokerconn.NewConnector(&config.Connection{Address: status.Address}),
+		connector:     invokerconn.NewConnector(&config.Connection{
+			Address: status.Address,
+			Auth:    ts.Config.Master.InvokerAuth,
+		}),
 		registry:      registry,
 		jobHolderByID: make(map[string]*jobHolder),
 		jobTypesCount: make(map[JobType]int),
@@ -323,3 +326,15 @@ func (i *Invoker) StopJob(jobID string) {
 		}
 	})
 }
+
+// CancelJob forgets job and stops it on invoker, so job result is treated as unknown
+func (i *Invoker) CancelJob(jobID string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.getJobType(jobID) == UnknownJob {
+		return
+	}
+	i.removeJob(jobID)
+	i.StopJob(jobID)
+}
@@ -8,6 +8,7 @@ import (
 	"testing_system/common/connectors/invokerconn"
 	"testing_system/common/connectors/masterconn"
 	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
 	"testing_system/lib/logger"
 	"testing_system/master/queue"
 )
@@ -121,6 +122,35 @@ func (r *InvokerRegistry) SendJobs() {
 	}
 }
 
+// CancelSubmission removes submission from queue and stops its jobs on all invokers.
+// Results of stopped jobs are ignored as unknown jobs
+func (r *InvokerRegistry) CancelSubmission(submissionID uint) (*models.Submission, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	submission, err := r.queue.CancelSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if r.nextJob != nil && r.nextJob.SubmitID == submissionID {
+		r.nextJob = nil
+	}
+
+	for jobID, job := range r.testingJobs {
+		if job.SubmitID != submissionID {
+			continue
+		}
+		invoker, ok := r.invokerByJobID[jobID]
+		if !ok {
+			logger.Panic("Job %s is marked as testing, but no invoker found for it", jobID)
+		}
+		invoker.CancelJob(jobID)
+		delete(r.invokerByJobID, jobID)
+		delete(r.testingJobs, jobID)
+	}
+	return submission, nil
+}
+
 func (r *InvokerRegistry) Status() []*masterconn.InvokerStatus {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -0,0 +1,114 @@
+package master
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/lib/logger"
+)
+
+// @Summary Generate tests
+// @Description Create new problem revision and generate its inputs by test script, validate all inputs
+// @Description and write answers with main solution. Generation is tested as main solution submission
+// @Description with author priority, the first failed test verdict is submission verdict
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Param request body masterconn.TestGenerationRequest true "Problem"
+// @Success 200 {object} masterconn.TestGenerationResponse
+// @Failure 400 {object} string
+// @Failure 404 {object} string
+// @Failure 500 {object} string
+// @Router /master/generate_tests [post]
+func (m *Master) handleGenerateTests(c *gin.Context) {
+	var request masterconn.TestGenerationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "Invalid test generation request: %v", err)
+		return
+	}
+
+	problem := m.loadProblem(c, request.ProblemID)
+	if problem == nil {
+		return
+	}
+	if problem.Interactive {
+		c.String(http.StatusBadRequest, "Tests of interactive problem can not be generated")
+		return
+	}
+	solution := problem.MainSolution()
+	if solution == nil {
+		c.String(http.StatusBadRequest, "Problem has no main solution")
+		return
+	}
+
+	resp := m.ts.StorageConn.Download(&storageconn.Request{
+		Resource:        resource.Solution,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		StorageFilename: solution.Name,
+		DownloadBytes:   true,
+		Ctx:             c,
+	})
+	if resp.Error != nil {
+		logger.Error("failed to load problem %d main solution %s, error: %v", problem.ID, solution.Name, resp.Error)
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// Generated tests are uploaded to new revision, so submissions tested against previous revisions and invokers
+	// that cached their files are not affected
+	oldProblem := problem
+	problem = new(models.Problem)
+	*problem = *oldProblem
+	if err := models.SaveNewProblemRevision(m.ts.DB.WithContext(c), oldProblem, problem); err != nil {
+		logger.Error("failed to create problem %d revision for test generation, error: %v", problem.ID, err)
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	submission := &models.Submission{
+		ProblemID:       problem.ID,
+		ProblemRevision: problem.Revision,
+		Language:        solution.Language,
+		Priority:        priority.Author,
+		GeneratesTests:  true,
+		Verdict:         verdict.RU,
+	}
+	if err := m.ts.DB.WithContext(c).Create(submission).Error; err != nil {
+		logger.Error("failed to save test generation submission of problem %d to db, error: %v", problem.ID, err)
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	err := m.ts.StorageConn.Upload(&storageconn.Request{
+		Resource:        resource.SourceCode,
+		SubmitID:        uint64(submission.ID),
+		StorageFilename: solution.Name,
+		File:            bytes.NewReader(resp.RawData),
+		Ctx:             c,
+	}).Error
+	if err != nil {
+		logger.Error("failed to save test generation submission %d source, error: %v", submission.ID, err)
+		m.failSubmission(submission, "failed to save solution source")
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if err = m.queue.Submit(problem, submission); err != nil {
+		logger.Error("failed to submit test generation submission %d, error: %v", submission.ID, err)
+		m.failSubmission(submission, err.Error())
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+	m.ts.Metrics.MasterQueueSize.Inc()
+	logger.Trace("new test generation, submission: %d, problem: %d", submission.ID, problem.ID)
+
+	m.invokerRegistry.SendJobs()
+
+	c.JSON(http.StatusOK, masterconn.TestGenerationResponse{SubmissionID: submission.ID})
+}
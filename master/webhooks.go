@@ -0,0 +1,89 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing_system/common/config"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/db/models"
+	"testing_system/lib/logger"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/go-resty/resty/v2"
+)
+
+// sendWebhooks notifies all configured webhooks about finished submission in background
+func (m *Master) sendWebhooks(submission *models.Submission) {
+	if len(m.ts.Config.Master.Webhooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(&masterconn.SubmissionWebhook{
+		Event:        masterconn.WebhookEventSubmissionFinished,
+		SubmissionID: submission.ID,
+		Verdict:      submission.Verdict,
+		Score:        submission.Score,
+		GroupResults: submission.GroupResults,
+		Submission:   submission,
+	})
+	if err != nil {
+		logger.Error("Can not marshal webhook of submission %d, error: %v", submission.ID, err)
+		return
+	}
+
+	for _, webhook := range m.ts.Config.Master.Webhooks {
+		m.ts.Go(func() {
+			m.deliverWebhook(webhook, submission.ID, body)
+		})
+	}
+}
+
+// deliverWebhook retries delivery until webhook accepts it or retry time is over.
+// Webhook failures never stop master, they are only logged
+func (m *Master) deliverWebhook(webhook *config.WebhookConfig, submissionID uint, body []byte) {
+	client := resty.New().SetTimeout(webhook.Timeout)
+	_, err := backoff.Retry(
+		m.ts.StopCtx,
+		func() (*struct{}, error) {
+			return nil, postWebhook(m.ts.StopCtx, client, webhook, body)
+		},
+		backoff.WithBackOff(backoff.NewExponentialBackOff()),
+		backoff.WithMaxElapsedTime(webhook.MaxElapsedTime),
+	)
+	if err != nil {
+		logger.Error("Can not deliver submission %d to webhook %s, error: %v", submissionID, webhook.URL, err)
+		return
+	}
+	logger.Trace("submission %d is delivered to webhook %s", submissionID, webhook.URL)
+}
+
+func postWebhook(ctx context.Context, client *resty.Client, webhook *config.WebhookConfig, body []byte) error {
+	r := client.R()
+	r.SetContext(ctx)
+	r.SetHeader("Content-Type", "application/json")
+	r.SetBody(body)
+	if webhook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		r.SetHeader(masterconn.WebhookTimestampHeader, timestamp)
+		r.SetHeader(masterconn.WebhookSignatureHeader, masterconn.SignWebhook(webhook.Secret, timestamp, body))
+	}
+	resp, err := r.Post(webhook.URL)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		err = fmt.Errorf("webhook responded with status %d", resp.StatusCode())
+		// Webhook rejected the payload, there is no sense to resend it
+		if resp.StatusCode() < http.StatusInternalServerError &&
+			resp.StatusCode() != http.StatusRequestTimeout &&
+			resp.StatusCode() != http.StatusTooManyRequests {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -83,24 +83,35 @@ func (filesystem *Filesystem) RemoveFile(resourceInfo *ResourceInfo) error {
 	return nil
 }
 
+// GetFile looks for problem resources in requested revision and then in previous revisions
 func (filesystem *Filesystem) GetFile(resourceInfo *ResourceInfo) (string, error) {
-	fullPath, err := filesystem.BuildFilePath(resourceInfo)
-	if err != nil {
-		return "", err
-	}
-
-	_, err = os.Stat(fullPath)
-
-	if err != nil {
-		return "", err
+	revision := resourceInfo.problemRevision()
+	for {
+		fullPath, err := filesystem.buildRevisionFilePath(resourceInfo, revision)
+		if err == nil {
+			_, err = os.Stat(fullPath)
+		}
+		if err == nil {
+			return fullPath, nil
+		}
+		if revision == 0 {
+			return "", err
+		}
+		revision--
 	}
-
-	return fullPath, nil
 }
 
 func (filesystem *Filesystem) BuildFilePath(resourceInfo *ResourceInfo) (string, error) {
+	return filesystem.buildRevisionFilePath(resourceInfo, resourceInfo.problemRevision())
+}
+
+func (filesystem *Filesystem) buildRevisionFilePath(resourceInfo *ResourceInfo, revision uint64) (string, error) {
 	subpathID := filesystem.generatePathFromID(strconv.FormatUint(resourceInfo.ID, 10))
-	fullPath := filepath.Join(filesystem.Basepath, resourceInfo.DataType.String(), subpathID, resourceInfo.Filepath)
+	fullPath := filepath.Join(filesystem.Basepath, resourceInfo.DataType.String(), subpathID)
+	if revision != 0 {
+		fullPath = filepath.Join(fullPath, "revisions", strconv.FormatUint(revision, 10))
+	}
+	fullPath = filepath.Join(fullPath, resourceInfo.Filepath)
 
 	if resourceInfo.EmptyStorageFilename {
 		entries, err := os.ReadDir(fullPath)
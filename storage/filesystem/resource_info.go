@@ -28,6 +28,11 @@ var FilepathFolderMapping = map[resource.Type]string{
 	resource.Checker:        "checker",
 	resource.CheckerOutput:  "tests",
 	resource.Interactor:     "interactor",
+	resource.Solution:       "solutions",
+	resource.Statement:      "statements",
+	resource.Generator:      "generators",
+	resource.Validator:      "validator",
+	resource.Grader:         "graders",
 }
 
 var FilepathFilenameMapping = map[resource.Type]string{
@@ -44,7 +49,8 @@ func (resourseInfo *ResourceInfo) ParseDataType() error {
 	request := resourseInfo.Request
 
 	switch request.Resource {
-	case resource.Checker, resource.Interactor:
+	case resource.Checker, resource.Interactor, resource.Solution, resource.Statement, resource.Generator,
+		resource.Validator, resource.Grader:
 		resourseInfo.DataType = resource.Problem
 		return nil
 	case resource.TestInput, resource.TestAnswer:
@@ -82,6 +88,14 @@ func (resourseInfo *ResourceInfo) ParseDataID() error {
 	}
 }
 
+// problemRevision returns requested problem revision, submission resources have no revisions
+func (resourseInfo *ResourceInfo) problemRevision() uint64 {
+	if resourseInfo.DataType != resource.Problem {
+		return 0
+	}
+	return resourseInfo.Request.ProblemRevision
+}
+
 func (resourseInfo *ResourceInfo) ParseFilepath() error {
 	filepathFolder, err := resourseInfo.parseFilepathFolder()
 
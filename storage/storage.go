@@ -18,7 +18,7 @@ func SetupStorage(ts *common.TestingSystem) error {
 		return fmt.Errorf("storage is not configured")
 	}
 
-	r := ts.Router.Group("/storage/")
+	r := ts.Router.Group("/storage/", ts.AuthMiddleware(ts.Config.Storage.Auth))
 
 	storage := NewStorage(ts)
 
@@ -38,6 +38,11 @@ type TSHolder struct {
 }
 
 func initTS(t *testing.T, sandbox string) *TSHolder {
+	return initTSWithHook(t, sandbox, func(*TSHolder) {})
+}
+
+// initTSWithHook calls beforeSetup after db and storage are initialized, but before invoker and master are set up
+func initTSWithHook(t *testing.T, sandbox string, beforeSetup func(h *TSHolder)) *TSHolder {
 	h := &TSHolder{
 		t:   t,
 		dir: t.TempDir(),
@@ -55,14 +60,21 @@ func initTS(t *testing.T, sandbox string) *TSHolder {
 	h.initTSConfig(configPath, sandbox)
 
 	h.ts = common.InitTestingSystem(configPath)
+	// In memory db is shared until all its connections are closed, so submissions of previous tests must not be seen
+	t.Cleanup(func() {
+		sqlDB, err := h.ts.DB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+	})
 
 	h.client = resty.New().SetBaseURL("http://localhost:" + strconv.Itoa(h.ts.Config.Port))
 
 	h.addProblems()
+	require.NoError(t, storage.SetupStorage(h.ts))
+	beforeSetup(h)
 
 	require.NoError(t, invoker.SetupInvoker(h.ts))
 	require.NoError(t, master.SetupMaster(h.ts))
-	require.NoError(t, storage.SetupStorage(h.ts))
 
 	h.finishWait.Add(1)
 
@@ -4,9 +4,15 @@ import (
 	"context"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
+	"testing_system/common/config"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/resource"
 	"testing_system/common/constants/verdict"
 	"testing_system/common/db/models"
 	"testing_system/lib/logger"
@@ -18,6 +24,9 @@ type submitTest struct {
 	dir     string `yaml:"-"`
 	ID      uint   `yaml:"-"`
 
+	userID    *uint `yaml:"-"`
+	contestID *uint `yaml:"-"`
+
 	ProblemID  uint   `yaml:"problem_id"`
 	Language   string `yaml:"language"`
 	SourceFile string `yaml:"source_file"`
@@ -26,7 +35,7 @@ type submitTest struct {
 	RequiredResult *models.Submission `yaml:"required_result"`
 }
 
-func (h *TSHolder) newSubmit(id uint) {
+func (h *TSHolder) loadSubmit(id uint) *submitTest {
 	s := &submitTest{
 		innerID: id,
 		dir:     filepath.Join(h.submitsDir, strconv.FormatUint(uint64(id), 10)),
@@ -35,6 +44,11 @@ func (h *TSHolder) newSubmit(id uint) {
 	sData, err := os.ReadFile(filepath.Join(s.dir, "cfg.yaml"))
 	require.NoError(h.t, err)
 	require.NoError(h.t, yaml.Unmarshal(sData, s))
+	return s
+}
+
+func (h *TSHolder) newSubmit(id uint) {
+	s := h.loadSubmit(id)
 
 	var ok bool
 	for _ = range 5 {
@@ -49,14 +63,54 @@ func (h *TSHolder) newSubmit(id uint) {
 	h.submits = append(h.submits, s)
 }
 
+// addTestingSubmit emulates submission that was being tested when master stopped.
+// It should be called after storage is set up, but before master is set up.
+// Testing system server is not running yet, so source is uploaded through temporary server with the same router
+func (h *TSHolder) addTestingSubmit(id uint) {
+	s := h.loadSubmit(id)
+
+	submission := &models.Submission{
+		ProblemID: s.ProblemID,
+		Language:  s.Language,
+		Verdict:   verdict.RU,
+	}
+	require.NoError(h.t, h.ts.DB.Save(submission).Error)
+	s.ID = submission.ID
+
+	sourceReader, err := os.Open(filepath.Join(s.dir, s.SourceFile))
+	require.NoError(h.t, err)
+	defer sourceReader.Close()
+
+	server := httptest.NewServer(h.ts.Router)
+	defer server.Close()
+	storageConn := storageconn.NewConnector(&config.Connection{
+		Address: server.URL,
+		Auth:    h.ts.Config.StorageConnection.Auth,
+	})
+	response := storageConn.Upload(&storageconn.Request{
+		Resource:        resource.SourceCode,
+		SubmitID:        uint64(s.ID),
+		StorageFilename: s.SourceFile,
+		File:            sourceReader,
+	})
+	require.NoError(h.t, response.Error)
+
+	h.submits = append(h.submits, s)
+}
+
 func (h *TSHolder) sendSubmit(s *submitTest) bool {
 	sourceReader, err := os.Open(filepath.Join(s.dir, s.SourceFile))
 	require.NoError(h.t, err)
 	defer sourceReader.Close()
 	s.ID, err = h.ts.MasterConn.SendNewSubmission(
 		context.Background(),
-		s.ProblemID,
-		s.Language,
+		&masterconn.NewSubmission{
+			ProblemID: s.ProblemID,
+			Language:  s.Language,
+			Priority:  priority.Default,
+			UserID:    s.userID,
+			ContestID: s.contestID,
+		},
 		s.SourceFile,
 		sourceReader,
 	)
@@ -1,12 +1,32 @@
 package tests
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"testing_system/common/config"
+	"testing_system/common/connectors/masterconn"
+	"testing_system/common/connectors/storageconn"
+	"testing_system/common/constants/priority"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/constants/verdict"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
 	"time"
 )
 
@@ -135,3 +155,669 @@ func testLargeQueue(t *testing.T, sandbox string) {
 	h.waitSubmits()
 	h.stop()
 }
+
+func TestRejudge(t *testing.T) {
+	runSanbodxTests(t, testRejudge)
+}
+
+func testRejudge(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	h.newSubmit(1)
+	s := h.submits[0]
+	h.waitSubmits()
+
+	response, err := h.ts.MasterConn.Rejudge(context.Background(), &masterconn.RejudgeRequest{SubmissionID: &s.ID})
+	require.NoError(t, err)
+	require.Equal(t, []uint{s.ID}, response.SubmissionIDs)
+	require.Empty(t, response.FailedSubmissionIDs)
+	h.submits = append(h.submits, s)
+	h.waitSubmits()
+
+	var history []models.SubmissionHistory
+	require.NoError(t, h.ts.DB.Where("submission_id = ?", s.ID).Find(&history).Error)
+	require.Len(t, history, 1)
+	require.Equal(t, s.RequiredResult.Verdict, history[0].Verdict)
+	h.stop()
+}
+
+func TestRecoverSubmissions(t *testing.T) {
+	runSanbodxTests(t, testRecoverSubmissions)
+}
+
+func testRecoverSubmissions(t *testing.T, sandbox string) {
+	lost := &models.Submission{
+		ProblemID: 1000,
+		Language:  "cpp",
+		Verdict:   verdict.RU,
+	}
+	h := initTSWithHook(t, sandbox, func(h *TSHolder) {
+		h.addTestingSubmit(1)
+		h.addTestingSubmit(2)
+		require.NoError(t, h.ts.DB.Save(lost).Error)
+	})
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	h.waitSubmits()
+	require.NoError(t, h.ts.DB.First(lost, lost.ID).Error)
+	require.Equal(t, verdict.CF, lost.Verdict)
+	h.stop()
+}
+
+func TestContestSubmit(t *testing.T) {
+	runSanbodxTests(t, testContestSubmit)
+}
+
+func testContestSubmit(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	s := h.loadSubmit(1)
+	user := &models.User{Login: "participant"}
+	require.NoError(t, h.ts.DB.Create(user).Error)
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, s.ProblemID).Error)
+	contest := &models.Contest{
+		Name:      "Contest",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now().Add(time.Hour),
+		Problems:  []*models.Problem{problem},
+	}
+	require.NoError(t, h.ts.DB.Create(contest).Error)
+	finishedContest := &models.Contest{
+		Name:      "Finished contest",
+		StartTime: time.Now().Add(-2 * time.Hour),
+		EndTime:   time.Now().Add(-time.Hour),
+		Problems:  []*models.Problem{problem},
+	}
+	require.NoError(t, h.ts.DB.Create(finishedContest).Error)
+
+	s.userID = &user.ID
+	s.contestID = &finishedContest.ID
+	sourceReader, err := os.Open(filepath.Join(s.dir, s.SourceFile))
+	require.NoError(t, err)
+	_, err = h.ts.MasterConn.SendNewSubmission(context.Background(), &masterconn.NewSubmission{
+		ProblemID: s.ProblemID,
+		Language:  s.Language,
+		UserID:    s.userID,
+		ContestID: s.contestID,
+	}, s.SourceFile, sourceReader)
+	sourceReader.Close()
+	require.Error(t, err)
+
+	s.contestID = &contest.ID
+	require.True(t, h.sendSubmit(s))
+	h.submits = append(h.submits, s)
+	h.waitSubmits()
+
+	submission := new(models.Submission)
+	require.NoError(t, h.ts.DB.First(submission, s.ID).Error)
+	require.Equal(t, user.ID, *submission.UserID)
+	require.Equal(t, contest.ID, *submission.ContestID)
+	h.stop()
+}
+
+func TestSubmissionEvents(t *testing.T) {
+	runSanbodxTests(t, testSubmissionEvents)
+}
+
+func testSubmissionEvents(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var receivedEvents []*masterconn.SubmissionEvent
+	subscribed := make(chan struct{})
+	finished := make(chan error)
+	go func() {
+		close(subscribed)
+		finished <- h.ts.MasterConn.SubscribeSubmissionEvents(ctx, nil, nil, func(event *masterconn.SubmissionEvent) error {
+			receivedEvents = append(receivedEvents, event)
+			if event.Type == masterconn.SubmissionEventFinished {
+				cancel()
+			}
+			return nil
+		})
+	}()
+	<-subscribed
+	time.Sleep(100 * time.Millisecond)
+
+	h.newSubmit(1)
+	s := h.submits[0]
+	h.waitSubmits()
+	select {
+	case err := <-finished:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("submission finished event is not received")
+	}
+
+	require.Equal(t, masterconn.SubmissionEventTesting, receivedEvents[0].Type)
+	lastEvent := receivedEvents[len(receivedEvents)-1]
+	require.Equal(t, masterconn.SubmissionEventFinished, lastEvent.Type)
+	require.Equal(t, s.ID, lastEvent.Submission.ID)
+	require.Equal(t, s.RequiredResult.Verdict, lastEvent.Submission.Verdict)
+
+	// Stream of finished submission contains only its final state
+	var singleEvents []*masterconn.SubmissionEvent
+	err := h.ts.MasterConn.SubscribeSubmissionEvents(context.Background(), &s.ID, nil, func(event *masterconn.SubmissionEvent) error {
+		singleEvents = append(singleEvents, event)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, singleEvents, 1)
+	require.Equal(t, masterconn.SubmissionEventFinished, singleEvents[0].Type)
+	require.Equal(t, s.RequiredResult.Verdict, singleEvents[0].Submission.Verdict)
+
+	// Submission of another user is not streamed
+	err = h.ts.MasterConn.SubscribeSubmissionEvents(context.Background(), &s.ID, pointer.Uint(1000), func(event *masterconn.SubmissionEvent) error {
+		t.Errorf("unexpected event %s of submission %d", event.Type, event.Submission.ID)
+		return nil
+	})
+	require.Error(t, err)
+	h.stop()
+}
+
+func TestWebhooks(t *testing.T) {
+	runSanbodxTests(t, testWebhooks)
+}
+
+// webhookRequest is received by test webhook server, it is checked in test goroutine
+type webhookRequest struct {
+	timestamp string
+	signature string
+	body      []byte
+	err       error
+}
+
+func testWebhooks(t *testing.T, sandbox string) {
+	const secret = "webhook-secret"
+	var mutex sync.Mutex
+	attempts := 0
+	delivered := make(chan *webhookRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			// First delivery fails, so webhook should be retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		delivered <- &webhookRequest{
+			timestamp: r.Header.Get(masterconn.WebhookTimestampHeader),
+			signature: r.Header.Get(masterconn.WebhookSignatureHeader),
+			body:      body,
+			err:       err,
+		}
+	}))
+	defer server.Close()
+
+	h := initTSWithHook(t, sandbox, func(h *TSHolder) {
+		h.ts.Config.Master.Webhooks = []*config.WebhookConfig{{
+			URL:            server.URL,
+			Secret:         secret,
+			Timeout:        10 * time.Second,
+			MaxElapsedTime: time.Minute,
+		}}
+	})
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	h.newSubmit(1)
+	s := h.submits[0]
+	h.waitSubmits()
+
+	select {
+	case request := <-delivered:
+		require.NoError(t, request.err)
+		require.Equal(t, masterconn.SignWebhook(secret, request.timestamp, request.body), request.signature)
+		webhook := new(masterconn.SubmissionWebhook)
+		require.NoError(t, json.Unmarshal(request.body, webhook))
+		require.Equal(t, masterconn.WebhookEventSubmissionFinished, webhook.Event)
+		require.Equal(t, s.ID, webhook.SubmissionID)
+		require.Equal(t, s.RequiredResult.Verdict, webhook.Verdict)
+		require.Equal(t, s.RequiredResult.Score, webhook.Score)
+		require.Equal(t, s.ID, webhook.Submission.ID)
+	case <-time.After(30 * time.Second):
+		t.Fatal("webhook is not delivered")
+	}
+	h.stop()
+}
+
+func TestCancelSubmission(t *testing.T) {
+	runSanbodxTests(t, testCancelSubmission)
+}
+
+func testCancelSubmission(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	s := h.loadSubmit(1)
+	require.True(t, h.sendSubmit(s))
+	require.NoError(t, h.ts.MasterConn.CancelSubmission(context.Background(), s.ID))
+	require.Error(t, h.ts.MasterConn.CancelSubmission(context.Background(), s.ID))
+	h.waitTesting(s)
+	require.Equal(t, verdict.CL, s.result.Verdict)
+
+	// Other submissions are tested after cancellation
+	h.newSubmit(2)
+	h.waitSubmits()
+	h.stop()
+}
+
+func TestCachedSubmission(t *testing.T) {
+	runSanbodxTests(t, testCachedSubmission)
+}
+
+func testCachedSubmission(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	h.newSubmit(1)
+	tested := h.submits[0]
+	h.waitSubmits()
+
+	// Identical source is not tested again
+	h.newSubmit(1)
+	cached := h.submits[0]
+	h.waitSubmits()
+	require.NotNil(t, cached.result.CachedFromID)
+	require.Equal(t, tested.ID, *cached.result.CachedFromID)
+	require.Equal(t, tested.result.TestResults, cached.result.TestResults)
+
+	s := h.loadSubmit(1)
+	sourceReader, err := os.Open(filepath.Join(s.dir, s.SourceFile))
+	require.NoError(t, err)
+	s.ID, err = h.ts.MasterConn.SendNewSubmission(context.Background(), &masterconn.NewSubmission{
+		ProblemID: s.ProblemID,
+		Language:  s.Language,
+		ForceRun:  true,
+	}, s.SourceFile, sourceReader)
+	sourceReader.Close()
+	require.NoError(t, err)
+	h.submits = append(h.submits, s)
+	h.waitSubmits()
+	require.Nil(t, s.result.CachedFromID)
+	h.stop()
+}
+
+func TestProblemRevisions(t *testing.T) {
+	runSanbodxTests(t, testProblemRevisions)
+}
+
+func testProblemRevisions(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	h.newSubmit(1)
+	tested := h.submits[0]
+	h.waitSubmits()
+	require.Equal(t, uint64(0), tested.result.ProblemRevision)
+
+	// New revision changes answer of the first test, old revision stays untouched
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, tested.ProblemID).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+	problem.Revision = 1
+	require.NoError(t, h.ts.DB.Save(problem).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+	require.NoError(t, h.ts.StorageConn.Upload(&storageconn.Request{
+		Resource:        resource.TestAnswer,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		TestID:          1,
+		File:            strings.NewReader("4\n"),
+	}).Error)
+
+	s := h.loadSubmit(1)
+	require.True(t, h.sendSubmit(s))
+	h.waitTesting(s)
+	require.Equal(t, verdict.WA, s.result.Verdict)
+	require.Equal(t, uint64(1), s.result.ProblemRevision)
+	require.Nil(t, s.result.CachedFromID)
+
+	for revision, answer := range []string{"3", "4"} {
+		response := h.ts.StorageConn.Download(&storageconn.Request{
+			Resource:        resource.TestAnswer,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: uint64(revision),
+			TestID:          1,
+			DownloadBytes:   true,
+		})
+		require.NoError(t, response.Error)
+		require.Equal(t, answer, strings.TrimSpace(string(response.RawData)))
+	}
+	// Resources that are not changed in revision are taken from previous revisions
+	response := h.ts.StorageConn.Download(&storageconn.Request{
+		Resource:        resource.TestInput,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		TestID:          1,
+		DownloadBytes:   true,
+	})
+	require.NoError(t, response.Error)
+	require.Equal(t, "1 2", strings.TrimSpace(string(response.RawData)))
+	h.stop()
+}
+
+func TestProblemPackage(t *testing.T) {
+	runSanbodxTests(t, testProblemPackage)
+}
+
+func testProblemPackage(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	s := h.loadSubmit(1)
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, s.ProblemID).Error)
+
+	pkg, err := problempackage.Export(context.Background(), h.ts.StorageConn, problem)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, pkg.Write(buf))
+	pkg, err = problempackage.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	imported, err := problempackage.Import(context.Background(), h.ts.DB, h.ts.StorageConn, pkg)
+	require.NoError(t, err)
+	require.NotEqual(t, problem.ID, imported.ID)
+	require.Equal(t, uint64(1), imported.Revision)
+	require.Equal(t, problem.TestsNumber, imported.TestsNumber)
+
+	// Submission to imported problem is tested the same way as to the original one
+	s.ProblemID = imported.ID
+	require.True(t, h.sendSubmit(s))
+	h.submits = append(h.submits, s)
+	h.waitSubmits()
+	h.stop()
+}
+
+func TestInvocation(t *testing.T) {
+	runSanbodxTests(t, testInvocation)
+}
+
+func testInvocation(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, 1).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+	problem.Revision = 1
+	// Runtime error solution is tagged as time limit, so it should be reported as mismatched
+	for _, solution := range []struct {
+		submitID uint
+		name     string
+		tag      solutiontag.Tag
+	}{
+		{1, "main.cpp", solutiontag.Main},
+		{3, "wa.cpp", solutiontag.WrongAnswer},
+		{5, "tl.cpp", solutiontag.TimeLimit},
+		{4, "rt.cpp", solutiontag.TimeLimit},
+	} {
+		s := h.loadSubmit(solution.submitID)
+		source, err := os.Open(filepath.Join(s.dir, s.SourceFile))
+		require.NoError(t, err)
+		require.NoError(t, h.ts.StorageConn.Upload(&storageconn.Request{
+			Resource:        resource.Solution,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: problem.Revision,
+			StorageFilename: solution.name,
+			File:            source,
+		}).Error)
+		source.Close()
+		problem.Solutions = append(problem.Solutions, &models.ProblemSolution{
+			Name:     solution.name,
+			Language: s.Language,
+			Tag:      solution.tag,
+		})
+	}
+	require.NoError(t, h.ts.DB.Save(problem).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+
+	invocationID, err := h.ts.MasterConn.NewInvocation(context.Background(), &masterconn.InvocationRequest{
+		ProblemID: problem.ID,
+	})
+	require.NoError(t, err)
+
+	var result *masterconn.InvocationResult
+	for {
+		result, err = h.ts.MasterConn.GetInvocation(context.Background(), invocationID)
+		require.NoError(t, err)
+		if result.Finished {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	require.Equal(t, problem.Revision, result.ProblemRevision)
+	require.Equal(t, []string{"rt.cpp"}, result.Mismatched)
+	expectedVerdicts := []verdict.Verdict{verdict.OK, verdict.WA, verdict.TL, verdict.RT}
+	require.Len(t, result.Solutions, len(expectedVerdicts))
+	for i, solution := range result.Solutions {
+		require.Equal(t, expectedVerdicts[i], solution.Verdict)
+		require.Len(t, solution.TestResults, int(problem.TestsNumber))
+		require.NotNil(t, solution.MatchesTag)
+	}
+
+	suggestion, err := h.ts.MasterConn.SuggestTimeLimit(context.Background(), invocationID)
+	require.NoError(t, err)
+	require.Equal(t, problem.ID, suggestion.ProblemID)
+	require.LessOrEqual(t, result.Solutions[0].MaxTime, suggestion.MaxAcceptedTime)
+	require.GreaterOrEqual(t, suggestion.TimeLimit, suggestion.MaxAcceptedTime)
+	require.Less(t, suggestion.TimeLimit, result.Solutions[2].MaxTime)
+	require.Equal(t, []string{"rt.cpp"}, suggestion.PassingSolutions)
+
+	// Reference solution runs are not rejudged with problem submissions
+	response, err := h.ts.MasterConn.Rejudge(context.Background(), &masterconn.RejudgeRequest{ProblemID: &problem.ID})
+	require.NoError(t, err)
+	require.Empty(t, response.SubmissionIDs)
+	h.stop()
+}
+
+func TestTestGeneration(t *testing.T) {
+	runSanbodxTests(t, testTestGeneration)
+}
+
+func testTestGeneration(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	s := h.loadSubmit(1)
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, s.ProblemID).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+	problem.Revision = 1
+
+	// Generator prints its arguments, validator accepts two non-negative numbers
+	programs := map[resource.Type]string{
+		resource.Generator: `#include <iostream>
+int main(int argc, char* argv[]) { std::cout << argv[1] << " " << argv[2] << std::endl; }`,
+		resource.Validator: `#include <iostream>
+int main() { int a, b; return std::cin >> a >> b && a >= 0 && b >= 0 ? 0 : 1; }`,
+	}
+	for resourceType, program := range programs {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "program.cpp"), []byte(program), 0644))
+		cmd := exec.Command("g++", "program.cpp", "-std=c++17", "-o", "program")
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+		binary, err := os.Open(filepath.Join(dir, "program"))
+		require.NoError(t, err)
+		require.NoError(t, h.ts.StorageConn.Upload(&storageconn.Request{
+			Resource:        resourceType,
+			ProblemID:       uint64(problem.ID),
+			ProblemRevision: problem.Revision,
+			StorageFilename: "gen",
+			File:            binary,
+		}).Error)
+		binary.Close()
+	}
+
+	source, err := os.Open(filepath.Join(s.dir, s.SourceFile))
+	require.NoError(t, err)
+	require.NoError(t, h.ts.StorageConn.Upload(&storageconn.Request{
+		Resource:        resource.Solution,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		StorageFilename: "main.cpp",
+		File:            source,
+	}).Error)
+	source.Close()
+	problem.Solutions = models.ProblemSolutions{{Name: "main.cpp", Language: s.Language, Tag: solutiontag.Main}}
+	problem.TestScript = models.TestScript{{Test: 2, Generator: "gen", Args: []string{"7", "8"}}}
+	problem.HasValidator = true
+	require.NoError(t, h.ts.DB.Save(problem).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+
+	submissionID, err := h.ts.MasterConn.GenerateTests(context.Background(), &masterconn.TestGenerationRequest{
+		ProblemID: problem.ID,
+	})
+	require.NoError(t, err)
+	generation := &submitTest{ID: submissionID}
+	h.waitTesting(generation)
+	require.Equal(t, verdict.OK, generation.result.Verdict)
+	require.True(t, generation.result.GeneratesTests)
+	require.Len(t, generation.result.TestResults, int(problem.TestsNumber))
+
+	// Tests are generated in new revision, previous revision keeps its tests
+	require.Equal(t, problem.Revision+1, generation.result.ProblemRevision)
+	oldInput := h.ts.StorageConn.Download(&storageconn.Request{
+		Resource:        resource.TestInput,
+		ProblemID:       uint64(problem.ID),
+		ProblemRevision: problem.Revision,
+		TestID:          2,
+		DownloadBytes:   true,
+	})
+	require.NoError(t, oldInput.Error)
+	require.NotEqual(t, "7 8", strings.TrimSpace(string(oldInput.RawData)))
+	problem.Revision = generation.result.ProblemRevision
+
+	// Uploaded input of the first test gets answer of main solution, the second test is generated
+	for testID, expected := range map[uint64][2]string{1: {"1 2", "3"}, 2: {"7 8", "15"}} {
+		for i, resourceType := range []resource.Type{resource.TestInput, resource.TestAnswer} {
+			response := h.ts.StorageConn.Download(&storageconn.Request{
+				Resource:        resourceType,
+				ProblemID:       uint64(problem.ID),
+				ProblemRevision: problem.Revision,
+				TestID:          testID,
+				DownloadBytes:   true,
+			})
+			require.NoError(t, response.Error)
+			require.Equal(t, expected[i], strings.TrimSpace(string(response.RawData)))
+		}
+	}
+
+	// Test generation is not rejudged with problem submissions
+	response, err := h.ts.MasterConn.Rejudge(context.Background(), &masterconn.RejudgeRequest{ProblemID: &problem.ID})
+	require.NoError(t, err)
+	require.Empty(t, response.SubmissionIDs)
+
+	// Submissions are tested on generated tests
+	require.True(t, h.sendSubmit(s))
+	h.submits = append(h.submits, s)
+	h.waitSubmits()
+	h.stop()
+}
+
+func TestOutputOnly(t *testing.T) {
+	runSanbodxTests(t, testOutputOnly)
+}
+
+func testOutputOnly(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	problem := new(models.Problem)
+	require.NoError(t, h.ts.DB.First(problem, 1).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+	problem.Revision = 1
+	problem.ProblemType = models.ProblemTypeOutputOnly
+	problem.TestGroups = models.TestGroups{{
+		Name:        "1",
+		FirstTest:   1,
+		LastTest:    2,
+		TestScore:   pointer.Float64(50),
+		ScoringType: models.TestGroupScoringTypeEachTest,
+	}}
+	require.NoError(t, h.ts.DB.Save(problem).Error)
+	require.NoError(t, h.ts.DB.Create(models.NewProblemRevision(problem)).Error)
+
+	// Output of the first test is correct, output of the second test is wrong
+	archive := &bytes.Buffer{}
+	writer := zip.NewWriter(archive)
+	for name, output := range map[string]string{"01.out": "3\n", "02.out": "1\n"} {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(output))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	submissionID, err := h.ts.MasterConn.SendNewSubmission(
+		context.Background(),
+		&masterconn.NewSubmission{ProblemID: problem.ID, Priority: priority.Default},
+		"outputs.zip",
+		archive,
+	)
+	require.NoError(t, err)
+	s := &submitTest{ID: submissionID}
+	h.waitTesting(s)
+
+	require.Nil(t, s.result.CompilationResult)
+	require.Equal(t, verdict.PT, s.result.Verdict)
+	require.Equal(t, 50., s.result.Score)
+	require.Len(t, s.result.TestResults, 2)
+	require.Equal(t, verdict.OK, s.result.TestResults[0].Verdict)
+	require.Equal(t, verdict.WA, s.result.TestResults[1].Verdict)
+	h.stop()
+}
+
+func TestMultiFileSubmit(t *testing.T) {
+	runSanbodxTests(t, testMultiFileSubmit)
+}
+
+func testMultiFileSubmit(t *testing.T, sandbox string) {
+	h := initTS(t, sandbox)
+	go h.start()
+	time.Sleep(10 * time.Millisecond)
+
+	sources := map[string]string{
+		"main.cpp": "#include <iostream>\n#include \"sum.h\"\nint main() { int a, b; std::cin >> a >> b; std::cout << sum(a, b) << std::endl; }\n",
+		"sum.h":    "int sum(int a, int b);\n",
+		"sum.cpp":  "#include \"sum.h\"\nint sum(int a, int b) { return a + b; }\n",
+	}
+	var files []*masterconn.SourceFile
+	for name, source := range sources {
+		files = append(files, &masterconn.SourceFile{Name: name, Reader: strings.NewReader(source)})
+	}
+	submissionID, err := h.ts.MasterConn.SendNewSubmissionFiles(
+		context.Background(),
+		&masterconn.NewSubmission{ProblemID: 1, Language: "cpp", Priority: priority.Default},
+		files,
+	)
+	require.NoError(t, err)
+	s := &submitTest{ID: submissionID}
+	h.waitTesting(s)
+
+	require.True(t, s.result.SourceArchive)
+	require.Equal(t, verdict.OK, s.result.Verdict)
+	require.Len(t, s.result.TestResults, 2)
+	h.stop()
+}
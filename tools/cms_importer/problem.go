@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"github.com/xorcare/pointer"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/constants/standardchecker"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+	"testing_system/lib/customfields"
+	"testing_system/tools/importutil"
+)
+
+const (
+	taskFile = "task.yaml"
+	genFile  = "gen/GEN"
+
+	inputPattern  = "input/input%d.txt"
+	outputPattern = "output/output%d.txt"
+
+	subtaskPrefix     = "# ST:"
+	copyTestPrefix    = "#COPY:"
+	defaultTotalValue = 100.
+	defaultLanguage   = "en"
+)
+
+// taskYAML is task.yaml of italian CMS task format
+type taskYAML struct {
+	Name            string   `yaml:"name"`
+	Title           string   `yaml:"title"`
+	TimeLimit       float64  `yaml:"time_limit"`
+	MemoryLimit     uint64   `yaml:"memory_limit"`
+	NInput          uint64   `yaml:"n_input"`
+	TotalValue      *float64 `yaml:"total_value"`
+	OutputOnly      bool     `yaml:"output_only"`
+	PrimaryLanguage string   `yaml:"primary_language"`
+	ScoreType       string   `yaml:"score_type"`
+	// ScoreTypeParameters are [[score, tests count], ...] for group score types
+	ScoreTypeParameters [][]float64 `yaml:"score_type_parameters"`
+}
+
+type subtask struct {
+	name  string
+	score float64
+	tests uint64
+}
+
+func buildPackage(fsys fs.FS, workdir string, defaultName string) (*problempackage.Package, error) {
+	var task taskYAML
+	data, err := fs.ReadFile(fsys, taskFile)
+	if err != nil {
+		return nil, fmt.Errorf("can not read %s, error: %v", taskFile, err)
+	}
+	if err = yaml.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("can not parse %s, error: %v", taskFile, err)
+	}
+	if err = checkTaskIsSupported(fsys, &task); err != nil {
+		return nil, err
+	}
+
+	prob := &models.Problem{
+		Name:        task.Name,
+		ProblemType: models.ProblemTypeIOI,
+		TimeLimit:   customfields.Time(task.TimeLimit * 1e9),
+		MemoryLimit: customfields.Memory(task.MemoryLimit * 1024 * 1024),
+		TestsNumber: task.NInput,
+	}
+	if prob.Name == "" {
+		prob.Name = defaultName
+	}
+
+	subtasks, err := taskSubtasks(fsys, &task)
+	if err != nil {
+		return nil, err
+	}
+	if err = buildTestGroups(prob, &task, subtasks); err != nil {
+		return nil, err
+	}
+
+	pkg := &problempackage.Package{Problem: prob}
+	for _, addFiles := range []func(fs.FS, string, *problempackage.Package, *taskYAML) error{
+		addTests,
+		addChecker,
+		addSolutions,
+		addStatements,
+	} {
+		if err = addFiles(fsys, workdir, pkg, &task); err != nil {
+			return nil, err
+		}
+	}
+	return pkg, nil
+}
+
+func checkTaskIsSupported(fsys fs.FS, task *taskYAML) error {
+	if task.NInput == 0 {
+		return fmt.Errorf("task has no tests")
+	}
+	if task.TimeLimit <= 0 || task.MemoryLimit == 0 {
+		return fmt.Errorf("time_limit and memory_limit should be specified")
+	}
+	if task.OutputOnly {
+		return fmt.Errorf("output only tasks are not supported")
+	}
+	for _, manager := range []string{"check/manager", "cor/manager", "check/manager.cpp", "cor/manager.cpp"} {
+		if importutil.Exists(fsys, manager) {
+			return fmt.Errorf("communication tasks are not supported")
+		}
+	}
+	entries, _ := fs.Glob(fsys, "sol/grader.*")
+	if len(entries) > 0 {
+		return fmt.Errorf("tasks with graders are not supported")
+	}
+	return nil
+}
+
+// taskSubtasks returns subtasks from score_type_parameters or from "# ST: <score>" lines of gen/GEN.
+// Tests in gen/GEN before the first subtask form subtask with zero score.
+// If there are no subtasks, task is scored as sum of tests with total_value
+func taskSubtasks(fsys fs.FS, task *taskYAML) ([]*subtask, error) {
+	switch task.ScoreType {
+	case "", "Sum":
+		if task.ScoreType == "" && importutil.Exists(fsys, genFile) {
+			return genSubtasks(fsys)
+		}
+		return nil, nil
+	case "GroupMin":
+		var subtasks []*subtask
+		for _, parameters := range task.ScoreTypeParameters {
+			if len(parameters) != 2 || parameters[1] != float64(uint64(parameters[1])) {
+				return nil, fmt.Errorf("only [score, tests count] score type parameters are supported")
+			}
+			subtasks = append(subtasks, &subtask{
+				name:  strconv.Itoa(len(subtasks) + 1),
+				score: parameters[0],
+				tests: uint64(parameters[1]),
+			})
+		}
+		return subtasks, nil
+	default:
+		return nil, fmt.Errorf("score type %s is not supported", task.ScoreType)
+	}
+}
+
+func genSubtasks(fsys fs.FS) ([]*subtask, error) {
+	data, err := fs.ReadFile(fsys, genFile)
+	if err != nil {
+		return nil, fmt.Errorf("can not read %s, error: %v", genFile, err)
+	}
+	var subtasks []*subtask
+	current := &subtask{name: "0"}
+	subtasksCount := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, subtaskPrefix):
+			score, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, subtaskPrefix)), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid subtask score in %s: %s", genFile, line)
+			}
+			if current.tests > 0 || subtasksCount > 0 {
+				subtasks = append(subtasks, current)
+			}
+			subtasksCount++
+			current = &subtask{name: strconv.Itoa(subtasksCount), score: score}
+		case strings.HasPrefix(line, copyTestPrefix):
+			current.tests++
+		case line == "" || strings.HasPrefix(line, "#"):
+			// comment
+		default:
+			current.tests++
+		}
+	}
+	if subtasksCount == 0 {
+		return nil, nil
+	}
+	return append(subtasks, current), nil
+}
+
+// buildTestGroups creates group for each subtask scored as minimum among its tests,
+// or single group where each test costs total_value / n_input
+func buildTestGroups(prob *models.Problem, task *taskYAML, subtasks []*subtask) error {
+	if len(subtasks) == 0 {
+		totalValue := defaultTotalValue
+		if task.TotalValue != nil {
+			totalValue = *task.TotalValue
+		}
+		prob.TestGroups = models.TestGroups{{
+			Name:         "1",
+			FirstTest:    1,
+			LastTest:     prob.TestsNumber,
+			TestScore:    pointer.Float64(totalValue / float64(prob.TestsNumber)),
+			ScoringType:  models.TestGroupScoringTypeEachTest,
+			FeedbackType: models.TestGroupFeedbackTypeComplete,
+		}}
+		return nil
+	}
+
+	lastTest := uint64(0)
+	for _, st := range subtasks {
+		if st.tests == 0 {
+			return fmt.Errorf("subtask %s has no tests", st.name)
+		}
+		prob.TestGroups = append(prob.TestGroups, &models.TestGroup{
+			Name:         st.name,
+			FirstTest:    lastTest + 1,
+			LastTest:     lastTest + st.tests,
+			GroupScore:   pointer.Float64(st.score),
+			ScoringType:  models.TestGroupScoringTypeMin,
+			FeedbackType: models.TestGroupFeedbackTypeComplete,
+		})
+		lastTest += st.tests
+	}
+	if lastTest != prob.TestsNumber {
+		return fmt.Errorf("subtasks have %d tests, but n_input is %d", lastTest, prob.TestsNumber)
+	}
+	return nil
+}
+
+func addTests(fsys fs.FS, _ string, pkg *problempackage.Package, _ *taskYAML) error {
+	for testID := uint64(1); testID <= pkg.Problem.TestsNumber; testID++ {
+		// CMS tests are numbered from 0
+		input, err := fs.ReadFile(fsys, fmt.Sprintf(inputPattern, testID-1))
+		if err != nil {
+			return err
+		}
+		answer, err := fs.ReadFile(fsys, fmt.Sprintf(outputPattern, testID-1))
+		if err != nil {
+			return err
+		}
+		pkg.Files = append(pkg.Files,
+			&problempackage.File{Resource: resource.TestInput, TestID: testID, Data: input},
+			&problempackage.File{Resource: resource.TestAnswer, TestID: testID, Data: answer},
+		)
+	}
+	return nil
+}
+
+// addChecker uses compiled check/checker or cor/correttore, or compiles its C++ source.
+// Tasks without checker are checked with white diff, which is the same as wcmp
+func addChecker(fsys fs.FS, workdir string, pkg *problempackage.Package, _ *taskYAML) error {
+	for _, dir := range []string{"check", "cor"} {
+		name := "checker"
+		if dir == "cor" {
+			name = "correttore"
+		}
+		binaryPath := path.Join(dir, name)
+		sourcePath := binaryPath + ".cpp"
+
+		var checker []byte
+		var err error
+		switch {
+		case importutil.Exists(fsys, sourcePath):
+			checkerDir := filepath.Join(workdir, dir)
+			var checkerFS fs.FS
+			if checkerFS, err = fs.Sub(fsys, dir); err != nil {
+				return err
+			}
+			if err = os.CopyFS(checkerDir, checkerFS); err != nil {
+				return fmt.Errorf("can not extract checker, error: %v", err)
+			}
+			checker, err = importutil.CompileCpp(checkerDir, []string{name + ".cpp"}, "checker.bin")
+		case importutil.Exists(fsys, binaryPath):
+			checker, err = fs.ReadFile(fsys, binaryPath)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pkg.Problem.CheckerType = models.CheckerTypeCMS
+		pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Checker, Name: "checker", Data: checker})
+		return nil
+	}
+
+	pkg.Problem.CheckerType = models.CheckerTypeStandard
+	pkg.Problem.StandardChecker = standardchecker.Tokens
+	return nil
+}
+
+// addSolutions adds sol/solution.* or sol/soluzione.* as main solution
+func addSolutions(fsys fs.FS, _ string, pkg *problempackage.Package, _ *taskYAML) error {
+	for _, pattern := range []string{"sol/solution.*", "sol/soluzione.*"} {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			source, err := fs.ReadFile(fsys, match)
+			if err != nil {
+				return err
+			}
+			name := path.Base(match)
+			tag := solutiontag.Main
+			if len(pkg.Problem.Solutions) > 0 {
+				tag = solutiontag.Accepted
+			}
+			pkg.Problem.Solutions = append(pkg.Problem.Solutions, &models.ProblemSolution{
+				Name:     name,
+				Language: importutil.LanguageByFilename(name),
+				Tag:      tag,
+			})
+			pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Solution, Name: name, Data: source})
+		}
+	}
+	return nil
+}
+
+// addStatements adds statement/statement.pdf or italian testo/testo.pdf in task primary language
+func addStatements(fsys fs.FS, _ string, pkg *problempackage.Package, task *taskYAML) error {
+	language := task.PrimaryLanguage
+	for _, statementPath := range []string{"statement/statement.pdf", "testo/testo.pdf"} {
+		if !importutil.Exists(fsys, statementPath) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, statementPath)
+		if err != nil {
+			return err
+		}
+		if language == "" {
+			language = defaultLanguage
+			if strings.HasPrefix(statementPath, "testo") {
+				language = "it"
+			}
+		}
+		name := path.Base(statementPath)
+		pkg.Problem.Statements = append(pkg.Problem.Statements, &models.ProblemStatement{
+			Name:     name,
+			Language: language,
+			Type:     "application/pdf",
+		})
+		pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Statement, Name: name, Data: data})
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package importutil contains helpers shared by problem importers from other formats
+package importutil
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing_system/common/config"
+	"testing_system/common/connectors/storageconn"
+	db2 "testing_system/common/db"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+)
+
+// CompileCpp compiles C++ sources located in dir to binary named output and returns the binary
+func CompileCpp(dir string, sources []string, output string) ([]byte, error) {
+	args := append([]string{}, sources...)
+	args = append(args, "-std=c++20", "-O2", "-o", output)
+	cmd := exec.Command("g++", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("can not compile %v, error: %v, output: %s", sources, err, out)
+	}
+	return os.ReadFile(filepath.Join(dir, output))
+}
+
+var extensionLanguages = map[string]string{
+	".cpp":  "cpp",
+	".cc":   "cpp",
+	".cxx":  "cpp",
+	".c":    "c",
+	".py":   "python",
+	".java": "java",
+	".kt":   "kotlin",
+	".pas":  "pascal",
+	".go":   "go",
+	".rs":   "rust",
+}
+
+// LanguageByFilename guesses solution language by its extension, unknown extension is used as language itself
+func LanguageByFilename(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if language, ok := extensionLanguages[ext]; ok {
+		return language
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// OpenPackage opens package directory or zip archive. If marker file is not in the root of archive,
+// but archive contains single directory, this directory is used as package root
+func OpenPackage(path string, marker string) (fs.FS, func() error, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stat.IsDir() {
+		return os.DirFS(path), func() error { return nil }, nil
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not open package archive %s, error: %v", path, err)
+	}
+	if _, err = fs.Stat(archive, marker); err == nil {
+		return archive, archive.Close, nil
+	}
+	entries, err := fs.ReadDir(archive, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		archive.Close()
+		return nil, nil, fmt.Errorf("no %s found in package %s", marker, path)
+	}
+	root, err := fs.Sub(archive, entries[0].Name())
+	if err != nil {
+		archive.Close()
+		return nil, nil, err
+	}
+	return root, archive.Close, nil
+}
+
+// Exists reports whether package contains file or directory
+func Exists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// ImportPackage creates problem from package in testing system configured at configPath
+func ImportPackage(configPath string, pkg *problempackage.Package) (*models.Problem, error) {
+	cfg := config.ReadConfig(configPath)
+	db, err := db2.NewDB(cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+	storage := storageconn.NewConnector(cfg.StorageConnection)
+	if storage == nil {
+		return nil, fmt.Errorf("StorageConnection is not specified in config")
+	}
+	return problempackage.Import(context.Background(), db, storage, pkg)
+}
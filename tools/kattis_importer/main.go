@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing_system/tools/importutil"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Println("Usage: kattis_importer <ts_config_path> <package_dir_or_zip>")
+		os.Exit(1)
+	}
+
+	fsys, closePackage, err := importutil.OpenPackage(os.Args[2], problemFile)
+	if err != nil {
+		panic(err)
+	}
+	defer closePackage()
+
+	workdir, err := os.MkdirTemp("", "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(workdir)
+
+	defaultName := filepath.Base(os.Args[2])
+	defaultName = defaultName[:len(defaultName)-len(filepath.Ext(defaultName))]
+	pkg, err := buildPackage(fsys, workdir, defaultName)
+	if err != nil {
+		panic(err)
+	}
+
+	prob, err := importutil.ImportPackage(os.Args[1], pkg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Created problem %d\n", prob.ID)
+}
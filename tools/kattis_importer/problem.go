@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"github.com/xorcare/pointer"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/constants/standardchecker"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+	"testing_system/lib/customfields"
+	"testing_system/tools/importutil"
+)
+
+const (
+	problemFile   = "problem.yaml"
+	testdataFile  = "testdata.yaml"
+	timeLimitFile = ".timelimit"
+
+	sampleGroup = "sample"
+	secretGroup = "secret"
+
+	// Kattis default memory limit in MiB
+	defaultMemoryLimit = 2048
+	defaultAcceptScore = 1.
+)
+
+type problemYAML struct {
+	// Name is either string or map from language to name
+	Name           yaml.Node `yaml:"name"`
+	Type           string    `yaml:"type"`
+	Validation     string    `yaml:"validation"`
+	ValidatorFlags string    `yaml:"validator_flags"`
+	Limits         struct {
+		// TimeLimit is in seconds
+		TimeLimit float64 `yaml:"time_limit"`
+		// Memory is in MiB
+		Memory uint64 `yaml:"memory"`
+	} `yaml:"limits"`
+}
+
+type testdataYAML struct {
+	AcceptScore *float64 `yaml:"accept_score"`
+	GraderFlags string   `yaml:"grader_flags"`
+}
+
+type testCase struct {
+	input  string
+	answer string
+}
+
+type testGroup struct {
+	name  string
+	dir   string
+	tests []testCase
+}
+
+func buildPackage(fsys fs.FS, workdir string, defaultName string) (*problempackage.Package, error) {
+	var config problemYAML
+	if err := readYAML(fsys, problemFile, &config); err != nil {
+		return nil, err
+	}
+
+	prob := &models.Problem{
+		Name: problemName(&config.Name, defaultName),
+	}
+	timeLimit, err := problemTimeLimit(fsys, &config)
+	if err != nil {
+		return nil, err
+	}
+	prob.TimeLimit = customfields.Time(timeLimit * 1e9)
+	memoryLimit := config.Limits.Memory
+	if memoryLimit == 0 {
+		memoryLimit = defaultMemoryLimit
+	}
+	prob.MemoryLimit = customfields.Memory(memoryLimit * 1024 * 1024)
+
+	groups, err := collectTestGroups(fsys)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &problempackage.Package{Problem: prob}
+	for _, group := range groups {
+		for _, test := range group.tests {
+			if err = addTest(fsys, pkg, test); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch config.Type {
+	case "", "pass-fail":
+		prob.ProblemType = models.ProblemTypeICPC
+	case "scoring":
+		prob.ProblemType = models.ProblemTypeIOI
+		if err = buildTestGroups(fsys, prob, groups); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown problem type %s", config.Type)
+	}
+
+	if err = setValidator(fsys, workdir, pkg, &config); err != nil {
+		return nil, err
+	}
+	if err = addSolutions(fsys, pkg); err != nil {
+		return nil, err
+	}
+	if err = addStatements(fsys, pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func readYAML(fsys fs.FS, name string, value interface{}) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("can not read %s, error: %v", name, err)
+	}
+	if err = yaml.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("can not parse %s, error: %v", name, err)
+	}
+	return nil
+}
+
+func problemName(node *yaml.Node, defaultName string) string {
+	if node.Kind == yaml.ScalarNode && node.Value != "" {
+		return node.Value
+	}
+	names := make(map[string]string)
+	if node.Kind != yaml.MappingNode || node.Decode(&names) != nil || len(names) == 0 {
+		return defaultName
+	}
+	if name, ok := names["en"]; ok {
+		return name
+	}
+	languages := make([]string, 0, len(names))
+	for language := range names {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return names[languages[0]]
+}
+
+// problemTimeLimit returns time limit in seconds. Legacy packages have no time limit in problem.yaml,
+// it is written to .timelimit file by problem tools
+func problemTimeLimit(fsys fs.FS, config *problemYAML) (float64, error) {
+	if config.Limits.TimeLimit > 0 {
+		return config.Limits.TimeLimit, nil
+	}
+	data, err := fs.ReadFile(fsys, timeLimitFile)
+	if err != nil {
+		return 0, fmt.Errorf("no time limit in %s and no %s file found", problemFile, timeLimitFile)
+	}
+	timeLimit, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil || timeLimit <= 0 {
+		return 0, fmt.Errorf("invalid time limit in %s", timeLimitFile)
+	}
+	return timeLimit, nil
+}
+
+// collectTestGroups returns sample tests, tests in data/secret root and tests of each data/secret subdirectory
+func collectTestGroups(fsys fs.FS) ([]*testGroup, error) {
+	var groups []*testGroup
+	if importutil.Exists(fsys, "data/sample") {
+		group := &testGroup{name: sampleGroup, dir: "data/sample"}
+		if err := collectTests(fsys, group.dir, group); err != nil {
+			return nil, err
+		}
+		if len(group.tests) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, "data/secret")
+	if err != nil {
+		return nil, fmt.Errorf("can not read data/secret, error: %v", err)
+	}
+	secret := &testGroup{name: secretGroup, dir: "data/secret"}
+	var subgroups []*testGroup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			group := &testGroup{name: entry.Name(), dir: path.Join("data/secret", entry.Name())}
+			if err = collectTests(fsys, group.dir, group); err != nil {
+				return nil, err
+			}
+			if len(group.tests) > 0 {
+				subgroups = append(subgroups, group)
+			}
+		} else if err = addTestCase(fsys, path.Join("data/secret", entry.Name()), secret); err != nil {
+			return nil, err
+		}
+	}
+	if len(secret.tests) > 0 {
+		groups = append(groups, secret)
+	}
+	groups = append(groups, subgroups...)
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("package has no tests")
+	}
+	return groups, nil
+}
+
+func collectTests(fsys fs.FS, dir string, group *testGroup) error {
+	return fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		return addTestCase(fsys, name, group)
+	})
+}
+
+func addTestCase(fsys fs.FS, name string, group *testGroup) error {
+	base, ok := strings.CutSuffix(name, ".in")
+	if !ok {
+		return nil
+	}
+	answer := base + ".ans"
+	if !importutil.Exists(fsys, answer) {
+		return fmt.Errorf("no answer %s for test %s", answer, name)
+	}
+	group.tests = append(group.tests, testCase{input: name, answer: answer})
+	return nil
+}
+
+func addTest(fsys fs.FS, pkg *problempackage.Package, test testCase) error {
+	input, err := fs.ReadFile(fsys, test.input)
+	if err != nil {
+		return err
+	}
+	answer, err := fs.ReadFile(fsys, test.answer)
+	if err != nil {
+		return err
+	}
+	pkg.Problem.TestsNumber++
+	pkg.Files = append(pkg.Files,
+		&problempackage.File{Resource: resource.TestInput, TestID: pkg.Problem.TestsNumber, Data: input},
+		&problempackage.File{Resource: resource.TestAnswer, TestID: pkg.Problem.TestsNumber, Data: answer},
+	)
+	return nil
+}
+
+// buildTestGroups maps kattis test groups to testing system groups. Sample group costs nothing,
+// secret groups use accept_score and grader_flags from testdata.yaml: min costs accept_score for the whole group,
+// sum (default) costs accept_score for each test
+func buildTestGroups(fsys fs.FS, prob *models.Problem, groups []*testGroup) error {
+	lastTest := uint64(0)
+	for _, group := range groups {
+		testGroup := &models.TestGroup{
+			Name:         group.name,
+			FirstTest:    lastTest + 1,
+			LastTest:     lastTest + uint64(len(group.tests)),
+			FeedbackType: models.TestGroupFeedbackTypeICPC,
+		}
+		lastTest = testGroup.LastTest
+
+		if group.name == sampleGroup {
+			testGroup.ScoringType = models.TestGroupScoringTypeComplete
+			testGroup.GroupScore = pointer.Float64(0)
+			testGroup.FeedbackType = models.TestGroupFeedbackTypeComplete
+			prob.TestGroups = append(prob.TestGroups, testGroup)
+			continue
+		}
+
+		testdata, err := loadTestdata(fsys, group.dir)
+		if err != nil {
+			return err
+		}
+		score := defaultAcceptScore
+		if testdata.AcceptScore != nil {
+			score = *testdata.AcceptScore
+		}
+		flags := strings.Fields(testdata.GraderFlags)
+		switch {
+		case slices.Contains(flags, "min"):
+			testGroup.ScoringType = models.TestGroupScoringTypeComplete
+			testGroup.GroupScore = pointer.Float64(score)
+		case len(flags) == 0, slices.Contains(flags, "sum"):
+			testGroup.ScoringType = models.TestGroupScoringTypeEachTest
+			testGroup.TestScore = pointer.Float64(score)
+		default:
+			return fmt.Errorf("group %s has unsupported grader flags %s", group.name, testdata.GraderFlags)
+		}
+		prob.TestGroups = append(prob.TestGroups, testGroup)
+	}
+	return nil
+}
+
+// loadTestdata loads testdata.yaml of group, settings not specified in group are inherited from parent directories
+func loadTestdata(fsys fs.FS, dir string) (*testdataYAML, error) {
+	testdata := new(testdataYAML)
+	var dirs []string
+	for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	slices.Reverse(dirs)
+	for _, dir = range dirs {
+		name := path.Join(dir, testdataFile)
+		if !importutil.Exists(fsys, name) {
+			continue
+		}
+		var current testdataYAML
+		if err := readYAML(fsys, name, &current); err != nil {
+			return nil, err
+		}
+		if current.AcceptScore != nil {
+			testdata.AcceptScore = current.AcceptScore
+		}
+		if current.GraderFlags != "" {
+			testdata.GraderFlags = current.GraderFlags
+		}
+	}
+	return testdata, nil
+}
+
+func setValidator(fsys fs.FS, workdir string, pkg *problempackage.Package, config *problemYAML) error {
+	switch config.Validation {
+	case "", "default":
+		checker, eps, err := defaultValidatorChecker(config.ValidatorFlags)
+		if err != nil {
+			return err
+		}
+		pkg.Problem.CheckerType = models.CheckerTypeStandard
+		pkg.Problem.StandardChecker = checker
+		pkg.Problem.CheckerEpsilon = eps
+		return nil
+	case "custom":
+		if config.ValidatorFlags != "" {
+			return fmt.Errorf("validator_flags are not supported for custom output validators")
+		}
+		checker, err := compileValidator(fsys, workdir)
+		if err != nil {
+			return err
+		}
+		pkg.Problem.CheckerType = models.CheckerTypeKattis
+		pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Checker, Name: "checker", Data: checker})
+		return nil
+	default:
+		return fmt.Errorf("validation %s is not supported", config.Validation)
+	}
+}
+
+// defaultValidatorChecker maps kattis default output validator to standard checker and its float tolerance
+func defaultValidatorChecker(flags string) (string, *float64, error) {
+	fields := strings.Fields(flags)
+	checker := standardchecker.Tokens
+	var eps *float64
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "case_sensitive", "space_change_sensitive":
+			// wcmp is case-sensitive and ignores space changes
+		case "float_tolerance", "float_absolute_tolerance", "float_relative_tolerance":
+			if i+1 == len(fields) {
+				return "", nil, fmt.Errorf("no value for %s validator flag", fields[i])
+			}
+			i++
+			tolerance, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil || !(tolerance > 0) {
+				return "", nil, fmt.Errorf("invalid value %s for validator flag", fields[i])
+			}
+			checker = standardchecker.Floats
+			eps = &tolerance
+		default:
+			return "", nil, fmt.Errorf("unsupported validator flag %s", fields[i])
+		}
+	}
+	return checker, eps, nil
+}
+
+func compileValidator(fsys fs.FS, workdir string) ([]byte, error) {
+	entries, err := fs.ReadDir(fsys, "output_validators")
+	if err != nil {
+		return nil, fmt.Errorf("can not read output_validators, error: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil, fmt.Errorf("output_validators should contain exactly one validator directory")
+	}
+
+	validatorDir := filepath.Join(workdir, "output_validator")
+	validatorFS, err := fs.Sub(fsys, path.Join("output_validators", entries[0].Name()))
+	if err != nil {
+		return nil, err
+	}
+	if err = os.CopyFS(validatorDir, validatorFS); err != nil {
+		return nil, fmt.Errorf("can not extract output validator, error: %v", err)
+	}
+
+	var sources []string
+	err = fs.WalkDir(validatorFS, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && importutil.LanguageByFilename(name) == "cpp" {
+			sources = append(sources, name)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("only C++ output validators are supported")
+	}
+	return importutil.CompileCpp(validatorDir, sources, "checker")
+}
+
+var submissionTags = map[string]solutiontag.Tag{
+	"accepted":            solutiontag.Accepted,
+	"wrong_answer":        solutiontag.WrongAnswer,
+	"time_limit_exceeded": solutiontag.TimeLimit,
+	"run_time_error":      solutiontag.Rejected,
+}
+
+// addSolutions adds single file submissions as reference solutions, first accepted submission is main
+func addSolutions(fsys fs.FS, pkg *problempackage.Package) error {
+	if !importutil.Exists(fsys, "submissions") {
+		return nil
+	}
+	categories, err := fs.ReadDir(fsys, "submissions")
+	if err != nil {
+		return err
+	}
+	hasMain := false
+	for _, category := range categories {
+		tag, ok := submissionTags[category.Name()]
+		if !category.IsDir() || !ok {
+			continue
+		}
+		dir := path.Join("submissions", category.Name())
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				fmt.Printf("Skipping multi-file submission %s\n", path.Join(dir, entry.Name()))
+				continue
+			}
+			source, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+			solutionTag := tag
+			if tag == solutiontag.Accepted && !hasMain {
+				solutionTag = solutiontag.Main
+				hasMain = true
+			}
+			if pkg.Problem.FindSolution(entry.Name()) != nil {
+				return fmt.Errorf("submission name %s is used more than once", entry.Name())
+			}
+			pkg.Problem.Solutions = append(pkg.Problem.Solutions, &models.ProblemSolution{
+				Name:     entry.Name(),
+				Language: importutil.LanguageByFilename(entry.Name()),
+				Tag:      solutionTag,
+			})
+			pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Solution, Name: entry.Name(), Data: source})
+		}
+	}
+	return nil
+}
+
+var statementTypes = map[string]string{
+	".tex": "application/x-tex",
+	".md":  "text/markdown",
+	".pdf": "application/pdf",
+}
+
+// addStatements adds problem.<language>.<ext> files from statement directory, language is en if it is omitted
+func addStatements(fsys fs.FS, pkg *problempackage.Package) error {
+	for _, dir := range []string{"problem_statement", "statement"} {
+		if !importutil.Exists(fsys, dir) {
+			continue
+		}
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			ext := path.Ext(entry.Name())
+			statementType, ok := statementTypes[ext]
+			if entry.IsDir() || !ok || !strings.HasPrefix(entry.Name(), "problem.") {
+				continue
+			}
+			language := strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ext), "problem")
+			language = strings.TrimPrefix(language, ".")
+			if language == "" {
+				language = "en"
+			}
+			data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+			pkg.Problem.Statements = append(pkg.Problem.Statements, &models.ProblemStatement{
+				Name:     entry.Name(),
+				Language: language,
+				Type:     statementType,
+			})
+			pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Statement, Name: entry.Name(), Data: data})
+		}
+	}
+	return nil
+}
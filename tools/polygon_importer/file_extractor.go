@@ -61,21 +61,16 @@ func extractAllFiles(prefix string, dst string) error {
 	return nil
 }
 
-func moveFile(srcPath string, dstDir string, name string) error {
-	dstDir = filepath.Join(probTmpPath, dstDir)
-	err := os.MkdirAll(dstDir, 0775)
-	if err != nil {
-		return fmt.Errorf("can not create dir %s for file %s, error: %s", dstDir, srcPath, err.Error())
-	}
-
+func readFile(srcPath string) ([]byte, error) {
 	for _, file := range archive.File {
 		if file.Name == srcPath {
-			err = moveSingleFile(file, filepath.Join(dstDir, name))
+			r, err := file.Open()
 			if err != nil {
-				return fmt.Errorf("can not extract zip file %s to dir %s, error: %s", srcPath, dstDir, err.Error())
+				return nil, fmt.Errorf("can not open zip file %s, error: %s", srcPath, err.Error())
 			}
-			return nil
+			defer r.Close()
+			return io.ReadAll(r)
 		}
 	}
-	return fmt.Errorf("file with path %s not found", srcPath)
+	return nil, fmt.Errorf("file with path %s not found", srcPath)
 }
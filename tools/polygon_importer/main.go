@@ -5,11 +5,10 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
-	"testing_system/common/config"
-	db2 "testing_system/common/db"
+	"testing_system/common/problempackage"
+	"testing_system/tools/importutil"
 )
 
 var polygonApiKey string
@@ -22,26 +21,32 @@ var probTmpPath string
 var probXML XProblemXML
 
 func main() {
-	configPath := os.Args[1]
-	cfg := config.ReadConfig(configPath)
-	db, err := db2.NewDB(cfg.DB)
-	if err != nil {
-		panic(err)
-	}
-	polygonApiKey = os.Args[3]
-	polygonApiSecret = os.Args[4]
-	probID, err := strconv.Atoi(os.Args[2])
-	if err != nil {
-		panic(err)
+	if len(os.Args) != 3 && len(os.Args) != 5 {
+		fmt.Println("Usage: polygon_importer <ts_config_path> <problem_id> <api_key> <api_secret>")
+		fmt.Println("       polygon_importer <ts_config_path> <package.zip>")
+		os.Exit(1)
 	}
-
 	workdir, err := os.MkdirTemp("", "")
 	if err != nil {
 		panic(err)
 	}
-	packagePath := filepath.Join(workdir, "package.zip")
-	if err = ImportPackageApi(probID, packagePath); err != nil {
-		panic(err)
+	defer os.RemoveAll(workdir)
+
+	var packagePath string
+	if len(os.Args) == 3 {
+		// Local package does not require polygon access
+		packagePath = os.Args[2]
+	} else {
+		polygonApiKey = os.Args[3]
+		polygonApiSecret = os.Args[4]
+		probID, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			panic(err)
+		}
+		packagePath = filepath.Join(workdir, "package.zip")
+		if err = ImportPackageApi(probID, packagePath); err != nil {
+			panic(err)
+		}
 	}
 
 	archive, err = zip.OpenReader(packagePath)
@@ -55,19 +60,11 @@ func main() {
 		panic(err)
 	}
 
-	if err = extractAllFiles("tests", "tests"); err != nil {
-		panic(err)
-	}
-
 	if err = extractAllFiles("files", "sources"); err != nil {
 		panic(err)
 	}
 
-	if err = moveFile("problem.xml", "", "problem.xml"); err != nil {
-		panic(err)
-	}
-
-	probXMLData, err := os.ReadFile(filepath.Join(probTmpPath, "problem.xml"))
+	probXMLData, err := readFile("problem.xml")
 	if err != nil {
 		panic(err)
 	}
@@ -77,42 +74,26 @@ func main() {
 		panic(err)
 	}
 
-	checkerCPP := filepath.Base(probXML.Assets.Checker.Source.Path)
-	cmd := exec.Command("g++", checkerCPP, "-std=c++20", "-o", "checker")
-	cmd.Dir = filepath.Join(probTmpPath, "sources")
-	err = cmd.Run()
-	if err != nil {
-		panic(err)
-	}
-
-	if err = os.MkdirAll(filepath.Join(probTmpPath, "checker"), 0755); err != nil {
-		panic(err)
-	}
-
-	err = exec.Command(
-		"mv",
-		filepath.Join(probTmpPath, "sources", "checker"),
-		filepath.Join(probTmpPath, "checker", "checker"),
-	).Run() // If go has no single line solution for this, I will use cmd!
+	prob, err := buildProblemModel()
 	if err != nil {
 		panic(err)
 	}
 
-	prob := buildProblemModel()
-
-	err = db.Create(prob).Error
-	if err != nil {
-		panic(err)
+	pkg := &problempackage.Package{Problem: prob}
+	for _, addFiles := range []func(*problempackage.Package) error{
+		addTests,
+		addExecutables,
+		addSolutions,
+		addStatements,
+	} {
+		if err = addFiles(pkg); err != nil {
+			panic(err)
+		}
 	}
 
-	out, err := exec.Command("mv", probTmpPath, filepath.Join(
-		cfg.Storage.StoragePath,
-		"Problem",
-		strconv.FormatUint(uint64(prob.ID), 10),
-	)).CombinedOutput()
+	prob, err = importutil.ImportPackage(os.Args[1], pkg)
 	if err != nil {
-		fmt.Println(string(out))
 		panic(err)
 	}
-	fmt.Printf("Created problem %d", prob.ID)
+	fmt.Printf("Created problem %d\n", prob.ID)
 }
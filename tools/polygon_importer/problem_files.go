@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing_system/common/constants/resource"
+	"testing_system/common/constants/solutiontag"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+	"testing_system/tools/importutil"
+)
+
+const (
+	defaultInputPathPattern  = "tests/%02d"
+	defaultAnswerPathPattern = "tests/%02d.a"
+)
+
+func addTests(pkg *problempackage.Package) error {
+	testset, err := findTestset()
+	if err != nil {
+		return err
+	}
+	inputPattern := testset.InputPathPattern
+	if inputPattern == "" {
+		inputPattern = defaultInputPathPattern
+	}
+	answerPattern := testset.AnswerPathPattern
+	if answerPattern == "" {
+		answerPattern = defaultAnswerPathPattern
+	}
+
+	for testID := uint64(1); testID <= pkg.Problem.TestsNumber; testID++ {
+		input, err := readFile(fmt.Sprintf(inputPattern, testID))
+		if err != nil {
+			return err
+		}
+		answer, err := readFile(fmt.Sprintf(answerPattern, testID))
+		if err != nil {
+			return err
+		}
+		pkg.Files = append(pkg.Files,
+			&problempackage.File{Resource: resource.TestInput, TestID: testID, Data: input},
+			&problempackage.File{Resource: resource.TestAnswer, TestID: testID, Data: answer},
+		)
+	}
+	return nil
+}
+
+// compileSource compiles checker or interactor with testlib from package resources
+func compileSource(sourcePath string, name string) ([]byte, error) {
+	return importutil.CompileCpp(filepath.Join(probTmpPath, "sources"), []string{filepath.Base(sourcePath)}, name)
+}
+
+func addExecutables(pkg *problempackage.Package) error {
+	checker, err := compileSource(probXML.Assets.Checker.Source.Path, "checker")
+	if err != nil {
+		return err
+	}
+	pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Checker, Name: "checker", Data: checker})
+
+	if probXML.Assets.Interactor != nil {
+		interactor, err := compileSource(probXML.Assets.Interactor.Source.Path, "interactor")
+		if err != nil {
+			return err
+		}
+		pkg.Files = append(pkg.Files,
+			&problempackage.File{Resource: resource.Interactor, Name: "interactor", Data: interactor},
+		)
+	}
+	return nil
+}
+
+// addSolutions adds polygon solutions as problem reference solutions.
+// Language is polygon source type before the first dot, e.g. cpp for cpp.g++17
+func addSolutions(pkg *problempackage.Package) error {
+	for _, xSolution := range probXML.Assets.Solutions.Solutions {
+		tag := solutiontag.Tag(xSolution.Tag)
+		if !tag.IsValid() {
+			return fmt.Errorf("solution %s has unknown tag %s", xSolution.Source.Path, xSolution.Tag)
+		}
+		source, err := readFile(xSolution.Source.Path)
+		if err != nil {
+			return err
+		}
+		name := path.Base(xSolution.Source.Path)
+		language, _, _ := strings.Cut(xSolution.Source.Type, ".")
+		pkg.Problem.Solutions = append(pkg.Problem.Solutions, &models.ProblemSolution{
+			Name:     name,
+			Language: language,
+			Tag:      tag,
+		})
+		pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Solution, Name: name, Data: source})
+	}
+	return nil
+}
+
+// addStatements adds statement files listed in problem.xml. Statements of different languages have same
+// file names in polygon, so language is added to the name
+func addStatements(pkg *problempackage.Package) error {
+	for _, xStatement := range probXML.Statements.Statements {
+		data, err := readFile(xStatement.Path)
+		if err != nil {
+			return err
+		}
+		name := xStatement.Language + "-" + path.Base(xStatement.Path)
+		pkg.Problem.Statements = append(pkg.Problem.Statements, &models.ProblemStatement{
+			Name:     name,
+			Language: xStatement.Language,
+			Type:     xStatement.Type,
+		})
+		pkg.Files = append(pkg.Files, &problempackage.File{Resource: resource.Statement, Name: name, Data: data})
+	}
+	return nil
+}
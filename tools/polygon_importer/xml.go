@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"github.com/xorcare/pointer"
 	"testing_system/common/db/models"
 	"testing_system/lib/customfields"
@@ -45,11 +46,13 @@ type XJudging struct {
 }
 
 type XTestset struct {
-	Name        string  `xml:"name,attr"`
-	TimeLimit   int     `xml:"time-limit"`
-	MemoryLimit int     `xml:"memory-limit"`
-	Tests       XTests  `xml:"tests"`
-	Groups      XGroups `xml:"groups"`
+	Name              string  `xml:"name,attr"`
+	TimeLimit         int     `xml:"time-limit"`
+	MemoryLimit       int     `xml:"memory-limit"`
+	InputPathPattern  string  `xml:"input-path-pattern"`
+	AnswerPathPattern string  `xml:"answer-path-pattern"`
+	Tests             XTests  `xml:"tests"`
+	Groups            XGroups `xml:"groups"`
 }
 
 type XTests struct {
@@ -110,19 +113,41 @@ type XSolutions struct {
 	Solutions []*XSourceFile `xml:"solution"`
 }
 
-func buildProblemModel() *models.Problem {
-	prob := &models.Problem{
-		Name: probXML.ShortName,
-	}
-	var testset *XTestset
-	for _, someTestset := range probXML.Judging.Testsets {
-		if someTestset.Name != "tests" {
-			continue
+// Polygon defaults for groups without policies
+const (
+	defaultFeedbackPolicy = "complete"
+	defaultPointsPolicy   = "complete-group"
+)
+
+var feedbackPolicies = map[string]models.TestGroupFeedbackType{
+	"none":     models.TestGroupFeedbackTypeNone,
+	"points":   models.TestGroupFeedbackTypePoints,
+	"icpc":     models.TestGroupFeedbackTypeICPC,
+	"complete": models.TestGroupFeedbackTypeComplete,
+}
+
+var pointsPolicies = map[string]models.TestGroupScoringType{
+	"complete-group": models.TestGroupScoringTypeComplete,
+	"each-test":      models.TestGroupScoringTypeEachTest,
+}
+
+func findTestset() (*XTestset, error) {
+	for _, testset := range probXML.Judging.Testsets {
+		if testset.Name == "tests" {
+			return testset, nil
 		}
-		testset = someTestset
 	}
-	if testset == nil {
-		panic("no testset tests found")
+	return nil, fmt.Errorf("no testset tests found")
+}
+
+func buildProblemModel() (*models.Problem, error) {
+	prob := &models.Problem{
+		Name:        probXML.ShortName,
+		Interactive: probXML.Assets.Interactor != nil,
+	}
+	testset, err := findTestset()
+	if err != nil {
+		return nil, err
 	}
 	prob.TimeLimit = customfields.Time(testset.TimeLimit * 1000 * 1000)
 	prob.MemoryLimit = customfields.Memory(testset.MemoryLimit)
@@ -130,64 +155,105 @@ func buildProblemModel() *models.Problem {
 
 	if len(testset.Groups.Groups) == 0 {
 		prob.ProblemType = models.ProblemTypeICPC
-		return prob
+		return prob, nil
 	}
 
 	prob.ProblemType = models.ProblemTypeIOI
+	groups := make(map[string]*models.TestGroup)
 	for _, xGroup := range testset.Groups.Groups {
-		group := &models.TestGroup{
-			Name:       xGroup.Name,
-			FirstTest:  prob.TestsNumber,
-			GroupScore: pointer.Float64(0),
-		}
-		for _, dependency := range xGroup.Dependencies.Dependencies {
-			group.RequiredGroupNames = append(group.RequiredGroupNames, dependency.Group)
-		}
-		switch xGroup.FeedbackPolicy {
-		case "none":
-			group.FeedbackType = models.TestGroupFeedbackTypeNone
-		case "points":
-			group.FeedbackType = models.TestGroupFeedbackTypePoints
-		case "icpc":
-			group.FeedbackType = models.TestGroupFeedbackTypeICPC
-		case "complete":
-			group.FeedbackType = models.TestGroupFeedbackTypeComplete
-		default:
-			panic("unknown feedback policy")
+		group, err := buildTestGroup(xGroup)
+		if err != nil {
+			return nil, err
 		}
-
-		switch xGroup.PointsPolicy {
-		case "complete-group":
-			group.ScoringType = models.TestGroupScoringTypeComplete
-		case "each-test":
-			group.ScoringType = models.TestGroupScoringTypeEachTest
-		default:
-			panic("unknown points policy")
-		}
-
-		prob.TestGroups = append(prob.TestGroups, group)
+		groups[group.Name] = group
 	}
 
+	// Groups are ordered by their tests, as testing system requires groups to be consecutive
+	testPoints := make(map[string][]float64)
+	var lastGroup *models.TestGroup
 	for i, test := range testset.Tests.Tests {
 		id := uint64(i + 1)
 		if test.Group == "" {
-			panic("test group is required for each test")
+			return nil, fmt.Errorf("test %d has no group, group is required for each test", id)
+		}
+		group, ok := groups[test.Group]
+		if !ok {
+			return nil, fmt.Errorf("test %d has unknown group %s", id, test.Group)
 		}
-		for _, group := range prob.TestGroups {
-			if group.Name == test.Group {
-				group.FirstTest = min(group.FirstTest, id)
-				group.LastTest = max(group.LastTest, id)
-				*group.GroupScore += test.Points
+		if group != lastGroup {
+			if group.FirstTest != 0 {
+				return nil, fmt.Errorf("tests of group %s are not consecutive", group.Name)
 			}
+			group.FirstTest = id
+			prob.TestGroups = append(prob.TestGroups, group)
+			lastGroup = group
 		}
+		group.LastTest = id
+		testPoints[group.Name] = append(testPoints[group.Name], test.Points)
+	}
+	if len(prob.TestGroups) != len(groups) {
+		return nil, fmt.Errorf("some groups have no tests")
 	}
 
 	for _, group := range prob.TestGroups {
-		if group.ScoringType == models.TestGroupScoringTypeEachTest {
-			group.TestScore = pointer.Float64(*group.GroupScore / float64(group.LastTest-group.FirstTest+1))
-			group.GroupScore = nil
+		if err = setGroupScore(group, testPoints[group.Name]); err != nil {
+			return nil, err
 		}
 	}
+	return prob, nil
+}
+
+func buildTestGroup(xGroup *XGroup) (*models.TestGroup, error) {
+	group := &models.TestGroup{
+		Name: xGroup.Name,
+	}
+	for _, dependency := range xGroup.Dependencies.Dependencies {
+		group.RequiredGroupNames = append(group.RequiredGroupNames, dependency.Group)
+	}
+
+	feedbackPolicy := xGroup.FeedbackPolicy
+	if feedbackPolicy == "" {
+		feedbackPolicy = defaultFeedbackPolicy
+	}
+	var ok bool
+	if group.FeedbackType, ok = feedbackPolicies[feedbackPolicy]; !ok {
+		return nil, fmt.Errorf("group %s has unknown feedback policy %s", group.Name, feedbackPolicy)
+	}
+
+	pointsPolicy := xGroup.PointsPolicy
+	if pointsPolicy == "" {
+		pointsPolicy = defaultPointsPolicy
+	}
+	if group.ScoringType, ok = pointsPolicies[pointsPolicy]; !ok {
+		return nil, fmt.Errorf("group %s has unknown points policy %s", group.Name, pointsPolicy)
+	}
+	if xGroup.Points != 0 {
+		group.GroupScore = pointer.Float64(xGroup.Points)
+	}
+	return group, nil
+}
 
-	return prob
+// setGroupScore sets group score from test points. Group points from problem.xml take precedence
+// for complete-group policy, each-test policy requires all tests of group to cost the same
+func setGroupScore(group *models.TestGroup, points []float64) error {
+	switch group.ScoringType {
+	case models.TestGroupScoringTypeComplete:
+		if group.GroupScore != nil {
+			return nil
+		}
+		sum := 0.
+		for _, testPoints := range points {
+			sum += testPoints
+		}
+		group.GroupScore = pointer.Float64(sum)
+	case models.TestGroupScoringTypeEachTest:
+		for _, testPoints := range points {
+			if testPoints != points[0] {
+				return fmt.Errorf("tests of each-test group %s have different points", group.Name)
+			}
+		}
+		group.TestScore = pointer.Float64(points[0])
+		group.GroupScore = nil
+	}
+	return nil
 }
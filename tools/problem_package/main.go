@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing_system/common/config"
+	"testing_system/common/connectors/storageconn"
+	db2 "testing_system/common/db"
+	"testing_system/common/db/models"
+	"testing_system/common/problempackage"
+)
+
+func main() {
+	if len(os.Args) < 4 {
+		fmt.Println("Usage: problem_package <ts_config_path> export <problem_id> <package.zip>")
+		fmt.Println("       problem_package <ts_config_path> import <package.zip>")
+		os.Exit(1)
+	}
+	cfg := config.ReadConfig(os.Args[1])
+	db, err := db2.NewDB(cfg.DB)
+	if err != nil {
+		panic(err)
+	}
+	storage := storageconn.NewConnector(cfg.StorageConnection)
+	if storage == nil {
+		panic("StorageConnection is not specified in config")
+	}
+	ctx := context.Background()
+
+	switch os.Args[2] {
+	case "export":
+		if len(os.Args) < 5 {
+			panic("problem id and package path should be specified")
+		}
+		problemID, err := strconv.Atoi(os.Args[3])
+		if err != nil {
+			panic(err)
+		}
+		problem := new(models.Problem)
+		if err = db.First(problem, problemID).Error; err != nil {
+			panic(err)
+		}
+		pkg, err := problempackage.Export(ctx, storage, problem)
+		if err != nil {
+			panic(err)
+		}
+		file, err := os.Create(os.Args[4])
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		if err = pkg.Write(file); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Exported problem %d\n", problem.ID)
+	case "import":
+		file, err := os.Open(os.Args[3])
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			panic(err)
+		}
+		pkg, err := problempackage.Read(file, info.Size())
+		if err != nil {
+			panic(err)
+		}
+		problem, err := problempackage.Import(ctx, db, storage, pkg)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("Created problem %d\n", problem.ID)
+	default:
+		panic(fmt.Sprintf("unknown command %s", os.Args[2]))
+	}
+}